@@ -8,6 +8,7 @@ require (
 	github.com/miekg/dns v1.1.72
 	github.com/registrobr/rdap v1.1.8
 	github.com/spf13/cobra v1.10.2
+	golang.org/x/net v0.50.0
 )
 
 require (
@@ -15,7 +16,6 @@ require (
 	github.com/likexian/gokit v0.25.16 // indirect
 	github.com/spf13/pflag v1.0.10 // indirect
 	golang.org/x/mod v0.33.0 // indirect
-	golang.org/x/net v0.50.0 // indirect
 	golang.org/x/sync v0.19.0 // indirect
 	golang.org/x/sys v0.41.0 // indirect
 	golang.org/x/text v0.34.0 // indirect
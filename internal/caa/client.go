@@ -0,0 +1,306 @@
+package caa
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	dnsinfo "watchr/internal/dns"
+	"watchr/internal/idn"
+	tlsinfo "watchr/internal/tls"
+)
+
+// knownTags are the property tags CAs understand. A critical property with
+// any other tag forbids issuance (RFC 8659 section 4.5).
+var knownTags = map[string]bool{
+	"issue":        true,
+	"issuewild":    true,
+	"iodef":        true,
+	"contactemail": true,
+	"contactphone": true,
+	"issuemail":    true,
+	"issuevmc":     true,
+}
+
+type Client struct {
+	timeout   time.Duration
+	dnsClient *dnsinfo.Client
+	tlsClient *tlsinfo.Client
+}
+
+func NewClient(timeout time.Duration, dnsClient *dnsinfo.Client) *Client {
+	return &Client{
+		timeout:   timeout,
+		dnsClient: dnsClient,
+		tlsClient: tlsinfo.NewClient(timeout),
+	}
+}
+
+// Check fetches the certificate served by host and checks, for every name it
+// covers, that the CAA records relevant to the name (RFC 8659) authorize the
+// CA that issued it, so that renewing it with the same CA will succeed. The
+// issuer domains of the CA are looked up from its organization unless given
+// in issuerDomains.
+func (c *Client) Check(ctx context.Context, host, port string, issuerDomains []string) (*Response, error) {
+	start := time.Now()
+
+	name, err := idn.Parse(host)
+	if err != nil {
+		return nil, err
+	}
+	host = name.ASCII
+
+	tlsResp, err := c.tlsClient.Fetch(ctx, host, port)
+	if err != nil {
+		return nil, err
+	}
+	if len(tlsResp.Certificates) == 0 {
+		return nil, fmt.Errorf("%s did not send a certificate", net.JoinHostPort(host, port))
+	}
+	leaf := tlsResp.Certificates[0]
+
+	names := certificateNames(leaf.DNSNames)
+	if len(names) == 0 {
+		if net.ParseIP(host) != nil {
+			return nil, fmt.Errorf("the certificate has no DNS names to check CAA records for")
+		}
+		names = []string{strings.ToLower(strings.TrimSuffix(host, "."))}
+	}
+
+	if len(issuerDomains) == 0 {
+		issuerDomains = IssuerDomains(leaf.Issuer.Organization)
+	}
+
+	response := &Response{
+		Host:          host,
+		IDN:           name,
+		Port:          port,
+		Issuer:        issuerName(leaf.Issuer),
+		IssuerDomains: issuerDomains,
+		Status:        StatusPermitted,
+		Names:         make([]NameResult, 0, len(names)),
+	}
+
+	slog.Debug("evaluating CAA records", "host", host, "issuer", response.Issuer, "names", names)
+
+	cache := make(map[string][]Property)
+	for _, name := range names {
+		result := c.evaluate(ctx, name, issuerDomains, cache)
+		response.Names = append(response.Names, result)
+		if statusRank(result.Status) > statusRank(response.Status) {
+			response.Status = result.Status
+		}
+	}
+	response.QueryTime = time.Since(start)
+
+	return response, nil
+}
+
+// evaluate checks the CAA set relevant to name against the issuer domains.
+func (c *Client) evaluate(ctx context.Context, name string, issuerDomains []string, cache map[string][]Property) NameResult {
+	result := NameResult{
+		Name:     name,
+		Wildcard: strings.HasPrefix(name, "*."),
+	}
+
+	owner, properties, err := c.relevantSet(ctx, strings.TrimPrefix(name, "*."), cache)
+	if err != nil {
+		result.Status = StatusError
+		result.Reason = "the CAA records could not be retrieved, CAs must not issue"
+		result.Error = err.Error()
+		return result
+	}
+
+	if owner == "" {
+		result.Status = StatusPermitted
+		result.Reason = "no CAA records up the tree, any CA may issue"
+		return result
+	}
+	result.Owner = owner
+	result.Properties = properties
+
+	var issue, issueWild []Property
+	for _, property := range properties {
+		switch property.Tag {
+		case "issue":
+			issue = append(issue, property)
+		case "issuewild":
+			issueWild = append(issueWild, property)
+		case "iodef":
+			result.Iodef = append(result.Iodef, property.Value)
+		default:
+			if property.Critical && !knownTags[property.Tag] {
+				result.Status = StatusDenied
+				result.Reason = fmt.Sprintf("critical property %q at %s is not understood by CAs", property.Tag, owner)
+				return result
+			}
+		}
+	}
+
+	// issuewild takes precedence over issue for wildcard names when present
+	// (RFC 8659 section 4.3).
+	applicable, tag := issue, "issue"
+	if result.Wildcard && len(issueWild) > 0 {
+		applicable, tag = issueWild, "issuewild"
+	}
+
+	if len(applicable) == 0 {
+		result.Status = StatusPermitted
+		result.Reason = fmt.Sprintf("the CAA records at %s do not restrict issuance for this name", owner)
+		return result
+	}
+
+	for _, property := range applicable {
+		if property.Issuer != "" {
+			result.Authorized = append(result.Authorized, property.Issuer)
+		}
+	}
+
+	authorized := "no CA"
+	if len(result.Authorized) > 0 {
+		authorized = strings.Join(result.Authorized, ", ")
+	}
+
+	if len(issuerDomains) == 0 {
+		result.Status = StatusUnknown
+		result.Reason = fmt.Sprintf("the issuing CA is not known, %s at %s authorizes %s", tag, owner, authorized)
+		return result
+	}
+
+	for _, issuer := range result.Authorized {
+		for _, domain := range issuerDomains {
+			if issuer == domain {
+				result.Status = StatusPermitted
+				result.Reason = fmt.Sprintf("%s at %s authorizes %s", tag, owner, issuer)
+				return result
+			}
+		}
+	}
+
+	result.Status = StatusDenied
+	result.Reason = fmt.Sprintf("the issuing CA is not authorized, %s at %s authorizes %s", tag, owner, authorized)
+	return result
+}
+
+// relevantSet climbs the tree from name up to the top-level domain and returns
+// the first non-empty CAA set found, along with the name it was found at. Both
+// are empty when no name has CAA records.
+func (c *Client) relevantSet(ctx context.Context, name string, cache map[string][]Property) (string, []Property, error) {
+	for current := name; current != ""; {
+		properties, ok := cache[current]
+		if !ok {
+			var err error
+			properties, err = c.lookup(ctx, current)
+			if err != nil {
+				return "", nil, err
+			}
+			cache[current] = properties
+		}
+
+		if len(properties) > 0 {
+			return current, properties, nil
+		}
+
+		_, current, _ = strings.Cut(current, ".")
+	}
+
+	return "", nil, nil
+}
+
+func (c *Client) lookup(ctx context.Context, name string) ([]Property, error) {
+	resp, err := c.dnsClient.Query(ctx, name, "CAA")
+	if err != nil {
+		return nil, fmt.Errorf("CAA lookup of %s failed: %w", name, err)
+	}
+
+	switch resp.Rcode {
+	case "NOERROR", "NXDOMAIN":
+	default:
+		return nil, fmt.Errorf("CAA lookup of %s failed: %s", name, resp.Rcode)
+	}
+
+	properties := make([]Property, 0)
+	for _, record := range resp.Records {
+		data, ok := record.Data.(*dnsinfo.CAAData)
+		if !ok {
+			continue
+		}
+		properties = append(properties, parseProperty(data))
+	}
+
+	return properties, nil
+}
+
+// parseProperty parses a CAA property, splitting the issuer domain and the
+// parameters of issue and issuewild values (RFC 8659 section 4.2).
+func parseProperty(data *dnsinfo.CAAData) Property {
+	property := Property{
+		Critical: data.Flag&128 != 0,
+		Tag:      strings.ToLower(data.Tag),
+		Value:    data.Value,
+	}
+
+	if property.Tag != "issue" && property.Tag != "issuewild" {
+		return property
+	}
+
+	issuer, parameters, _ := strings.Cut(data.Value, ";")
+	property.Issuer = strings.ToLower(strings.TrimSpace(issuer))
+
+	for _, parameter := range strings.Split(parameters, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(parameter), "=")
+		if !ok || key == "" {
+			continue
+		}
+		if property.Parameters == nil {
+			property.Parameters = make(map[string]string)
+		}
+		property.Parameters[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	return property
+}
+
+// certificateNames returns the DNS names of the certificate in lower case and
+// without duplicates.
+func certificateNames(dnsNames []string) []string {
+	names := make([]string, 0, len(dnsNames))
+	seen := make(map[string]bool, len(dnsNames))
+	for _, name := range dnsNames {
+		name = strings.ToLower(strings.TrimSuffix(name, "."))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+func issuerName(issuer tlsinfo.Subject) string {
+	name := strings.Join(issuer.Organization, ", ")
+	switch {
+	case name == "":
+		return issuer.CommonName
+	case issuer.CommonName != "":
+		return name + " (" + issuer.CommonName + ")"
+	default:
+		return name
+	}
+}
+
+func statusRank(status string) int {
+	switch status {
+	case StatusUnknown:
+		return 1
+	case StatusError:
+		return 2
+	case StatusDenied:
+		return 3
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,181 @@
+package caa
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	dnsinfo "watchr/internal/dns"
+)
+
+func TestClient_Check_Permitted(t *testing.T) {
+	port := startTLSServer(t, "Let's Encrypt", "www.example.com", "example.com")
+	client := newTestClient(t,
+		`example.com. 300 IN CAA 0 issue "letsencrypt.org; validationmethods=dns-01"`,
+		`example.com. 300 IN CAA 0 iodef "mailto:security@example.com"`,
+		"www.example.com. 300 IN A 192.0.2.1",
+	)
+
+	resp, err := client.Check(context.Background(), "127.0.0.1", port, nil)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	if resp.Status != StatusPermitted {
+		t.Errorf("expected %s, got %s: %+v", StatusPermitted, resp.Status, resp.Names)
+	}
+	if resp.Issuer != "Let's Encrypt (Test Issuing CA)" || !reflect.DeepEqual(resp.IssuerDomains, []string{"letsencrypt.org"}) {
+		t.Errorf("unexpected issuer: %s %v", resp.Issuer, resp.IssuerDomains)
+	}
+
+	if len(resp.Names) != 2 {
+		t.Fatalf("expected 2 names, got %+v", resp.Names)
+	}
+
+	// www.example.com. has no CAA records, so the set of example.com. applies.
+	www := resp.Names[0]
+	if www.Name != "www.example.com" || www.Owner != "example.com" {
+		t.Errorf("expected the CAA set to be found at example.com, got %+v", www)
+	}
+	if !reflect.DeepEqual(www.Iodef, []string{"mailto:security@example.com"}) {
+		t.Errorf("unexpected iodef: %v", www.Iodef)
+	}
+}
+
+func TestClient_Check_Denied(t *testing.T) {
+	port := startTLSServer(t, "DigiCert Inc", "shop.example.com")
+	client := newTestClient(t,
+		`example.com. 300 IN CAA 0 issue "letsencrypt.org"`,
+		`example.com. 300 IN CAA 0 issue "pki.goog"`,
+	)
+
+	resp, err := client.Check(context.Background(), "127.0.0.1", port, nil)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	if resp.Status != StatusDenied {
+		t.Fatalf("expected %s, got %s", StatusDenied, resp.Status)
+	}
+
+	result := resp.Names[0]
+	if !reflect.DeepEqual(result.Authorized, []string{"letsencrypt.org", "pki.goog"}) {
+		t.Errorf("unexpected authorized CAs: %v", result.Authorized)
+	}
+	if !strings.Contains(result.Reason, "not authorized") {
+		t.Errorf("unexpected reason: %s", result.Reason)
+	}
+}
+
+func TestClient_Check_Wildcard(t *testing.T) {
+	port := startTLSServer(t, "Let's Encrypt", "*.example.com", "example.com")
+	client := newTestClient(t,
+		`example.com. 300 IN CAA 0 issue "letsencrypt.org"`,
+		`example.com. 300 IN CAA 0 issuewild ";"`,
+	)
+
+	resp, err := client.Check(context.Background(), "127.0.0.1", port, nil)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	wildcard, apex := resp.Names[0], resp.Names[1]
+	if !wildcard.Wildcard || wildcard.Status != StatusDenied || len(wildcard.Authorized) != 0 {
+		t.Errorf("expected issuewild to forbid the wildcard, got %+v", wildcard)
+	}
+	if apex.Status != StatusPermitted {
+		t.Errorf("expected issue to authorize the apex, got %+v", apex)
+	}
+	if resp.Status != StatusDenied {
+		t.Errorf("expected the worst status, got %s", resp.Status)
+	}
+}
+
+func TestClient_Check_NoCAA(t *testing.T) {
+	port := startTLSServer(t, "Example CA", "www.example.com")
+	client := newTestClient(t, "www.example.com. 300 IN A 192.0.2.1")
+
+	resp, err := client.Check(context.Background(), "127.0.0.1", port, nil)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	if resp.Status != StatusPermitted || resp.Names[0].Owner != "" {
+		t.Errorf("expected any CA to be permitted, got %+v", resp.Names)
+	}
+}
+
+func TestClient_Check_UnknownIssuer(t *testing.T) {
+	port := startTLSServer(t, "Example CA", "www.example.com")
+	client := newTestClient(t, `example.com. 300 IN CAA 0 issue "ca.example.net"`)
+
+	resp, err := client.Check(context.Background(), "127.0.0.1", port, nil)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if resp.Status != StatusUnknown {
+		t.Errorf("expected %s for an unknown CA, got %s", StatusUnknown, resp.Status)
+	}
+
+	resp, err = client.Check(context.Background(), "127.0.0.1", port, []string{"ca.example.net"})
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if resp.Status != StatusPermitted {
+		t.Errorf("expected the given issuer domain to be permitted, got %s", resp.Status)
+	}
+}
+
+func TestClient_Check_CriticalProperty(t *testing.T) {
+	port := startTLSServer(t, "Let's Encrypt", "www.example.com")
+	client := newTestClient(t,
+		`example.com. 300 IN CAA 0 issue "letsencrypt.org"`,
+		`example.com. 300 IN CAA 128 tbs "unknown"`,
+	)
+
+	resp, err := client.Check(context.Background(), "127.0.0.1", port, nil)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	if resp.Status != StatusDenied || !strings.Contains(resp.Names[0].Reason, "critical property") {
+		t.Errorf("expected an unknown critical property to forbid issuance, got %+v", resp.Names[0])
+	}
+}
+
+func TestParseProperty(t *testing.T) {
+	property := parseProperty(&dnsinfo.CAAData{Flag: 128, Tag: "Issue", Value: "LetsEncrypt.org; accounturi=https://acme.example/acct/1; validationmethods=dns-01"})
+
+	expected := Property{
+		Critical: true,
+		Tag:      "issue",
+		Value:    "LetsEncrypt.org; accounturi=https://acme.example/acct/1; validationmethods=dns-01",
+		Issuer:   "letsencrypt.org",
+		Parameters: map[string]string{
+			"accounturi":        "https://acme.example/acct/1",
+			"validationmethods": "dns-01",
+		},
+	}
+	if !reflect.DeepEqual(property, expected) {
+		t.Errorf("parseProperty = %+v, want %+v", property, expected)
+	}
+
+	if property := parseProperty(&dnsinfo.CAAData{Tag: "issue", Value: ";"}); property.Issuer != "" {
+		t.Errorf("expected an empty issuer, got %q", property.Issuer)
+	}
+}
+
+func TestIssuerDomains(t *testing.T) {
+	if domains := IssuerDomains([]string{"Google Trust Services"}); !reflect.DeepEqual(domains, []string{"pki.goog"}) {
+		t.Errorf("unexpected domains: %v", domains)
+	}
+	if domains := IssuerDomains([]string{"Example CA"}); domains != nil {
+		t.Errorf("expected no domains for an unknown CA, got %v", domains)
+	}
+	// Cloudflare certificates are signed by several CAs, so the organization
+	// alone does not tell which one.
+	if domains := IssuerDomains([]string{"Cloudflare, Inc."}); domains != nil {
+		t.Errorf("expected no domains for Cloudflare, got %v", domains)
+	}
+}
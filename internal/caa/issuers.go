@@ -0,0 +1,42 @@
+package caa
+
+import "strings"
+
+// issuerDomains maps the organization of well-known CAs, as found in the
+// issuer of the certificates they sign, to the issuer domains they recognize
+// in CAA records.
+var issuerDomains = []struct {
+	organization string
+	domains      []string
+}{
+	{"let's encrypt", []string{"letsencrypt.org"}},
+	{"google trust services", []string{"pki.goog"}},
+	{"digicert", []string{"digicert.com", "www.digicert.com", "symantec.com", "geotrust.com", "rapidssl.com", "thawte.com"}},
+	{"sectigo", []string{"sectigo.com", "comodoca.com", "comodo.com", "usertrust.com", "trust-provider.com"}},
+	{"comodo", []string{"sectigo.com", "comodoca.com", "comodo.com", "usertrust.com", "trust-provider.com"}},
+	{"zerossl", []string{"sectigo.com", "zerossl.com"}},
+	{"globalsign", []string{"globalsign.com"}},
+	{"amazon", []string{"amazon.com", "amazontrust.com", "awstrust.com", "amazonaws.com"}},
+	{"godaddy", []string{"godaddy.com", "starfieldtech.com"}},
+	{"starfield", []string{"godaddy.com", "starfieldtech.com"}},
+	{"entrust", []string{"entrust.net", "affirmtrust.com"}},
+	{"buypass", []string{"buypass.com", "buypass.no"}},
+	{"ssl corporation", []string{"ssl.com"}},
+	{"microsoft", []string{"microsoft.com"}},
+	{"actalis", []string{"actalis.it"}},
+	{"harica", []string{"harica.gr"}},
+}
+
+// IssuerDomains returns the CAA issuer domains of the CA with the given
+// organization names, or nil when the CA is not known.
+func IssuerDomains(organizations []string) []string {
+	for _, organization := range organizations {
+		organization = strings.ToLower(organization)
+		for _, issuer := range issuerDomains {
+			if strings.Contains(organization, issuer.organization) {
+				return issuer.domains
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package caa
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	mdns "github.com/miekg/dns"
+
+	dnsinfo "watchr/internal/dns"
+)
+
+// newTestClient runs a local DNS server answering from the given records, and
+// NXDOMAIN for names it has no records for, and returns a client using it.
+func newTestClient(t *testing.T, records ...string) *Client {
+	t.Helper()
+
+	rrs := make([]mdns.RR, 0, len(records))
+	for _, record := range records {
+		rr, err := mdns.NewRR(record)
+		if err != nil {
+			t.Fatalf("failed to parse %q: %v", record, err)
+		}
+		rrs = append(rrs, rr)
+	}
+
+	handler := func(w mdns.ResponseWriter, r *mdns.Msg) {
+		m := new(mdns.Msg)
+		m.SetReply(r)
+
+		q := r.Question[0]
+		exists := false
+		for _, rr := range rrs {
+			header := rr.Header()
+			if mdns.CanonicalName(header.Name) != mdns.CanonicalName(q.Name) {
+				continue
+			}
+			exists = true
+			if header.Rrtype == q.Qtype {
+				m.Answer = append(m.Answer, rr)
+			}
+		}
+		if !exists {
+			m.Rcode = mdns.RcodeNameError
+		}
+
+		_ = w.WriteMsg(m)
+	}
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen on UDP: %v", err)
+	}
+
+	server := &mdns.Server{PacketConn: pc, Handler: mdns.HandlerFunc(handler)}
+	go func() {
+		_ = server.ActivateAndServe()
+	}()
+	t.Cleanup(func() {
+		_ = server.Shutdown()
+	})
+
+	return NewClient(5*time.Second, dnsinfo.NewClient(5*time.Second, pc.LocalAddr().String()))
+}
+
+// startTLSServer runs a local TLS server presenting a certificate for dnsNames
+// issued by a CA with the given organization, and returns its port.
+func startTLSServer(t *testing.T, organization string, dnsNames ...string) string {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{Organization: []string{organization}, CommonName: "Test Issuing CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to create CA certificate: %v", err)
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatalf("failed to parse CA certificate: %v", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(90 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     dnsNames,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+
+	cert := tls.Certificate{Certificate: [][]byte{der, caDER}, PrivateKey: key}
+	l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatalf("failed to listen on TLS: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = l.Close()
+	})
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.(*tls.Conn).Handshake()
+			_ = conn.Close()
+		}
+	}()
+
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	return port
+}
@@ -0,0 +1,56 @@
+package caa
+
+import (
+	"time"
+
+	"watchr/internal/idn"
+)
+
+// Evaluation statuses, from best to worst.
+const (
+	StatusPermitted = "permitted"
+	StatusUnknown   = "unknown"
+	StatusError     = "error"
+	StatusDenied    = "denied"
+)
+
+type Response struct {
+	Host      string        `json:"host"`
+	IDN       *idn.Name     `json:"idn,omitempty"`
+	Port      string        `json:"port"`
+	QueryTime time.Duration `json:"queryTime"`
+	// Issuer describes the CA that issued the served leaf certificate, and
+	// IssuerDomains are the CAA issuer domains it is known by.
+	Issuer        string   `json:"issuer"`
+	IssuerDomains []string `json:"issuerDomains"`
+	// Status is the worst status of all the names.
+	Status string       `json:"status"`
+	Names  []NameResult `json:"names"`
+}
+
+// NameResult is the evaluation of the CAA records relevant to one of the
+// certificate names. Owner is the name the relevant CAA set was found at,
+// empty when no name up the tree has CAA records.
+type NameResult struct {
+	Name       string     `json:"name"`
+	Wildcard   bool       `json:"wildcard"`
+	Owner      string     `json:"owner,omitempty"`
+	Properties []Property `json:"properties,omitempty"`
+	// Authorized lists the issuer domains allowed to issue for the name by
+	// the issue or issuewild properties that apply to it.
+	Authorized []string `json:"authorized,omitempty"`
+	Iodef      []string `json:"iodef,omitempty"`
+	Status     string   `json:"status"`
+	Reason     string   `json:"reason"`
+	Error      string   `json:"error,omitempty"`
+}
+
+type Property struct {
+	Critical bool   `json:"critical"`
+	Tag      string `json:"tag"`
+	Value    string `json:"value"`
+	// Issuer is the issuer domain of issue and issuewild properties, empty
+	// when the property forbids issuance.
+	Issuer     string            `json:"issuer,omitempty"`
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
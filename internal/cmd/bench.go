@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	dnsinfo "watchr/internal/dns"
+	"watchr/internal/idn"
+	"watchr/internal/output"
+)
+
+func NewDNSBenchCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bench <domain>",
+		Short: "Benchmark the latency of DNS resolvers",
+		Long: `Benchmark the latency of one or more DNS resolvers.
+
+Every resolver gets --count cached queries for the domain, after a first query
+that warms its cache, and --count uncached queries for random labels under the
+domain, which the resolver has to forward to the authoritative servers. Up to
+--concurrency queries are in flight at once, and the resolvers are measured one
+after the other.
+
+The minimum, median, 95th and 99th percentile and maximum latency of the
+answered queries are reported for each resolver, along with the share of
+queries that timed out or failed.
+
+Resolvers are given with --server, which can be repeated, or as a named set with
+--resolvers (public, google, cloudflare, quad9, opendns, dot, doh). Without
+them, the system resolver is measured.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runDNSBench,
+	}
+
+	cmd.Flags().StringArrayP("server", "s", nil, "DNS server to benchmark, can be repeated (default: system resolver)")
+	cmd.Flags().String("resolvers", "", "Named resolver set to benchmark (public, google, cloudflare, quad9, opendns, dot, doh)")
+	cmd.Flags().StringP("type", "T", "A", "Record type to query")
+	cmd.Flags().IntP("count", "n", 100, "number of cached and of uncached queries sent to each resolver")
+	cmd.Flags().Int("concurrency", 10, "number of queries in flight at the same time")
+
+	return cmd
+}
+
+func runDNSBench(cmd *cobra.Command, args []string) error {
+	domain, err := idn.ToASCII(args[0])
+	if err != nil {
+		return err
+	}
+	timeoutSecs, _ := cmd.Flags().GetInt("timeout")
+	timeout := time.Duration(timeoutSecs) * time.Second
+	format, _ := cmd.Flags().GetString("format")
+	servers, _ := cmd.Flags().GetStringArray("server")
+	resolverSet, _ := cmd.Flags().GetString("resolvers")
+	recordType, _ := cmd.Flags().GetString("type")
+	count, _ := cmd.Flags().GetInt("count")
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+
+	if resolverSet != "" {
+		setServers, err := dnsinfo.ResolverSet(resolverSet)
+		if err != nil {
+			return err
+		}
+		servers = append(servers, setServers...)
+	}
+
+	if len(servers) == 0 {
+		servers = []string{""}
+	}
+
+	ctx := context.Background()
+
+	formatter := output.NewFormatter(format, cmd.OutOrStdout())
+
+	slog.Info("benchmarking DNS resolvers", "domain", domain, "type", recordType, "servers", servers, "count", count, "concurrency", concurrency, "timeout", timeout)
+
+	resp, err := dnsinfo.Bench(ctx, timeout, domain, recordType, servers, count, concurrency)
+	if err != nil {
+		return err
+	}
+
+	return formatter.OutputDNSBench(resp)
+}
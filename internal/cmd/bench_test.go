@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDNSBenchCommand_Execute(t *testing.T) {
+	cmd := NewDNSBenchCommand()
+
+	if !strings.HasPrefix(cmd.Use, "bench") {
+		t.Errorf("expected Use to start with 'bench', got %s", cmd.Use)
+	}
+
+	if cmd.Short == "" {
+		t.Error("expected non-empty Short description")
+	}
+
+	if cmd.RunE == nil {
+		t.Error("expected RunE to be set")
+	}
+}
+
+func TestDNSBenchCommand_Flags(t *testing.T) {
+	cmd := NewDNSBenchCommand()
+
+	for _, name := range []string{"server", "resolvers", "type", "count", "concurrency"} {
+		if cmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected --%s flag to be defined", name)
+		}
+	}
+
+	if flag := cmd.Flags().Lookup("count"); flag.DefValue != "100" {
+		t.Errorf("expected --count to default to 100, got %s", flag.DefValue)
+	}
+}
+
+func TestDNSBenchCommand_UnknownResolverSet(t *testing.T) {
+	cmd := NewDNSBenchCommand()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+
+	cmd.SetArgs([]string{"--resolvers", "nope", "example.com"})
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "unknown resolver set") {
+		t.Errorf("expected an unknown resolver set error, got %v", err)
+	}
+}
+
+func TestDNSBenchCommand_InvalidCount(t *testing.T) {
+	cmd := NewDNSBenchCommand()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+
+	cmd.SetArgs([]string{"--count", "0", "--server", "127.0.0.1", "example.com"})
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "query count") {
+		t.Errorf("expected an invalid count error, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	dnsinfo "watchr/internal/dns"
+	"watchr/internal/idn"
+	"watchr/internal/output"
+	"watchr/internal/rdap"
+)
+
+func NewDelegationCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delegation <domain>",
+		Short: "Check parent/child delegation and glue consistency",
+		Long: `Compare the delegation of a domain published by its parent zone with the
+NS set served by the child zone.
+
+The parent zone's servers are asked for the domain's NS records and glue, and
+the delegated servers are asked for the NS set and nameserver addresses they
+serve. Parent/child NS mismatches, in-bailiwick nameservers without glue, and
+glue that is missing or stale compared to the child zone are reported.
+
+The nameservers registered for the domain are fetched over RDAP and compared
+with the parent NS set as well, unless --no-rdap is given.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runDelegation,
+	}
+
+	cmd.Flags().StringP("server", "s", "", "DNS server used to find the parent zone and resolve nameservers (default: system resolver)")
+	cmd.Flags().Bool("no-rdap", false, "Do not compare with the nameservers registered over RDAP")
+
+	return cmd
+}
+
+func runDelegation(cmd *cobra.Command, args []string) error {
+	domain, err := idn.ToASCII(args[0])
+	if err != nil {
+		return err
+	}
+	timeoutSecs, _ := cmd.Flags().GetInt("timeout")
+	timeout := time.Duration(timeoutSecs) * time.Second
+	format, _ := cmd.Flags().GetString("format")
+	server, _ := cmd.Flags().GetString("server")
+	noRDAP, _ := cmd.Flags().GetBool("no-rdap")
+
+	ctx := context.Background()
+
+	dnsClient := dnsinfo.NewClient(timeout, server)
+	formatter := output.NewFormatter(format, cmd.OutOrStdout())
+
+	registryNS := make([]string, 0)
+	if !noRDAP {
+		rdapResp, err := rdap.NewClient(timeout).QueryDomain(ctx, domain)
+		if err != nil {
+			slog.Debug("RDAP query failed, skipping registry comparison", "error", err)
+		} else {
+			for _, ns := range rdapResp.Nameservers {
+				registryNS = append(registryNS, ns.LDHName)
+			}
+		}
+	}
+
+	slog.Info("checking delegation", "domain", domain, "server", server, "timeout", timeout)
+
+	resp, err := dnsClient.CheckDelegation(ctx, domain, registryNS)
+	if err != nil {
+		return err
+	}
+
+	return formatter.OutputDelegation(resp)
+}
+
+func init() {
+	AddCommand(NewDelegationCommand())
+}
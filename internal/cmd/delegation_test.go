@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDelegationCommand_Execute(t *testing.T) {
+	cmd := NewDelegationCommand()
+
+	if !strings.HasPrefix(cmd.Use, "delegation") {
+		t.Errorf("expected Use to start with 'delegation', got %s", cmd.Use)
+	}
+
+	if cmd.Short == "" {
+		t.Error("expected non-empty Short description")
+	}
+
+	if cmd.RunE == nil {
+		t.Error("expected RunE to be set")
+	}
+}
+
+func TestDelegationCommand_RequiresArgument(t *testing.T) {
+	cmd := NewDelegationCommand()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+
+	cmd.SetArgs([]string{})
+
+	err := cmd.Execute()
+	if err == nil {
+		t.Error("expected error when no domain argument provided")
+	}
+}
+
+func TestDelegationCommand_Flags(t *testing.T) {
+	cmd := NewDelegationCommand()
+
+	if cmd.Flags().Lookup("server") == nil {
+		t.Fatal("expected --server flag to be defined")
+	}
+
+	flag := cmd.Flags().Lookup("no-rdap")
+	if flag == nil {
+		t.Fatal("expected --no-rdap flag to be defined")
+	}
+
+	if flag.DefValue != "false" {
+		t.Errorf("expected --no-rdap to default to false, got %s", flag.DefValue)
+	}
+}
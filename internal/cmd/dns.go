@@ -2,46 +2,323 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"net"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	dnsinfo "watchr/internal/dns"
+	"watchr/internal/idn"
 	"watchr/internal/output"
 )
 
 func NewDNSCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "dns <domain>",
+		Use:   "dns <domain|ip>",
 		Short: "Perform DNS lookups",
 		Long: `Query DNS records for a domain.
 
 The command queries DNS records from the specified nameserver (default: system resolver).
-Supports common record types including A, AAAA, MX, NS, CNAME, TXT, SOA, SRV, PTR, and CAA.`,
+Without --server, every nameserver listed in /etc/resolv.conf is tried in turn,
+honoring its timeout, attempts and rotate options, and the failed attempts are
+shown with the answer. Use --search to expand the name with the search list,
+following the ndots option, as the system resolver does.
+Supports common record types including A, AAAA, CNAME, DNAME, MX, NS, TXT, SOA, SRV,
+PTR, CAA, HTTPS, SVCB, TLSA, SSHFP, NAPTR, URI, LOC, DS, and DNSKEY. Other types can
+be queried with the RFC 3597 generic form (e.g. --type TYPE65534), and records of
+unknown types are shown in the generic \# presentation format.
+
+When the name is an alias, its CNAME chain is shown hop by hop with the TTL of
+each CNAME and the records of the final target, following the chain with
+further queries when the answer stops short. CNAME loops, chains longer than 8
+hops and dangling CNAMEs whose target does not exist (NXDOMAIN) are reported.
+
+Use --wildcard to query random labels under the parent of the name, or its
+closest existing ancestor in the zone, and detect a wildcard record. The
+wildcard answer is reported, and records matching it are marked as such, since
+they may only exist because of the wildcard.
+
+Several record types can be queried at once by passing a comma-separated list
+to --type (e.g. --type A,AAAA,MX), or all supported types with --all. The
+queries are sent concurrently and the results are grouped per type.
+
+Use --dnssec to validate the DNSSEC chain of trust from the root trust anchor
+down to the queried name. Each link is reported as secure, insecure, bogus or
+indeterminate along with the reason. The IANA root KSKs are used as trust
+anchors unless --trust-anchor is given.
+
+Use --trace to resolve the name iteratively, starting from the root servers and
+following referrals down to the authoritative servers. Every hop is shown with
+the server queried, the referral or answer received, the latency, and any glue.
+
+DNS-over-TLS servers can be queried with --server tls://host[:port] (port
+defaults to 853). The certificate is verified against the host, or against the
+authentication name given with --tls-server-name or as tls://host#name. Use
+--spki-pin to additionally require a public key pin.
+
+DNS-over-HTTPS (RFC 8484) servers can be queried by passing their URL, e.g.
+--server https://dns.google/dns-query. Queries are sent with POST by default,
+use --doh-method GET to send them as a query parameter instead.
+
+When an IPv4 or IPv6 address is given instead of a domain, its in-addr.arpa or
+ip6.arpa name is queried for PTR records. Use --fcrdns to also resolve every PTR
+hostname back to its addresses and confirm that one of them matches
+(forward-confirmed reverse DNS).
+
+Use --compare with several --server values, or --resolvers with a named set of
+public resolvers, to query them all concurrently and compare their answers.
+Resolvers whose answer differs from the majority are marked with "!", which
+helps tracking the propagation of a DNS change. TTLs are not compared.
+
+Use --audit to resolve the zone's NS set and query every authoritative server
+directly, over IPv4 and IPv6 and without recursion. Their SOA serials, NS sets
+and --type records are compared, and servers that are out of sync, lame or
+unreachable are reported.
+
+Use --axfr to request a zone transfer from every authoritative server of the
+zone, or --ixfr with a SOA serial to request only the changes since that serial.
+The records of the first server allowing the transfer are streamed as they
+arrive, in zone-file format or, with --format json, as one JSON object per
+line, followed by a summary reporting the servers that refused the transfer. Requests can be signed with
+--tsig [algorithm:]name:secret (algorithm defaults to hmac-sha256).
+
+Use --subnet to attach an EDNS Client Subnet option (e.g. --subnet 203.0.113.0/24)
+and see the answer a geo-steering server gives to clients in that network; the
+scope prefix returned by the server is shown with the EDNS options. Other EDNS
+settings can be given with --edns-opt: nsid, cookie[=hex], padding[=block size],
+bufsize=size and do.
+
+Use "watchr dns enum <zone> --wordlist file" to enumerate the subdomains of a
+zone from a wordlist, and "watchr dns bench <domain>" to compare the latency of
+resolvers.
+
+Plain DNS queries are sent over UDP and retried over TCP when the answer is
+truncated. Use --tcp (or --server tcp://host) to always use TCP.`,
 		Args: cobra.ExactArgs(1),
 		RunE: runDNS,
 	}
 
-	cmd.Flags().StringP("type", "T", "A", "Record type (A, AAAA, MX, NS, CNAME, TXT, SOA, SRV, PTR, CAA)")
-	cmd.Flags().StringP("server", "s", "", "DNS server to query (default: system resolver, port defaults to 53)")
+	cmd.Flags().StringP("type", "T", "A", "Record type, or comma-separated list of types (e.g. A, AAAA, MX, TXT, HTTPS, TLSA, or TYPEnnn)")
+	cmd.Flags().Bool("all", false, "Query all supported record types")
+	cmd.Flags().Bool("dnssec", false, "Validate the DNSSEC chain of trust for the answer")
+	cmd.Flags().Bool("audit", false, "Audit the zone's authoritative nameservers for consistency")
+	cmd.Flags().Bool("axfr", false, "Request a full zone transfer (AXFR) from every authoritative server")
+	cmd.Flags().Uint32("ixfr", 0, "Request an incremental zone transfer (IXFR) of the changes since this SOA serial")
+	cmd.Flags().String("tsig", "", "TSIG key for zone transfers, as [algorithm:]name:secret with a base64 secret")
+	cmd.Flags().Bool("compare", false, "Query every --server concurrently and compare their answers")
+	cmd.Flags().String("resolvers", "", "Named resolver set to compare (public, google, cloudflare, quad9, opendns, dot, doh)")
+	cmd.Flags().Bool("fcrdns", false, "Confirm the PTR hostnames of an IP address resolve back to it")
+	cmd.Flags().Bool("trace", false, "Trace the resolution from the root servers down to the authoritative servers")
+	cmd.Flags().StringSlice("trust-anchor", nil, "Root trust anchor as a DS record (e.g. \". IN DS 20326 8 2 E06D...\"), can be repeated")
+	cmd.Flags().StringArrayP("server", "s", nil, "DNS server to query, as [udp://|tcp://]host[:port], tls://host[:port][#name] or an https:// URL (default: system resolver), can be repeated with --compare")
+	cmd.Flags().String("tls-server-name", "", "Name used to authenticate DNS-over-TLS servers (default: server host)")
+	cmd.Flags().StringSlice("spki-pin", nil, "Base64 SHA-256 SPKI pin required in the DNS-over-TLS/HTTPS certificate chain, can be repeated")
+	cmd.Flags().Bool("wildcard", false, "Probe random labels under the parent of the name to detect a wildcard and mark the records matching it")
+	cmd.Flags().Bool("search", false, "Expand the name with the search list from /etc/resolv.conf")
+	cmd.Flags().String("subnet", "", "Client subnet to send in an EDNS Client Subnet option (e.g. 203.0.113.0/24)")
+	cmd.Flags().StringArray("edns-opt", nil, "EDNS setting to send: nsid, cookie[=hex], padding[=block], bufsize=size or do, can be repeated")
+	cmd.Flags().Bool("tcp", false, "Always send plain DNS queries over TCP")
+	cmd.Flags().String("doh-method", "POST", "HTTP method for DNS-over-HTTPS queries (GET|POST)")
+
+	cmd.AddCommand(NewDNSEnumCommand())
+	cmd.AddCommand(NewDNSBenchCommand())
 
 	return cmd
 }
 
 func runDNS(cmd *cobra.Command, args []string) error {
-	domain := args[0]
+	domain, err := idn.ToASCII(args[0])
+	if err != nil {
+		return err
+	}
 	timeoutSecs, _ := cmd.Flags().GetInt("timeout")
 	timeout := time.Duration(timeoutSecs) * time.Second
 	format, _ := cmd.Flags().GetString("format")
 	recordType, _ := cmd.Flags().GetString("type")
-	server, _ := cmd.Flags().GetString("server")
+	servers, _ := cmd.Flags().GetStringArray("server")
+	tlsServerName, _ := cmd.Flags().GetString("tls-server-name")
+	spkiPins, _ := cmd.Flags().GetStringSlice("spki-pin")
+	dohMethod, _ := cmd.Flags().GetString("doh-method")
+	useTCP, _ := cmd.Flags().GetBool("tcp")
+	all, _ := cmd.Flags().GetBool("all")
+	dnssec, _ := cmd.Flags().GetBool("dnssec")
+	trustAnchors, _ := cmd.Flags().GetStringSlice("trust-anchor")
+	trace, _ := cmd.Flags().GetBool("trace")
+	fcrdns, _ := cmd.Flags().GetBool("fcrdns")
+	compare, _ := cmd.Flags().GetBool("compare")
+	audit, _ := cmd.Flags().GetBool("audit")
+	resolverSet, _ := cmd.Flags().GetString("resolvers")
+	axfr, _ := cmd.Flags().GetBool("axfr")
+	ixfrSerial, _ := cmd.Flags().GetUint32("ixfr")
+	ixfr := cmd.Flags().Changed("ixfr")
+	tsig, _ := cmd.Flags().GetString("tsig")
+	search, _ := cmd.Flags().GetBool("search")
+	wildcard, _ := cmd.Flags().GetBool("wildcard")
+	subnet, _ := cmd.Flags().GetString("subnet")
+	ednsOptions, _ := cmd.Flags().GetStringArray("edns-opt")
+
+	tsigAlgorithm, tsigName, tsigSecret, err := parseTSIG(tsig)
+	if err != nil {
+		return err
+	}
+
+	ednsConfig, err := dnsinfo.ParseEDNSConfig(subnet, ednsOptions)
+	if err != nil {
+		return err
+	}
+
+	if resolverSet != "" {
+		setServers, err := dnsinfo.ResolverSet(resolverSet)
+		if err != nil {
+			return err
+		}
+		servers = append(servers, setServers...)
+		compare = true
+	}
+
+	if len(servers) > 1 {
+		compare = true
+	}
+
+	server := ""
+	if len(servers) > 0 {
+		server = servers[0]
+	}
 
 	ctx := context.Background()
 
-	dnsClient := dnsinfo.NewClient(timeout, server)
+	dnsClient := dnsinfo.NewClient(timeout, server,
+		dnsinfo.WithTLSServerName(tlsServerName),
+		dnsinfo.WithSPKIPins(spkiPins),
+		dnsinfo.WithDoHMethod(dohMethod),
+		dnsinfo.WithTCP(useTCP),
+		dnsinfo.WithTSIG(tsigName, tsigAlgorithm, tsigSecret),
+		dnsinfo.WithEDNS(ednsConfig),
+		dnsinfo.WithSearch(search),
+	)
 	formatter := output.NewFormatter(format, cmd.OutOrStdout())
 
+	isIP := net.ParseIP(domain) != nil
+	if fcrdns {
+		if !isIP {
+			return fmt.Errorf("--fcrdns requires an IP address, got %s", domain)
+		}
+
+		slog.Info("checking forward-confirmed reverse DNS", "address", domain, "server", server, "timeout", timeout)
+
+		resp, err := dnsClient.ForwardConfirm(ctx, domain)
+		if err != nil {
+			return err
+		}
+
+		return formatter.OutputFCrDNS(resp)
+	}
+
+	if isIP {
+		reverseName, err := dnsinfo.ReverseName(domain)
+		if err != nil {
+			return err
+		}
+
+		domain = reverseName
+		if !cmd.Flags().Changed("type") {
+			recordType = "PTR"
+		}
+	}
+
+	if axfr || ixfr {
+		slog.Info("requesting zone transfer", "zone", domain, "ixfr", ixfr, "serial", ixfrSerial, "server", server, "timeout", timeout)
+
+		resp, err := dnsClient.Transfer(ctx, domain, ixfr, ixfrSerial, formatter.DNSTransferRecords())
+		if err != nil {
+			return err
+		}
+
+		return formatter.OutputDNSTransfer(resp)
+	}
+
+	if audit {
+		slog.Info("auditing authoritative nameservers", "zone", domain, "type", recordType, "server", server, "timeout", timeout)
+
+		resp, err := dnsClient.AuditNameservers(ctx, domain, recordType)
+		if err != nil {
+			return err
+		}
+
+		return formatter.OutputNSAudit(resp)
+	}
+
+	if compare {
+		if len(servers) == 0 {
+			servers, _ = dnsinfo.ResolverSet("public")
+		}
+
+		slog.Info("comparing DNS answers", "domain", domain, "type", recordType, "servers", servers, "timeout", timeout)
+
+		resp, err := dnsClient.Compare(ctx, domain, recordType, servers)
+		if err != nil {
+			return err
+		}
+
+		return formatter.OutputDNSCompare(resp)
+	}
+
+	if trace {
+		slog.Info("tracing DNS resolution", "domain", domain, "type", recordType, "timeout", timeout)
+
+		resp, err := dnsClient.Trace(ctx, domain, recordType, nil)
+		if err != nil {
+			return err
+		}
+
+		return formatter.OutputDNSTrace(resp)
+	}
+
+	if dnssec {
+		slog.Info("validating DNSSEC", "domain", domain, "type", recordType, "server", server, "timeout", timeout)
+
+		resp, err := dnsClient.ValidateDNSSEC(ctx, domain, recordType, trustAnchors)
+		if err != nil {
+			return err
+		}
+
+		return formatter.OutputDNSSEC(resp)
+	}
+
+	recordTypes := strings.Split(recordType, ",")
+	if all {
+		recordTypes = dnsinfo.SupportedRecordTypes()
+	}
+
+	if len(recordTypes) > 1 {
+		if wildcard {
+			return fmt.Errorf("--wildcard requires a single record type")
+		}
+
+		slog.Info("querying DNS", "domain", domain, "types", recordTypes, "server", server, "timeout", timeout)
+
+		resp, err := dnsClient.QueryMany(ctx, domain, recordTypes)
+		if err != nil {
+			return err
+		}
+
+		return formatter.OutputDNSMulti(resp)
+	}
+
+	if wildcard {
+		slog.Info("querying DNS with wildcard detection", "domain", domain, "type", recordType, "server", server, "timeout", timeout)
+
+		resp, err := dnsClient.QueryWildcard(ctx, domain, recordType)
+		if err != nil {
+			return err
+		}
+
+		return formatter.OutputDNS(resp)
+	}
+
 	slog.Info("querying DNS", "domain", domain, "type", recordType, "server", server, "timeout", timeout)
 
 	resp, err := dnsClient.Query(ctx, domain, recordType)
@@ -52,6 +329,27 @@ func runDNS(cmd *cobra.Command, args []string) error {
 	return formatter.OutputDNS(resp)
 }
 
+// parseTSIG splits a TSIG key given as "[algorithm:]name:secret".
+func parseTSIG(value string) (string, string, string, error) {
+	if value == "" {
+		return "", "", "", nil
+	}
+
+	parts := strings.Split(value, ":")
+	switch len(parts) {
+	case 2:
+		if parts[0] != "" && parts[1] != "" {
+			return "", parts[0], parts[1], nil
+		}
+	case 3:
+		if parts[0] != "" && parts[1] != "" && parts[2] != "" {
+			return parts[0], parts[1], parts[2], nil
+		}
+	}
+
+	return "", "", "", fmt.Errorf("invalid TSIG key %q, expected [algorithm:]name:secret", value)
+}
+
 func init() {
 	AddCommand(NewDNSCommand())
 }
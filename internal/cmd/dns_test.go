@@ -100,3 +100,286 @@ func TestDNSCommand_WithCustomServer(t *testing.T) {
 		t.Error("expected output to contain custom nameserver")
 	}
 }
+
+func TestDNSCommand_WithMultipleTypes(t *testing.T) {
+	cmd := NewDNSCommand()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+
+	cmd.SetArgs([]string{"example.com", "--type", "A,AAAA"})
+
+	err := cmd.Execute()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "example.com") {
+		t.Error("expected output to contain domain")
+	}
+	if !strings.Contains(output, "AAAA") {
+		t.Error("expected output to contain AAAA record type")
+	}
+}
+
+func TestDNSCommand_AllFlag(t *testing.T) {
+	cmd := NewDNSCommand()
+
+	flag := cmd.Flags().Lookup("all")
+	if flag == nil {
+		t.Fatal("expected --all flag to be defined")
+	}
+
+	if flag.DefValue != "false" {
+		t.Errorf("expected --all to default to false, got %s", flag.DefValue)
+	}
+}
+
+func TestDNSCommand_DNSSECFlags(t *testing.T) {
+	cmd := NewDNSCommand()
+
+	if cmd.Flags().Lookup("dnssec") == nil {
+		t.Fatal("expected --dnssec flag to be defined")
+	}
+
+	if cmd.Flags().Lookup("trust-anchor") == nil {
+		t.Fatal("expected --trust-anchor flag to be defined")
+	}
+}
+
+func TestDNSCommand_DNSSECInvalidTrustAnchor(t *testing.T) {
+	cmd := NewDNSCommand()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+
+	cmd.SetArgs([]string{"example.com", "--dnssec", "--trust-anchor", "not a record"})
+
+	err := cmd.Execute()
+	if err == nil {
+		t.Error("expected error for invalid trust anchor")
+	}
+}
+
+func TestDNSCommand_TraceFlag(t *testing.T) {
+	cmd := NewDNSCommand()
+
+	flag := cmd.Flags().Lookup("trace")
+	if flag == nil {
+		t.Fatal("expected --trace flag to be defined")
+	}
+
+	if flag.DefValue != "false" {
+		t.Errorf("expected --trace to default to false, got %s", flag.DefValue)
+	}
+}
+
+func TestDNSCommand_TLSFlags(t *testing.T) {
+	cmd := NewDNSCommand()
+
+	if cmd.Flags().Lookup("tls-server-name") == nil {
+		t.Fatal("expected --tls-server-name flag to be defined")
+	}
+
+	if cmd.Flags().Lookup("spki-pin") == nil {
+		t.Fatal("expected --spki-pin flag to be defined")
+	}
+
+	flag := cmd.Flags().Lookup("doh-method")
+	if flag == nil {
+		t.Fatal("expected --doh-method flag to be defined")
+	}
+
+	if flag.DefValue != "POST" {
+		t.Errorf("expected --doh-method to default to POST, got %s", flag.DefValue)
+	}
+}
+
+func TestDNSCommand_TCPFlag(t *testing.T) {
+	cmd := NewDNSCommand()
+
+	flag := cmd.Flags().Lookup("tcp")
+	if flag == nil {
+		t.Fatal("expected --tcp flag to be defined")
+	}
+
+	if flag.DefValue != "false" {
+		t.Errorf("expected --tcp to default to false, got %s", flag.DefValue)
+	}
+}
+
+func TestDNSCommand_FCrDNSRequiresIP(t *testing.T) {
+	cmd := NewDNSCommand()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+
+	cmd.SetArgs([]string{"example.com", "--fcrdns"})
+
+	err := cmd.Execute()
+	if err == nil {
+		t.Error("expected error when --fcrdns is used with a domain")
+	}
+}
+
+func TestDNSCommand_CompareFlags(t *testing.T) {
+	cmd := NewDNSCommand()
+
+	if cmd.Flags().Lookup("compare") == nil {
+		t.Fatal("expected --compare flag to be defined")
+	}
+
+	if cmd.Flags().Lookup("resolvers") == nil {
+		t.Fatal("expected --resolvers flag to be defined")
+	}
+}
+
+func TestDNSCommand_UnknownResolverSet(t *testing.T) {
+	cmd := NewDNSCommand()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+
+	cmd.SetArgs([]string{"example.com", "--resolvers", "unknown"})
+
+	err := cmd.Execute()
+	if err == nil {
+		t.Error("expected error for an unknown resolver set")
+	}
+}
+
+func TestDNSCommand_AuditFlag(t *testing.T) {
+	cmd := NewDNSCommand()
+
+	flag := cmd.Flags().Lookup("audit")
+	if flag == nil {
+		t.Fatal("expected --audit flag to be defined")
+	}
+
+	if flag.DefValue != "false" {
+		t.Errorf("expected --audit to default to false, got %s", flag.DefValue)
+	}
+}
+
+func TestDNSCommand_TransferFlags(t *testing.T) {
+	cmd := NewDNSCommand()
+
+	for _, name := range []string{"axfr", "ixfr", "tsig"} {
+		if cmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected --%s flag to be defined", name)
+		}
+	}
+}
+
+func TestDNSCommand_InvalidTSIG(t *testing.T) {
+	cmd := NewDNSCommand()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+
+	cmd.SetArgs([]string{"example.com", "--axfr", "--tsig", "missing-secret"})
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "invalid TSIG key") {
+		t.Errorf("expected invalid TSIG key error, got %v", err)
+	}
+}
+
+func TestParseTSIG(t *testing.T) {
+	tests := []struct {
+		value     string
+		algorithm string
+		name      string
+		secret    string
+		wantErr   bool
+	}{
+		{value: ""},
+		{value: "transfer.key:c2VjcmV0", name: "transfer.key", secret: "c2VjcmV0"},
+		{value: "hmac-sha512:transfer.key:c2VjcmV0", algorithm: "hmac-sha512", name: "transfer.key", secret: "c2VjcmV0"},
+		{value: "transfer.key", wantErr: true},
+		{value: "transfer.key:", wantErr: true},
+		{value: "a:b:c:d", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		algorithm, name, secret, err := parseTSIG(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseTSIG(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if algorithm != tt.algorithm || name != tt.name || secret != tt.secret {
+			t.Errorf("parseTSIG(%q) = %q, %q, %q", tt.value, algorithm, name, secret)
+		}
+	}
+}
+
+func TestDNSCommand_EDNSFlags(t *testing.T) {
+	cmd := NewDNSCommand()
+
+	for _, name := range []string{"subnet", "edns-opt"} {
+		if cmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected --%s flag to be defined", name)
+		}
+	}
+}
+
+func TestDNSCommand_InvalidSubnet(t *testing.T) {
+	cmd := NewDNSCommand()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+
+	cmd.SetArgs([]string{"example.com", "--subnet", "203.0.113.0/40"})
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "invalid client subnet") {
+		t.Errorf("expected invalid client subnet error, got %v", err)
+	}
+}
+
+func TestDNSCommand_SearchFlag(t *testing.T) {
+	cmd := NewDNSCommand()
+
+	flag := cmd.Flags().Lookup("search")
+	if flag == nil {
+		t.Fatal("expected --search flag to be defined")
+	}
+
+	if flag.DefValue != "false" {
+		t.Errorf("expected --search to default to false, got %s", flag.DefValue)
+	}
+}
+
+func TestDNSCommand_WildcardFlag(t *testing.T) {
+	cmd := NewDNSCommand()
+
+	if cmd.Flags().Lookup("wildcard") == nil {
+		t.Fatal("expected --wildcard flag to be defined")
+	}
+}
+
+func TestDNSCommand_WildcardRequiresSingleType(t *testing.T) {
+	cmd := NewDNSCommand()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+
+	cmd.SetArgs([]string{"--wildcard", "--type", "A,AAAA", "example.com"})
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "single record type") {
+		t.Errorf("expected --wildcard to reject several types, got %v", err)
+	}
+}
+
+func TestDNSCommand_Subcommands(t *testing.T) {
+	cmd := NewDNSCommand()
+
+	for _, name := range []string{"enum", "bench"} {
+		sub, _, err := cmd.Find([]string{name, "example.com"})
+		if err != nil || sub.Name() != name {
+			t.Errorf("expected the %s subcommand, got %v (%v)", name, sub, err)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	dnsinfo "watchr/internal/dns"
+	"watchr/internal/idn"
+	"watchr/internal/output"
+)
+
+func NewDNSEnumCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "enum <zone>",
+		Short: "Enumerate subdomains from a wordlist",
+		Long: `Enumerate the subdomains of a zone by resolving every label of a wordlist.
+
+The wordlist has one label per line; blank lines and # comments are skipped.
+Names are resolved concurrently by --concurrency workers, sending at most --rate
+queries per second (0 disables the limit). A name is found when it is answered
+with NOERROR, even without records of --type.
+
+The zone is first probed with random labels for a wildcard. Names answered with
+the wildcard's records are not reported, as they cannot be told apart from
+names that do not exist, and are only counted.
+
+Results are printed as a table, or with --format ndjson as one JSON object per
+name. --format json prints the results along with the summary.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runDNSEnum,
+	}
+
+	cmd.Flags().StringP("wordlist", "w", "", "file with labels to resolve, one per line")
+	cmd.Flags().StringP("type", "T", "A", "Record type to query for each name")
+	cmd.Flags().Int("concurrency", 20, "number of queries in flight at the same time")
+	cmd.Flags().Int("rate", 100, "maximum queries per second (0 for no limit)")
+	cmd.Flags().StringP("server", "s", "", "DNS server to query (default: system resolver)")
+	_ = cmd.MarkFlagRequired("wordlist")
+
+	return cmd
+}
+
+func runDNSEnum(cmd *cobra.Command, args []string) error {
+	zone, err := idn.ToASCII(args[0])
+	if err != nil {
+		return err
+	}
+	timeoutSecs, _ := cmd.Flags().GetInt("timeout")
+	timeout := time.Duration(timeoutSecs) * time.Second
+	format, _ := cmd.Flags().GetString("format")
+	wordlist, _ := cmd.Flags().GetString("wordlist")
+	recordType, _ := cmd.Flags().GetString("type")
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	rate, _ := cmd.Flags().GetInt("rate")
+	server, _ := cmd.Flags().GetString("server")
+
+	if rate < 0 {
+		return fmt.Errorf("invalid --rate %d, expected 0 or more queries per second", rate)
+	}
+
+	labels, err := readNames(wordlist)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	dnsClient := dnsinfo.NewClient(timeout, server)
+	formatter := output.NewFormatter(format, cmd.OutOrStdout())
+
+	slog.Info("enumerating subdomains", "zone", zone, "type", recordType, "labels", len(labels), "server", server, "concurrency", concurrency, "rate", rate, "timeout", timeout)
+
+	resp, err := dnsClient.Enumerate(ctx, zone, labels, recordType, concurrency, rate)
+	if err != nil {
+		return err
+	}
+
+	return formatter.OutputDNSEnum(resp)
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDNSEnumCommand_Execute(t *testing.T) {
+	cmd := NewDNSEnumCommand()
+
+	if !strings.HasPrefix(cmd.Use, "enum") {
+		t.Errorf("expected Use to start with 'enum', got %s", cmd.Use)
+	}
+
+	if cmd.Short == "" {
+		t.Error("expected non-empty Short description")
+	}
+
+	if cmd.RunE == nil {
+		t.Error("expected RunE to be set")
+	}
+}
+
+func TestDNSEnumCommand_Flags(t *testing.T) {
+	cmd := NewDNSEnumCommand()
+
+	for _, name := range []string{"wordlist", "type", "concurrency", "rate", "server"} {
+		if cmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected --%s flag to be defined", name)
+		}
+	}
+}
+
+func TestDNSEnumCommand_RequiresWordlist(t *testing.T) {
+	cmd := NewDNSEnumCommand()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+
+	cmd.SetArgs([]string{"example.com"})
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "wordlist") {
+		t.Errorf("expected an error without --wordlist, got %v", err)
+	}
+}
+
+func TestDNSEnumCommand_InvalidRate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(path, []byte("www\n"), 0o644); err != nil {
+		t.Fatalf("failed to write wordlist: %v", err)
+	}
+
+	cmd := NewDNSEnumCommand()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+
+	cmd.SetArgs([]string{"--wordlist", path, "--rate", "-1", "example.com"})
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "--rate") {
+		t.Errorf("expected an invalid rate error, got %v", err)
+	}
+}
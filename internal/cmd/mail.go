@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	dnsinfo "watchr/internal/dns"
+	"watchr/internal/idn"
+	mailinfo "watchr/internal/mail"
+	"watchr/internal/output"
+)
+
+func NewMailCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mail <domain>",
+		Short: "Audit email authentication records",
+		Long: `Audit the email authentication records published by a domain.
+
+Each item is reported as pass, warn or fail:
+  - SPF: the record is parsed and validated, includes and redirects are
+    expanded, and the 10 DNS lookup limit is enforced.
+  - DMARC: the policy, alignment modes and reporting addresses are parsed, and
+    external report destinations are checked for authorization.
+  - DKIM: the keys of the selectors given with --dkim-selector, or of a list of
+    common selectors, are decoded and their type and size checked.
+  - MTA-STS: the policy is fetched over HTTPS and validated, and the domain's
+    MX hosts are checked against it.
+  - TLS-RPT and BIMI: the records are parsed and validated.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runMail,
+	}
+
+	cmd.Flags().StringSlice("dkim-selector", nil, "DKIM selector to check, can be repeated (default: common selectors)")
+	cmd.Flags().StringP("server", "s", "", "DNS server to query (default: system resolver)")
+
+	return cmd
+}
+
+func runMail(cmd *cobra.Command, args []string) error {
+	domain, err := idn.ToASCII(args[0])
+	if err != nil {
+		return err
+	}
+	timeoutSecs, _ := cmd.Flags().GetInt("timeout")
+	timeout := time.Duration(timeoutSecs) * time.Second
+	format, _ := cmd.Flags().GetString("format")
+	selectors, _ := cmd.Flags().GetStringSlice("dkim-selector")
+	server, _ := cmd.Flags().GetString("server")
+
+	ctx := context.Background()
+
+	mailClient := mailinfo.NewClient(timeout, dnsinfo.NewClient(timeout, server))
+	formatter := output.NewFormatter(format, cmd.OutOrStdout())
+
+	slog.Info("auditing email authentication", "domain", domain, "server", server, "timeout", timeout)
+
+	resp, err := mailClient.Audit(ctx, domain, selectors)
+	if err != nil {
+		return err
+	}
+
+	return formatter.OutputMail(resp)
+}
+
+func init() {
+	AddCommand(NewMailCommand())
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMailCommand_Execute(t *testing.T) {
+	cmd := NewMailCommand()
+
+	if !strings.HasPrefix(cmd.Use, "mail") {
+		t.Errorf("expected Use to start with 'mail', got %s", cmd.Use)
+	}
+
+	if cmd.Short == "" {
+		t.Error("expected non-empty Short description")
+	}
+
+	if cmd.RunE == nil {
+		t.Error("expected RunE to be set")
+	}
+}
+
+func TestMailCommand_RequiresArgument(t *testing.T) {
+	cmd := NewMailCommand()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+
+	cmd.SetArgs([]string{})
+
+	err := cmd.Execute()
+	if err == nil {
+		t.Error("expected error when no domain argument provided")
+	}
+}
+
+func TestMailCommand_Flags(t *testing.T) {
+	cmd := NewMailCommand()
+
+	if cmd.Flags().Lookup("dkim-selector") == nil {
+		t.Fatal("expected --dkim-selector flag to be defined")
+	}
+
+	if cmd.Flags().Lookup("server") == nil {
+		t.Fatal("expected --server flag to be defined")
+	}
+}
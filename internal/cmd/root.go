@@ -32,7 +32,7 @@ func Execute() error {
 }
 
 func init() {
-	rootCmd.PersistentFlags().StringP("format", "f", "text", "Output format (text|json)")
+	rootCmd.PersistentFlags().StringP("format", "f", "text", "Output format (text|json, or ndjson for dns enum)")
 	rootCmd.PersistentFlags().IntP("timeout", "t", 10, "Request timeout in seconds")
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Enable verbose logging")
 }
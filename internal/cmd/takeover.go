@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	dnsinfo "watchr/internal/dns"
+	"watchr/internal/idn"
+	"watchr/internal/output"
+	"watchr/internal/takeover"
+)
+
+func NewTakeoverCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "takeover [name...]",
+		Short: "Detect subdomains that can be taken over",
+		Long: `Detect subdomains pointing at unclaimed third-party services.
+
+The CNAME chain of every name is resolved and each name is reported as:
+  - vulnerable: the chain points at a known service and either its target does
+    not exist (NXDOMAIN) and can be registered, or the service answers with its
+    "not found" page for the name.
+  - dangling: the CNAME target does not exist, but the service is not known.
+  - ok: nothing suggests the name can be taken over.
+
+Names are read from the arguments and from --file, one per line. The built-in
+service fingerprints can be replaced with a JSON file given with --fingerprints.`,
+		Args: cobra.ArbitraryArgs,
+		RunE: runTakeover,
+	}
+
+	cmd.Flags().String("file", "", "file with names to check, one per line")
+	cmd.Flags().String("fingerprints", "", "JSON file replacing the built-in service fingerprints")
+	cmd.Flags().Int("concurrency", 10, "number of names checked at the same time")
+	cmd.Flags().StringP("server", "s", "", "DNS server to query (default: system resolver)")
+
+	return cmd
+}
+
+func runTakeover(cmd *cobra.Command, args []string) error {
+	timeoutSecs, _ := cmd.Flags().GetInt("timeout")
+	timeout := time.Duration(timeoutSecs) * time.Second
+	format, _ := cmd.Flags().GetString("format")
+	file, _ := cmd.Flags().GetString("file")
+	fingerprintsFile, _ := cmd.Flags().GetString("fingerprints")
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	server, _ := cmd.Flags().GetString("server")
+
+	names := args
+	if file != "" {
+		fileNames, err := readNames(file)
+		if err != nil {
+			return err
+		}
+		names = append(names, fileNames...)
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("no names to check: pass them as arguments or with --file")
+	}
+	for i, name := range names {
+		ascii, err := idn.ToASCII(name)
+		if err != nil {
+			return err
+		}
+		names[i] = ascii
+	}
+
+	var fingerprints []takeover.Fingerprint
+	if fingerprintsFile != "" {
+		var err error
+		fingerprints, err = takeover.LoadFingerprints(fingerprintsFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx := context.Background()
+
+	takeoverClient := takeover.NewClient(timeout, dnsinfo.NewClient(timeout, server), fingerprints)
+	formatter := output.NewFormatter(format, cmd.OutOrStdout())
+
+	slog.Info("checking for subdomain takeovers", "names", len(names), "server", server, "concurrency", concurrency, "timeout", timeout)
+
+	resp, err := takeoverClient.Check(ctx, names, concurrency)
+	if err != nil {
+		return err
+	}
+
+	return formatter.OutputTakeover(resp)
+}
+
+// readNames reads one name per line from path, skipping blank lines and
+// comments starting with #.
+func readNames(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	var names []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			names = append(names, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return names, nil
+}
+
+func init() {
+	AddCommand(NewTakeoverCommand())
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTakeoverCommand_Execute(t *testing.T) {
+	cmd := NewTakeoverCommand()
+
+	if !strings.HasPrefix(cmd.Use, "takeover") {
+		t.Errorf("expected Use to start with 'takeover', got %s", cmd.Use)
+	}
+
+	if cmd.Short == "" {
+		t.Error("expected non-empty Short description")
+	}
+
+	if cmd.RunE == nil {
+		t.Error("expected RunE to be set")
+	}
+}
+
+func TestTakeoverCommand_RequiresNames(t *testing.T) {
+	cmd := NewTakeoverCommand()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+
+	cmd.SetArgs([]string{})
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "no names") {
+		t.Errorf("expected error when no names are given, got %v", err)
+	}
+}
+
+func TestTakeoverCommand_Flags(t *testing.T) {
+	cmd := NewTakeoverCommand()
+
+	for _, name := range []string{"file", "fingerprints", "concurrency", "server"} {
+		if cmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected --%s flag to be defined", name)
+		}
+	}
+}
+
+func TestTakeoverCommand_InvalidFingerprints(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fingerprints.json")
+	if err := os.WriteFile(path, []byte(`[{"service": "Example"}]`), 0o644); err != nil {
+		t.Fatalf("failed to write fingerprints: %v", err)
+	}
+
+	cmd := NewTakeoverCommand()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+
+	cmd.SetArgs([]string{"--fingerprints", path, "www.example.com"})
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "no CNAME patterns") {
+		t.Errorf("expected an invalid fingerprints error, got %v", err)
+	}
+}
+
+func TestReadNames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "names.txt")
+	contents := "# marketing\nwww.example.com\n\n  promo.example.com  # spring campaign\n"
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("failed to write names: %v", err)
+	}
+
+	names, err := readNames(path)
+	if err != nil {
+		t.Fatalf("readNames failed: %v", err)
+	}
+
+	expected := []string{"www.example.com", "promo.example.com"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("readNames = %v, want %v", names, expected)
+	}
+}
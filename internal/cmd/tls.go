@@ -7,6 +7,10 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"watchr/internal/caa"
+	"watchr/internal/dane"
+	dnsinfo "watchr/internal/dns"
+	"watchr/internal/idn"
 	"watchr/internal/output"
 	tlsinfo "watchr/internal/tls"
 )
@@ -23,7 +27,20 @@ chain, showing details such as subject, issuer, validity dates, and more.
 Use --scan-protocols to test which TLS versions are supported.
 Use --scan-ciphers to enumerate supported cipher suites for each TLS version.
 Use --full-scan to perform a comprehensive security scan including protocol
-versions, cipher suites, and vulnerability detection.`,
+versions, cipher suites, and vulnerability detection.
+
+Use --caa to check that the CAA records of every name in the served certificate
+authorize the CA that issued it, climbing the tree to find the relevant CAA set
+as CAs do. A denied name means renewing the certificate with the same CA will
+fail. The CA is recognized from the certificate issuer; use --ca-domain to give
+its CAA issuer domain when it is not known.
+
+Use --dane to verify the served chain against the TLSA records of the service
+(_port._tcp.host). The TLSA records are validated with DNSSEC from the root
+trust anchors, and each one is matched according to its certificate usage,
+selector and matching type; the record that matched, or the reason none did,
+is reported. Use --starttls smtp to upgrade SMTP connections (e.g. --port 25
+for inbound mail servers) before the handshake.`,
 		Args: cobra.ExactArgs(1),
 		RunE: runTLS,
 	}
@@ -32,12 +49,20 @@ versions, cipher suites, and vulnerability detection.`,
 	cmd.Flags().Bool("scan-protocols", false, "Scan for supported TLS protocol versions")
 	cmd.Flags().Bool("scan-ciphers", false, "Enumerate supported cipher suites (implies --scan-protocols)")
 	cmd.Flags().Bool("full-scan", false, "Perform full security scan (protocols, ciphers, vulnerabilities)")
+	cmd.Flags().Bool("caa", false, "Check that the CAA records authorize the CA that issued the certificate")
+	cmd.Flags().StringSlice("ca-domain", nil, "CAA issuer domain of the issuing CA, can be repeated (default: recognized from the issuer)")
+	cmd.Flags().Bool("dane", false, "Verify the certificate chain against the DNSSEC-validated TLSA records")
+	cmd.Flags().String("starttls", "", "Upgrade the connection with STARTTLS before the handshake (smtp)")
+	cmd.Flags().StringP("server", "s", "", "DNS server to query for CAA and TLSA records (default: system resolver)")
 
 	return cmd
 }
 
 func runTLS(cmd *cobra.Command, args []string) error {
-	host := args[0]
+	host, err := idn.ToASCII(args[0])
+	if err != nil {
+		return err
+	}
 	timeoutSecs, _ := cmd.Flags().GetInt("timeout")
 	timeout := time.Duration(timeoutSecs) * time.Second
 	format, _ := cmd.Flags().GetString("format")
@@ -45,6 +70,11 @@ func runTLS(cmd *cobra.Command, args []string) error {
 	fullScan, _ := cmd.Flags().GetBool("full-scan")
 	scanCiphers, _ := cmd.Flags().GetBool("scan-ciphers")
 	scanProtocols, _ := cmd.Flags().GetBool("scan-protocols")
+	checkCAA, _ := cmd.Flags().GetBool("caa")
+	caDomains, _ := cmd.Flags().GetStringSlice("ca-domain")
+	checkDANE, _ := cmd.Flags().GetBool("dane")
+	starttls, _ := cmd.Flags().GetString("starttls")
+	server, _ := cmd.Flags().GetString("server")
 
 	ctx := context.Background()
 	formatter := output.NewFormatter(format, cmd.OutOrStdout())
@@ -53,7 +83,33 @@ func runTLS(cmd *cobra.Command, args []string) error {
 		return runTLSScan(ctx, host, port, timeout, formatter, fullScan, scanCiphers)
 	}
 
-	tlsClient := tlsinfo.NewClient(timeout)
+	if checkCAA {
+		caaClient := caa.NewClient(timeout, dnsinfo.NewClient(timeout, server))
+
+		slog.Info("checking CAA records", "host", host, "port", port, "server", server, "timeout", timeout)
+
+		resp, err := caaClient.Check(ctx, host, port, caDomains)
+		if err != nil {
+			return err
+		}
+
+		return formatter.OutputCAA(resp)
+	}
+
+	tlsClient := tlsinfo.NewClient(timeout, tlsinfo.WithSTARTTLS(starttls))
+
+	if checkDANE {
+		daneClient := dane.NewClient(timeout, dnsinfo.NewClient(timeout, server), tlsClient)
+
+		slog.Info("verifying DANE", "host", host, "port", port, "starttls", starttls, "server", server, "timeout", timeout)
+
+		resp, err := daneClient.Verify(ctx, host, port)
+		if err != nil {
+			return err
+		}
+
+		return formatter.OutputDANE(resp)
+	}
 
 	slog.Info("retrieving TLS certificate", "host", host, "port", port, "timeout", timeout)
 
@@ -154,3 +154,23 @@ func TestTLSCommand_WithFullScan(t *testing.T) {
 		t.Error("expected output to contain 'Preferred Version'")
 	}
 }
+
+func TestTLSCommand_CAAFlags(t *testing.T) {
+	cmd := NewTLSCommand()
+
+	for _, name := range []string{"caa", "ca-domain", "server"} {
+		if cmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected --%s flag to be defined", name)
+		}
+	}
+}
+
+func TestTLSCommand_DANEFlags(t *testing.T) {
+	cmd := NewTLSCommand()
+
+	for _, name := range []string{"dane", "starttls"} {
+		if cmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected --%s flag to be defined", name)
+		}
+	}
+}
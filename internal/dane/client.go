@@ -0,0 +1,342 @@
+package dane
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	dnsinfo "watchr/internal/dns"
+	"watchr/internal/idn"
+	tlsinfo "watchr/internal/tls"
+)
+
+// TLSA certificate usages (RFC 7218).
+const (
+	UsagePKIXTA = 0
+	UsagePKIXEE = 1
+	UsageDANETA = 2
+	UsageDANEEE = 3
+)
+
+type Client struct {
+	timeout   time.Duration
+	dnsClient *dnsinfo.Client
+	tlsClient *tlsinfo.Client
+	// roots are the trust anchors of PKIX usages, or the system roots when
+	// nil.
+	roots *x509.CertPool
+	// lookupTLSA returns the DNSSEC-validated TLSA records of name.
+	lookupTLSA func(ctx context.Context, name string) (*dnsinfo.DNSSECResponse, error)
+}
+
+// NewClient returns a client fetching certificates with tlsClient and TLSA
+// records with dnsClient, validating them from the root trust anchors.
+func NewClient(timeout time.Duration, dnsClient *dnsinfo.Client, tlsClient *tlsinfo.Client) *Client {
+	return &Client{
+		timeout:   timeout,
+		dnsClient: dnsClient,
+		tlsClient: tlsClient,
+		lookupTLSA: func(ctx context.Context, name string) (*dnsinfo.DNSSECResponse, error) {
+			return dnsClient.ValidateDNSSEC(ctx, name, "TLSA", nil)
+		},
+	}
+}
+
+// Verify fetches the TLSA records of the service at host and port
+// (_port._tcp.host), validating them with DNSSEC, and matches every record
+// against the certificate chain the server presents (RFC 6698 and RFC 7671).
+func (c *Client) Verify(ctx context.Context, host, port string) (*Response, error) {
+	start := time.Now()
+
+	hostName, err := idn.Parse(host)
+	if err != nil {
+		return nil, err
+	}
+	host = hostName.ASCII
+	name := "_" + port + "._tcp." + strings.TrimSuffix(host, ".") + "."
+
+	slog.Debug("verifying DANE", "host", host, "port", port, "tlsa", name)
+
+	tlsa, err := c.lookupTLSA(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("TLSA lookup of %s failed: %w", name, err)
+	}
+
+	tlsResp, err := c.tlsClient.Fetch(ctx, host, port)
+	if err != nil {
+		return nil, err
+	}
+
+	chain := make([]*x509.Certificate, 0, len(tlsResp.Certificates))
+	for _, cert := range tlsResp.Certificates {
+		parsed, err := x509.ParseCertificate(cert.Raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the served certificate: %w", err)
+		}
+		chain = append(chain, parsed)
+	}
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("%s did not send a certificate", net.JoinHostPort(host, port))
+	}
+
+	response := &Response{
+		Host:     host,
+		IDN:      hostName,
+		Port:     port,
+		TLSAName: name,
+		DNSSEC:   tlsa.Status,
+		Chain:    make([]ChainCert, 0, len(chain)),
+		Records:  make([]RecordResult, 0),
+	}
+
+	for _, link := range tlsa.Links {
+		if link.Status != dnsinfo.DNSSECSecure {
+			response.DNSSECReason = link.Reason
+			break
+		}
+	}
+
+	for _, cert := range chain {
+		certSum := sha256.Sum256(cert.Raw)
+		spkiSum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+		response.Chain = append(response.Chain, ChainCert{
+			Subject:    cert.Subject.String(),
+			Issuer:     cert.Issuer.String(),
+			SHA256:     hex.EncodeToString(certSum[:]),
+			SPKISHA256: hex.EncodeToString(spkiSum[:]),
+		})
+	}
+
+	matched := false
+	for _, record := range tlsa.Records {
+		data, ok := record.Data.(*dnsinfo.TLSAData)
+		if !ok {
+			continue
+		}
+
+		result := c.match(host, chain, data)
+		matched = matched || result.Matched
+		response.Records = append(response.Records, result)
+	}
+
+	switch {
+	case len(response.Records) == 0:
+		response.Status = StatusNoRecords
+	case tlsa.Status != dnsinfo.DNSSECSecure:
+		response.Status = StatusUnusable
+	case matched:
+		response.Status = StatusMatched
+	default:
+		response.Status = StatusNoMatch
+	}
+	response.QueryTime = time.Since(start)
+
+	return response, nil
+}
+
+// match checks a TLSA record against the chain presented by the server.
+func (c *Client) match(host string, chain []*x509.Certificate, data *dnsinfo.TLSAData) RecordResult {
+	result := RecordResult{
+		Usage:        data.Usage,
+		Selector:     data.Selector,
+		MatchingType: data.MatchingType,
+		Data:         strings.ToLower(data.Certificate),
+		Description:  describe(data),
+		Certificate:  -1,
+	}
+
+	if data.Usage > UsageDANEEE {
+		result.Reason = fmt.Sprintf("unsupported certificate usage %d", data.Usage)
+		return result
+	}
+	if data.Selector > 1 {
+		result.Reason = fmt.Sprintf("unsupported selector %d", data.Selector)
+		return result
+	}
+	if data.MatchingType > 2 {
+		result.Reason = fmt.Sprintf("unsupported matching type %d", data.MatchingType)
+		return result
+	}
+
+	candidates := chain
+	switch data.Usage {
+	case UsagePKIXEE, UsageDANEEE:
+		candidates = chain[:1]
+	case UsagePKIXTA:
+		// The trust anchor is often not sent by the server, so the chains
+		// built from the trusted roots are searched too.
+		verified, err := c.verifyPKIX(host, chain, nil)
+		if err != nil {
+			result.Reason = fmt.Sprintf("the chain does not pass PKIX validation: %v", err)
+			return result
+		}
+		candidates = verified
+	}
+
+	index := -1
+	for i, cert := range candidates {
+		if association(cert, data.Selector, data.MatchingType) == result.Data {
+			index = i
+			break
+		}
+	}
+	if index < 0 && data.Usage == UsageDANETA {
+		return c.matchRecordAnchor(host, chain, result)
+	}
+	if index < 0 {
+		result.Reason = fmt.Sprintf("no certificate in the chain matches (%s)", describeTarget(data.Usage))
+		return result
+	}
+
+	switch data.Usage {
+	case UsagePKIXEE:
+		if _, err := c.verifyPKIX(host, chain, nil); err != nil {
+			result.Reason = fmt.Sprintf("the end-entity certificate matches but does not pass PKIX validation: %v", err)
+			return result
+		}
+	case UsageDANETA:
+		roots := x509.NewCertPool()
+		roots.AddCert(candidates[index])
+		if _, err := c.verifyPKIX(host, chain, roots); err != nil {
+			result.Reason = fmt.Sprintf("certificate %d matches but the end-entity certificate does not chain to it: %v", index, err)
+			return result
+		}
+	}
+
+	result.Matched = true
+	result.Certificate = index
+	if data.Usage == UsagePKIXTA {
+		result.Certificate = -1
+		for i, cert := range chain {
+			if cert.Equal(candidates[index]) {
+				result.Certificate = i
+			}
+		}
+	}
+	result.Reason = fmt.Sprintf("matches %s", candidates[index].Subject.String())
+
+	return result
+}
+
+// matchRecordAnchor handles a DANE-TA record matching none of the certificates
+// sent by the server. A record holding a full certificate is itself the trust
+// anchor (RFC 7671 section 5.2.2), so the chain is verified against it.
+func (c *Client) matchRecordAnchor(host string, chain []*x509.Certificate, result RecordResult) RecordResult {
+	if result.Selector != 0 || result.MatchingType != 0 {
+		result.Reason = fmt.Sprintf("no certificate in the chain matches (%s)", describeTarget(result.Usage))
+		return result
+	}
+
+	der, err := hex.DecodeString(result.Data)
+	if err != nil {
+		result.Reason = fmt.Sprintf("no certificate in the chain matches and the record data is not valid hex: %v", err)
+		return result
+	}
+	anchor, err := x509.ParseCertificate(der)
+	if err != nil {
+		result.Reason = fmt.Sprintf("no certificate in the chain matches and the record data is not a certificate: %v", err)
+		return result
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(anchor)
+	if _, err := c.verifyPKIX(host, chain, roots); err != nil {
+		result.Reason = fmt.Sprintf("the end-entity certificate does not chain to the trust anchor in the record: %v", err)
+		return result
+	}
+
+	result.Matched = true
+	result.Reason = fmt.Sprintf("matches the trust anchor in the record, %s", anchor.Subject.String())
+	return result
+}
+
+// verifyPKIX validates the chain for host against roots, or the client's
+// roots when nil, and returns the CA certificates of the verified chains,
+// leaving out the end-entity certificate each of them starts with.
+func (c *Client) verifyPKIX(host string, chain []*x509.Certificate, roots *x509.CertPool) ([]*x509.Certificate, error) {
+	if roots == nil {
+		roots = c.roots
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+	}
+	if net.ParseIP(host) == nil {
+		opts.DNSName = strings.TrimSuffix(host, ".")
+	}
+
+	chains, err := chain[0].Verify(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	certs := make([]*x509.Certificate, 0)
+	for _, verified := range chains {
+		certs = append(certs, verified[1:]...)
+	}
+	return certs, nil
+}
+
+// association returns the hex encoded data a TLSA record with the given
+// selector and matching type holds for cert.
+func association(cert *x509.Certificate, selector, matchingType uint8) string {
+	data := cert.Raw
+	if selector == 1 {
+		data = cert.RawSubjectPublicKeyInfo
+	}
+
+	switch matchingType {
+	case 1:
+		sum := sha256.Sum256(data)
+		return hex.EncodeToString(sum[:])
+	case 2:
+		sum := sha512.Sum512(data)
+		return hex.EncodeToString(sum[:])
+	default:
+		return hex.EncodeToString(data)
+	}
+}
+
+// describe returns the RFC 7218 mnemonics of the record's parameters.
+func describe(data *dnsinfo.TLSAData) string {
+	usages := []string{"PKIX-TA", "PKIX-EE", "DANE-TA", "DANE-EE"}
+	selectors := []string{"Cert", "SPKI"}
+	matchingTypes := []string{"Full", "SHA2-256", "SHA2-512"}
+
+	name := func(names []string, value uint8) string {
+		if int(value) < len(names) {
+			return names[value]
+		}
+		return fmt.Sprintf("%d", value)
+	}
+
+	return fmt.Sprintf("%s(%d) %s(%d) %s(%d)",
+		name(usages, data.Usage), data.Usage,
+		name(selectors, data.Selector), data.Selector,
+		name(matchingTypes, data.MatchingType), data.MatchingType,
+	)
+}
+
+func describeTarget(usage uint8) string {
+	switch usage {
+	case UsagePKIXEE, UsageDANEEE:
+		return "the end-entity certificate was compared"
+	case UsagePKIXTA:
+		return "the CA certificates of the verified chains were compared"
+	default:
+		return "every certificate sent by the server was compared"
+	}
+}
@@ -0,0 +1,208 @@
+package dane
+
+import (
+	"context"
+	"crypto/x509"
+	"strings"
+	"testing"
+
+	dnsinfo "watchr/internal/dns"
+)
+
+func TestClient_Verify_DANEEE(t *testing.T) {
+	port, chain := startTLSServer(t, false)
+	client := newTestClient(dnsinfo.DNSSECSecure,
+		&dnsinfo.TLSAData{Usage: 3, Selector: 1, MatchingType: 1, Certificate: "00ff"},
+		&dnsinfo.TLSAData{Usage: 3, Selector: 1, MatchingType: 1, Certificate: strings.ToUpper(association(chain.leaf, 1, 1))},
+	)
+
+	resp, err := client.Verify(context.Background(), "127.0.0.1", port)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	if resp.Status != StatusMatched {
+		t.Fatalf("expected %s, got %s: %+v", StatusMatched, resp.Status, resp.Records)
+	}
+	if resp.TLSAName != "_"+port+"._tcp.127.0.0.1." {
+		t.Errorf("unexpected TLSA name: %s", resp.TLSAName)
+	}
+
+	if resp.Records[0].Matched || !strings.Contains(resp.Records[0].Reason, "no certificate in the chain matches") {
+		t.Errorf("expected the first record not to match, got %+v", resp.Records[0])
+	}
+
+	record := resp.Records[1]
+	if !record.Matched || record.Certificate != 0 {
+		t.Errorf("expected the second record to match the leaf, got %+v", record)
+	}
+	if record.Description != "DANE-EE(3) SPKI(1) SHA2-256(1)" {
+		t.Errorf("unexpected description: %s", record.Description)
+	}
+
+	if len(resp.Chain) != 1 || resp.Chain[0].SPKISHA256 != association(chain.leaf, 1, 1) {
+		t.Errorf("unexpected chain: %+v", resp.Chain)
+	}
+}
+
+func TestClient_Verify_DANETA(t *testing.T) {
+	port, chain := startTLSServer(t, true)
+	client := newTestClient(dnsinfo.DNSSECSecure,
+		&dnsinfo.TLSAData{Usage: 2, Selector: 0, MatchingType: 2, Certificate: association(chain.ca, 0, 2)},
+	)
+
+	resp, err := client.Verify(context.Background(), "127.0.0.1", port)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	if resp.Status != StatusMatched || resp.Records[0].Certificate != 1 {
+		t.Errorf("expected the CA sent by the server to match, got %+v", resp.Records)
+	}
+}
+
+func TestClient_Verify_DANETA_RecordAnchor(t *testing.T) {
+	port, chain := startTLSServer(t, false)
+	client := newTestClient(dnsinfo.DNSSECSecure,
+		&dnsinfo.TLSAData{Usage: 2, Selector: 0, MatchingType: 0, Certificate: association(chain.ca, 0, 0)},
+	)
+
+	resp, err := client.Verify(context.Background(), "127.0.0.1", port)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	// The CA is not sent by the server, but the record holds it in full.
+	if resp.Status != StatusMatched || resp.Records[0].Certificate != -1 {
+		t.Errorf("expected the trust anchor in the record to match, got %+v", resp.Records)
+	}
+
+	// A digest of a trust anchor that is not sent cannot be used.
+	client = newTestClient(dnsinfo.DNSSECSecure,
+		&dnsinfo.TLSAData{Usage: 2, Selector: 0, MatchingType: 1, Certificate: association(chain.ca, 0, 1)},
+	)
+	resp, err = client.Verify(context.Background(), "127.0.0.1", port)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	if resp.Status != StatusNoMatch {
+		t.Errorf("expected %s, got %s", StatusNoMatch, resp.Status)
+	}
+}
+
+func TestClient_Verify_PKIXTA_Leaf(t *testing.T) {
+	port, chain := startTLSServer(t, true)
+	client := newTestClient(dnsinfo.DNSSECSecure,
+		&dnsinfo.TLSAData{Usage: 0, Selector: 1, MatchingType: 1, Certificate: association(chain.leaf, 1, 1)},
+	)
+	client.roots = x509.NewCertPool()
+	client.roots.AddCert(chain.ca)
+
+	resp, err := client.Verify(context.Background(), "127.0.0.1", port)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	// PKIX-TA requires a CA certificate, the end-entity one never matches.
+	if resp.Status != StatusNoMatch || resp.Records[0].Matched {
+		t.Errorf("expected PKIX-TA not to match the leaf, got %+v", resp.Records)
+	}
+}
+
+func TestClient_Verify_PKIX(t *testing.T) {
+	port, chain := startTLSServer(t, false)
+
+	client := newTestClient(dnsinfo.DNSSECSecure,
+		&dnsinfo.TLSAData{Usage: 0, Selector: 1, MatchingType: 1, Certificate: association(chain.ca, 1, 1)},
+		&dnsinfo.TLSAData{Usage: 1, Selector: 0, MatchingType: 0, Certificate: association(chain.leaf, 0, 0)},
+	)
+	client.roots = x509.NewCertPool()
+	client.roots.AddCert(chain.ca)
+
+	resp, err := client.Verify(context.Background(), "127.0.0.1", port)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	// The trust anchor is not sent by the server, but found in the verified
+	// chain.
+	if !resp.Records[0].Matched || resp.Records[0].Certificate != -1 {
+		t.Errorf("expected PKIX-TA to match the trusted root, got %+v", resp.Records[0])
+	}
+	if !resp.Records[1].Matched || resp.Records[1].Certificate != 0 {
+		t.Errorf("expected PKIX-EE to match the leaf, got %+v", resp.Records[1])
+	}
+
+	// Without trusting the CA, PKIX validation fails.
+	client.roots = x509.NewCertPool()
+	resp, err = client.Verify(context.Background(), "127.0.0.1", port)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	if resp.Status != StatusNoMatch {
+		t.Errorf("expected %s, got %s", StatusNoMatch, resp.Status)
+	}
+	for _, record := range resp.Records {
+		if record.Matched || !strings.Contains(record.Reason, "PKIX validation") {
+			t.Errorf("expected PKIX validation to fail, got %+v", record)
+		}
+	}
+}
+
+func TestClient_Verify_Insecure(t *testing.T) {
+	port, chain := startTLSServer(t, false)
+	client := newTestClient(dnsinfo.DNSSECInsecure,
+		&dnsinfo.TLSAData{Usage: 3, Selector: 1, MatchingType: 1, Certificate: association(chain.leaf, 1, 1)},
+	)
+
+	resp, err := client.Verify(context.Background(), "127.0.0.1", port)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	if resp.Status != StatusUnusable || resp.DNSSEC != dnsinfo.DNSSECInsecure {
+		t.Errorf("expected insecure TLSA records to be unusable, got %s (%s)", resp.Status, resp.DNSSEC)
+	}
+	if resp.DNSSECReason == "" {
+		t.Error("expected the reason the records are not secure")
+	}
+	if !resp.Records[0].Matched {
+		t.Errorf("expected the record to still be matched, got %+v", resp.Records[0])
+	}
+}
+
+func TestClient_Verify_NoRecords(t *testing.T) {
+	port, _ := startTLSServer(t, false)
+	client := newTestClient(dnsinfo.DNSSECSecure)
+
+	resp, err := client.Verify(context.Background(), "127.0.0.1", port)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	if resp.Status != StatusNoRecords {
+		t.Errorf("expected %s, got %s", StatusNoRecords, resp.Status)
+	}
+}
+
+func TestClient_Verify_Unsupported(t *testing.T) {
+	port, _ := startTLSServer(t, false)
+	client := newTestClient(dnsinfo.DNSSECSecure,
+		&dnsinfo.TLSAData{Usage: 4, Selector: 1, MatchingType: 1, Certificate: "00"},
+		&dnsinfo.TLSAData{Usage: 3, Selector: 2, MatchingType: 1, Certificate: "00"},
+		&dnsinfo.TLSAData{Usage: 3, Selector: 1, MatchingType: 9, Certificate: "00"},
+	)
+
+	resp, err := client.Verify(context.Background(), "127.0.0.1", port)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	for i, expected := range []string{"certificate usage 4", "selector 2", "matching type 9"} {
+		if !strings.Contains(resp.Records[i].Reason, "unsupported "+expected) {
+			t.Errorf("record %d: expected an unsupported %s, got %s", i, expected, resp.Records[i].Reason)
+		}
+	}
+}
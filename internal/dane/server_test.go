@@ -0,0 +1,125 @@
+package dane
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	dnsinfo "watchr/internal/dns"
+	tlsinfo "watchr/internal/tls"
+)
+
+// testChain is a CA and a leaf certificate it issued.
+type testChain struct {
+	ca   *x509.Certificate
+	leaf *x509.Certificate
+}
+
+// startTLSServer runs a local TLS server presenting a leaf certificate issued
+// by a test CA, followed by the CA when sendCA is set, and returns its port.
+func startTLSServer(t *testing.T, sendCA bool) (string, testChain) {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to create CA certificate: %v", err)
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatalf("failed to parse CA certificate: %v", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "mx.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{"mx.example.com"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+
+	served := [][]byte{der}
+	if sendCA {
+		served = append(served, caDER)
+	}
+
+	l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: served, PrivateKey: key}},
+	})
+	if err != nil {
+		t.Fatalf("failed to listen on TLS: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = l.Close()
+	})
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.(*tls.Conn).Handshake()
+			_ = conn.Close()
+		}
+	}()
+
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	return port, testChain{ca: ca, leaf: leaf}
+}
+
+// newTestClient returns a client whose TLSA lookups return the given records
+// with the given DNSSEC status.
+func newTestClient(status string, records ...*dnsinfo.TLSAData) *Client {
+	client := NewClient(5*time.Second, dnsinfo.NewClient(5*time.Second, "127.0.0.1:53"), tlsinfo.NewClient(5*time.Second))
+	client.lookupTLSA = func(ctx context.Context, name string) (*dnsinfo.DNSSECResponse, error) {
+		resp := &dnsinfo.DNSSECResponse{
+			Domain:     name,
+			RecordType: "TLSA",
+			Status:     status,
+			Records:    make([]dnsinfo.Record, 0, len(records)),
+		}
+		if status != dnsinfo.DNSSECSecure {
+			resp.Links = []dnsinfo.DNSSECLink{{Zone: "example.com.", Status: status, Reason: "no DS records for example.com."}}
+		}
+		for _, record := range records {
+			resp.Records = append(resp.Records, dnsinfo.Record{Name: name, Type: "TLSA", Data: record})
+		}
+		return resp, nil
+	}
+	return client
+}
@@ -0,0 +1,55 @@
+package dane
+
+import (
+	"time"
+
+	"watchr/internal/idn"
+)
+
+// Verification statuses.
+const (
+	StatusMatched   = "matched"
+	StatusNoMatch   = "no-match"
+	StatusUnusable  = "unusable"
+	StatusNoRecords = "no-records"
+)
+
+type Response struct {
+	Host      string        `json:"host"`
+	IDN       *idn.Name     `json:"idn,omitempty"`
+	Port      string        `json:"port"`
+	TLSAName  string        `json:"tlsaName"`
+	QueryTime time.Duration `json:"queryTime"`
+	// DNSSEC is the DNSSEC status of the TLSA records; DANE only applies when
+	// they are secure.
+	DNSSEC       string `json:"dnssec"`
+	DNSSECReason string `json:"dnssecReason,omitempty"`
+	// Status is StatusMatched when a secure TLSA record matches the chain,
+	// StatusNoMatch when none does, StatusUnusable when the TLSA records are
+	// not DNSSEC-secure, and StatusNoRecords when there are none.
+	Status  string         `json:"status"`
+	Chain   []ChainCert    `json:"chain"`
+	Records []RecordResult `json:"records"`
+}
+
+type ChainCert struct {
+	Subject string `json:"subject"`
+	Issuer  string `json:"issuer"`
+	// SHA256 and SPKISHA256 are the digests a TLSA record with matching type
+	// 1 would hold for the certificate and for its public key.
+	SHA256     string `json:"sha256"`
+	SPKISHA256 string `json:"spkiSha256"`
+}
+
+// RecordResult is the outcome of matching one TLSA record. Certificate is the
+// index in the chain of the certificate it matched, or -1.
+type RecordResult struct {
+	Usage        uint8  `json:"usage"`
+	Selector     uint8  `json:"selector"`
+	MatchingType uint8  `json:"matchingType"`
+	Data         string `json:"data"`
+	Description  string `json:"description"`
+	Matched      bool   `json:"matched"`
+	Certificate  int    `json:"certificate"`
+	Reason       string `json:"reason"`
+}
@@ -0,0 +1,284 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	mdns "github.com/miekg/dns"
+
+	"watchr/internal/idn"
+)
+
+// Nameserver audit statuses.
+const (
+	AuditOK          = "ok"
+	AuditOutOfSync   = "out-of-sync"
+	AuditLame        = "lame"
+	AuditUnreachable = "unreachable"
+)
+
+// AuditNameservers resolves the NS set of zone through the configured
+// resolver and queries every authoritative server directly, over IPv4 and
+// IPv6 and without recursion, for the zone's SOA and NS records and for the
+// recordType records of the zone apex. Servers that do not answer are
+// reported as unreachable, servers that answer without authority as lame, and
+// servers whose SOA serial, NS set or records differ from the others as out of
+// sync.
+func (c *Client) AuditNameservers(ctx context.Context, zone string, recordType string) (*NSAuditResponse, error) {
+	qtype, err := parseRecordType(recordType)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := idn.Parse(mdns.Fqdn(zone))
+	if err != nil {
+		return nil, err
+	}
+	zone = name.ASCII
+
+	slog.Debug("auditing authoritative nameservers", "zone", zone, "type", recordType)
+	start := time.Now()
+
+	nsResp, err := c.Query(ctx, zone, "NS")
+	if err != nil {
+		return nil, err
+	}
+
+	response := &NSAuditResponse{
+		Zone:        zone,
+		IDN:         name,
+		RecordType:  strings.ToUpper(recordType),
+		Nameserver:  c.nameserver,
+		Nameservers: make([]string, 0),
+		Servers:     make([]NSAuditServer, 0),
+	}
+
+	for _, record := range nsResp.Records {
+		if record.Type == "NS" {
+			response.Nameservers = append(response.Nameservers, mdns.CanonicalName(record.Value))
+		}
+	}
+	sort.Strings(response.Nameservers)
+
+	if len(response.Nameservers) == 0 {
+		return nil, fmt.Errorf("no NS records found for %s (%s)", zone, nsResp.Rcode)
+	}
+
+	for _, name := range response.Nameservers {
+		response.Servers = append(response.Servers, c.nameserverAddresses(ctx, name)...)
+	}
+
+	var wg sync.WaitGroup
+	for i := range response.Servers {
+		if response.Servers[i].Error != "" {
+			continue
+		}
+
+		wg.Add(1)
+		go func(server *NSAuditServer) {
+			defer wg.Done()
+			c.auditServer(ctx, server, zone, qtype)
+		}(&response.Servers[i])
+	}
+	wg.Wait()
+
+	compareAuditServers(response)
+	response.QueryTime = time.Since(start)
+
+	return response, nil
+}
+
+// nameserverAddresses resolves the IPv4 and IPv6 addresses of a nameserver.
+// When it has none, a single unreachable entry is returned for it.
+func (c *Client) nameserverAddresses(ctx context.Context, name string) []NSAuditServer {
+	servers := make([]NSAuditServer, 0)
+	failures := make([]string, 0)
+
+	for _, family := range []string{"A", "AAAA"} {
+		resp, err := c.Query(ctx, name, family)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s lookup failed: %v", family, err))
+			continue
+		}
+
+		for _, record := range resp.Records {
+			if record.Type != family {
+				continue
+			}
+			servers = append(servers, NSAuditServer{
+				Nameserver: name,
+				Address:    net.JoinHostPort(record.Value, c.port),
+				Family:     addressFamily(family),
+			})
+		}
+	}
+
+	if len(servers) == 0 {
+		reason := "no A or AAAA records found"
+		if len(failures) > 0 {
+			reason = strings.Join(failures, "; ")
+		}
+		servers = append(servers, NSAuditServer{
+			Nameserver: name,
+			Status:     AuditUnreachable,
+			Error:      reason,
+		})
+	}
+
+	return servers
+}
+
+func addressFamily(recordType string) string {
+	if recordType == "AAAA" {
+		return "IPv6"
+	}
+	return "IPv4"
+}
+
+// auditServer queries a single authoritative server and fills in its SOA
+// serial, NS set and records. The status is only set here for servers that
+// are unreachable or lame; the others are compared afterwards.
+func (c *Client) auditServer(ctx context.Context, server *NSAuditServer, zone string, qtype uint16) {
+	start := time.Now()
+	defer func() {
+		server.QueryTime = time.Since(start)
+	}()
+
+	r, err := c.queryAuthoritative(ctx, server.Address, zone, mdns.TypeSOA)
+	if err != nil {
+		server.Status = AuditUnreachable
+		server.Error = err.Error()
+		return
+	}
+
+	server.Rcode = rcodeString(r.Rcode)
+	server.Authoritative = r.Authoritative
+
+	for _, rr := range r.Answer {
+		if soa, ok := rr.(*mdns.SOA); ok {
+			server.Serial = soa.Serial
+		}
+	}
+
+	if r.Rcode != mdns.RcodeSuccess || !r.Authoritative {
+		server.Status = AuditLame
+		server.Error = fmt.Sprintf("not authoritative for %s (%s, aa=%t)", zone, server.Rcode, r.Authoritative)
+		return
+	}
+
+	r, err = c.queryAuthoritative(ctx, server.Address, zone, mdns.TypeNS)
+	if err != nil {
+		server.Status = AuditUnreachable
+		server.Error = err.Error()
+		return
+	}
+
+	server.NS = make([]string, 0)
+	for _, rr := range r.Answer {
+		if ns, ok := rr.(*mdns.NS); ok {
+			server.NS = append(server.NS, mdns.CanonicalName(ns.Ns))
+		}
+	}
+	sort.Strings(server.NS)
+
+	r, err = c.queryAuthoritative(ctx, server.Address, zone, qtype)
+	if err != nil {
+		server.Status = AuditUnreachable
+		server.Error = err.Error()
+		return
+	}
+
+	server.Records = make([]Record, 0)
+	for _, rr := range r.Answer {
+		if rr.Header().Rrtype != qtype {
+			continue
+		}
+		if record := parseAnswer(rr); record != nil {
+			server.Records = append(server.Records, *record)
+		}
+	}
+}
+
+// queryAuthoritative sends a non-recursive query for name straight to the
+// server at address.
+func (c *Client) queryAuthoritative(ctx context.Context, address string, name string, qtype uint16) (*mdns.Msg, error) {
+	m := new(mdns.Msg)
+	m.SetQuestion(name, qtype)
+	m.RecursionDesired = false
+	m.SetEdns0(defaultUDPSize, false)
+
+	r, _, err := c.exchangeWith(ctx, m, address)
+	return r, err
+}
+
+// compareAuditServers compares the servers that answered authoritatively
+// against the highest SOA serial and the most common NS set and answer.
+func compareAuditServers(response *NSAuditResponse) {
+	nsSets := make([]string, 0)
+	answers := make([]string, 0)
+	for _, server := range response.Servers {
+		if server.Status != "" {
+			continue
+		}
+		if len(nsSets) == 0 || serialGreater(server.Serial, response.Serial) {
+			response.Serial = server.Serial
+		}
+		nsSets = append(nsSets, strings.Join(server.NS, " "))
+		answers = append(answers, strings.Join(answerValues(server.Records), "\x00"))
+	}
+
+	nsSet := mostCommon(nsSets)
+	answer := mostCommon(answers)
+
+	response.Consistent = true
+	for i := range response.Servers {
+		server := &response.Servers[i]
+		if server.Status != "" {
+			response.Consistent = false
+			continue
+		}
+
+		if server.Serial != response.Serial {
+			server.Issues = append(server.Issues, fmt.Sprintf("SOA serial %d is behind %d", server.Serial, response.Serial))
+		}
+		if strings.Join(server.NS, " ") != nsSet {
+			server.Issues = append(server.Issues, fmt.Sprintf("NS set differs: %s", strings.Join(server.NS, ", ")))
+		}
+		if strings.Join(answerValues(server.Records), "\x00") != answer {
+			server.Issues = append(server.Issues, fmt.Sprintf("%s records differ from the other servers", response.RecordType))
+		}
+
+		server.Status = AuditOK
+		if len(server.Issues) > 0 {
+			server.Status = AuditOutOfSync
+			response.Consistent = false
+		}
+	}
+}
+
+// serialGreater reports whether SOA serial a is greater than b using serial
+// number arithmetic (RFC 1982), so serials that wrapped around compare as
+// newer.
+func serialGreater(a, b uint32) bool {
+	return int32(a-b) > 0
+}
+
+// mostCommon returns the value occurring most often, preferring the first
+// value to reach that count on ties.
+func mostCommon(values []string) string {
+	counts := make(map[string]int)
+	best, bestCount := "", 0
+	for _, value := range values {
+		counts[value]++
+		if counts[value] > bestCount {
+			best, bestCount = value, counts[value]
+		}
+	}
+	return best
+}
@@ -0,0 +1,181 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	mdns "github.com/miekg/dns"
+)
+
+// authoritativeHandler serves the apex of example.test with the given SOA
+// serial and A record.
+func authoritativeHandler(t *testing.T, serial uint32, address string) mdns.HandlerFunc {
+	return func(w mdns.ResponseWriter, r *mdns.Msg) {
+		m := new(mdns.Msg)
+		m.SetReply(r)
+		m.Authoritative = true
+
+		switch r.Question[0].Qtype {
+		case mdns.TypeSOA:
+			m.Answer = append(m.Answer, mustRR(t, fmt.Sprintf("example.test. 3600 IN SOA ns1.example.test. hostmaster.example.test. %d 7200 3600 1209600 300", serial)))
+		case mdns.TypeNS:
+			m.Answer = append(m.Answer,
+				mustRR(t, "example.test. 3600 IN NS ns1.example.test."),
+				mustRR(t, "example.test. 3600 IN NS ns2.example.test."),
+			)
+		case mdns.TypeA:
+			m.Answer = append(m.Answer, mustRR(t, "example.test. 300 IN A "+address))
+		}
+		_ = w.WriteMsg(m)
+	}
+}
+
+func TestClient_AuditNameservers(t *testing.T) {
+	addr := startTestServer(t, zoneHandler(
+		"example.test. 3600 IN NS ns1.example.test.",
+		"example.test. 3600 IN NS ns2.example.test.",
+		"example.test. 3600 IN NS ns3.example.test.",
+		"example.test. 3600 IN NS ns4.example.test.",
+		"example.test. 3600 IN NS ns5.example.test.",
+		"ns1.example.test. 3600 IN A 127.0.0.2",
+		"ns2.example.test. 3600 IN A 127.0.0.3",
+		"ns3.example.test. 3600 IN A 127.0.0.4",
+		"ns4.example.test. 3600 IN A 127.0.0.5",
+	))
+	_, port, _ := net.SplitHostPort(addr)
+
+	startTestServerAt(t, net.JoinHostPort("127.0.0.2", port), authoritativeHandler(t, 2024010102, "192.0.2.2"))
+	startTestServerAt(t, net.JoinHostPort("127.0.0.3", port), authoritativeHandler(t, 2024010101, "192.0.2.1"))
+	startTestServerAt(t, net.JoinHostPort("127.0.0.4", port), func(w mdns.ResponseWriter, r *mdns.Msg) {
+		m := new(mdns.Msg)
+		m.SetRcode(r, mdns.RcodeRefused)
+		_ = w.WriteMsg(m)
+	})
+
+	client := NewClient(time.Second, addr)
+	client.port = port
+
+	resp, err := client.AuditNameservers(context.Background(), "example.test", "A")
+	if err != nil {
+		t.Fatalf("AuditNameservers failed: %v", err)
+	}
+
+	if resp.Consistent {
+		t.Error("expected the audit not to be consistent")
+	}
+
+	if len(resp.Nameservers) != 5 {
+		t.Errorf("expected 5 nameservers, got %v", resp.Nameservers)
+	}
+
+	if resp.Serial != 2024010102 {
+		t.Errorf("expected highest serial 2024010102, got %d", resp.Serial)
+	}
+
+	statuses := make(map[string]NSAuditServer)
+	for _, server := range resp.Servers {
+		statuses[server.Nameserver] = server
+	}
+
+	expected := map[string]string{
+		"ns1.example.test.": AuditOK,
+		"ns2.example.test.": AuditOutOfSync,
+		"ns3.example.test.": AuditLame,
+		"ns4.example.test.": AuditUnreachable,
+		"ns5.example.test.": AuditUnreachable,
+	}
+	for name, status := range expected {
+		server, ok := statuses[name]
+		if !ok {
+			t.Errorf("expected a result for %s", name)
+			continue
+		}
+		if server.Status != status {
+			t.Errorf("expected %s to be %s, got %s (%v %s)", name, status, server.Status, server.Issues, server.Error)
+		}
+	}
+
+	if issues := statuses["ns2.example.test."].Issues; len(issues) != 2 {
+		t.Errorf("expected serial and record issues for ns2, got %v", issues)
+	}
+
+	if server := statuses["ns1.example.test."]; server.Family != "IPv4" || len(server.NS) != 2 {
+		t.Errorf("unexpected result for ns1: %+v", server)
+	}
+}
+
+func TestClient_AuditNameservers_NoNS(t *testing.T) {
+	addr := startTestServer(t, zoneHandler())
+
+	client := NewClient(time.Second, addr)
+
+	if _, err := client.AuditNameservers(context.Background(), "example.test", "A"); err == nil {
+		t.Error("expected error when the zone has no NS records")
+	}
+}
+
+func TestClient_AuditNameservers_SerialZero(t *testing.T) {
+	addr := startTestServer(t, authoritativeHandler(t, 0, "192.0.2.1"))
+
+	client := NewClient(time.Second, addr)
+
+	server := &NSAuditServer{Nameserver: "ns1.example.test.", Address: addr}
+	client.auditServer(context.Background(), server, "example.test.", mdns.TypeA)
+
+	if server.Status == AuditLame || server.Serial != 0 || len(server.Records) != 1 {
+		t.Errorf("expected serial 0 to be a valid serial, got %+v", server)
+	}
+}
+
+func TestCompareAuditServers_SerialWraparound(t *testing.T) {
+	response := &NSAuditResponse{
+		RecordType: "A",
+		Servers: []NSAuditServer{
+			{Nameserver: "ns1.example.test.", Serial: 4294967295},
+			{Nameserver: "ns2.example.test.", Serial: 1},
+			{Nameserver: "ns3.example.test.", Serial: 1},
+		},
+	}
+
+	compareAuditServers(response)
+
+	if response.Serial != 1 {
+		t.Errorf("expected serial 1 to be newer than 4294967295, got %d", response.Serial)
+	}
+	if response.Servers[0].Status != AuditOutOfSync || response.Servers[1].Status != AuditOK {
+		t.Errorf("expected only ns1 to be behind, got %+v", response.Servers)
+	}
+}
+
+func TestSerialGreater(t *testing.T) {
+	tests := []struct {
+		a, b     uint32
+		expected bool
+	}{
+		{2, 1, true},
+		{1, 2, false},
+		{1, 1, false},
+		{0, 4294967295, true},
+		{4294967295, 0, false},
+		{100, 4294967200, true},
+	}
+
+	for _, tt := range tests {
+		if got := serialGreater(tt.a, tt.b); got != tt.expected {
+			t.Errorf("serialGreater(%d, %d) = %t, want %t", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
+
+func TestMostCommon(t *testing.T) {
+	if got := mostCommon([]string{"a", "b", "b", "a", "b"}); got != "b" {
+		t.Errorf("expected b, got %s", got)
+	}
+
+	if got := mostCommon([]string{"a", "b"}); got != "a" {
+		t.Errorf("expected the first value on ties, got %s", got)
+	}
+}
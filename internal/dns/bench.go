@@ -0,0 +1,158 @@
+package dns
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	mdns "github.com/miekg/dns"
+
+	"watchr/internal/idn"
+)
+
+// Bench measures the latency of every nameserver, one after the other, with a
+// client created from timeout and opts. Each nameserver gets count cached
+// queries for domain itself, after one query to warm its cache, and count
+// uncached queries for random labels under domain that it cannot have cached.
+// Up to concurrency queries are in flight at once.
+func Bench(ctx context.Context, timeout time.Duration, domain string, recordType string, nameservers []string, count int, concurrency int, opts ...Option) (*BenchResponse, error) {
+	qtype, err := parseRecordType(recordType)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nameservers) == 0 {
+		return nil, fmt.Errorf("no nameservers to benchmark")
+	}
+	if count < 1 {
+		return nil, fmt.Errorf("invalid query count %d", count)
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	name, err := idn.Parse(mdns.CanonicalName(domain))
+	if err != nil {
+		return nil, err
+	}
+	domain = name.ASCII
+	recordType = strings.ToUpper(recordType)
+
+	response := &BenchResponse{
+		Domain:      domain,
+		IDN:         name,
+		RecordType:  recordType,
+		Count:       count,
+		Concurrency: concurrency,
+		Results:     make([]BenchResult, 0, len(nameservers)),
+	}
+
+	start := time.Now()
+	for _, nameserver := range nameservers {
+		client := NewClient(timeout, nameserver, opts...)
+
+		slog.Debug("benchmarking nameserver", "nameserver", client.nameserver, "domain", domain, "count", count, "concurrency", concurrency)
+
+		_, _ = client.query(ctx, domain, qtype, recordType)
+
+		result := BenchResult{Nameserver: client.nameserver}
+		result.Cached = client.benchQueries(ctx, count, concurrency, func() string {
+			return domain
+		}, qtype, recordType)
+		result.Uncached = client.benchQueries(ctx, count, concurrency, func() string {
+			return strings.ToLower(rand.Text()) + "." + domain
+		}, qtype, recordType)
+
+		response.Results = append(response.Results, result)
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+	response.QueryTime = time.Since(start)
+
+	return response, nil
+}
+
+// benchQueries sends count queries for the names returned by name and
+// summarizes their latency.
+func (c *Client) benchQueries(ctx context.Context, count int, concurrency int, name func() string, qtype uint16, recordType string) BenchStats {
+	var mu sync.Mutex
+	latencies := make([]time.Duration, 0, count)
+	stats := BenchStats{Queries: count}
+
+	var wg sync.WaitGroup
+	queue := make(chan string)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range queue {
+				resp, err := c.query(ctx, name, qtype, recordType)
+
+				mu.Lock()
+				switch {
+				case err != nil && isTimeout(err):
+					stats.Timeouts++
+				case err != nil:
+					stats.Errors++
+				case resp.Rcode != rcodeString(mdns.RcodeSuccess) && resp.Rcode != rcodeString(mdns.RcodeNameError):
+					stats.Errors++
+				default:
+					latencies = append(latencies, resp.QueryTime)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i := 0; i < count; i++ {
+		queue <- name()
+	}
+	close(queue)
+	wg.Wait()
+
+	stats.ErrorRate = float64(stats.Errors+stats.Timeouts) / float64(count)
+
+	if len(latencies) == 0 {
+		return stats
+	}
+
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i] < latencies[j]
+	})
+	stats.Min = latencies[0]
+	stats.Median = percentile(latencies, 50)
+	stats.P95 = percentile(latencies, 95)
+	stats.P99 = percentile(latencies, 99)
+	stats.Max = latencies[len(latencies)-1]
+
+	return stats
+}
+
+// percentile returns the p-th percentile of the sorted latencies using the
+// nearest-rank method.
+func percentile(latencies []time.Duration, p int) time.Duration {
+	rank := (p*len(latencies) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return latencies[rank-1]
+}
+
+// isTimeout reports whether a query failed because the nameserver did not
+// answer in time.
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
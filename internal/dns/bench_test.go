@@ -0,0 +1,138 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	mdns "github.com/miekg/dns"
+)
+
+func TestBench(t *testing.T) {
+	var cached, uncached atomic.Int32
+	fast := startTestServer(t, func(w mdns.ResponseWriter, r *mdns.Msg) {
+		m := new(mdns.Msg)
+		m.SetReply(r)
+		if r.Question[0].Name == "example.com." {
+			cached.Add(1)
+			m.Answer = append(m.Answer, mustRR(t, "example.com. 300 IN A 192.0.2.1"))
+		} else if strings.HasSuffix(r.Question[0].Name, ".example.com.") {
+			uncached.Add(1)
+			m.Rcode = mdns.RcodeNameError
+		}
+		_ = w.WriteMsg(m)
+	})
+	failing := startTestServer(t, func(w mdns.ResponseWriter, r *mdns.Msg) {
+		m := new(mdns.Msg)
+		m.SetRcode(r, mdns.RcodeServerFailure)
+		_ = w.WriteMsg(m)
+	})
+
+	resp, err := Bench(context.Background(), time.Second, "example.com", "A", []string{fast, failing}, 20, 4)
+	if err != nil {
+		t.Fatalf("Bench failed: %v", err)
+	}
+
+	if len(resp.Results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(resp.Results))
+	}
+
+	result := resp.Results[0]
+	if result.Nameserver != fast {
+		t.Errorf("expected results in the order of the nameservers, got %s", result.Nameserver)
+	}
+	for _, stats := range []BenchStats{result.Cached, result.Uncached} {
+		if stats.Queries != 20 || stats.Errors != 0 || stats.Timeouts != 0 || stats.ErrorRate != 0 {
+			t.Errorf("expected 20 successful queries, got %+v", stats)
+		}
+		if stats.Min <= 0 || stats.Min > stats.Median || stats.Median > stats.P95 || stats.P95 > stats.P99 || stats.P99 > stats.Max {
+			t.Errorf("expected ordered latencies, got %+v", stats)
+		}
+	}
+
+	// One more cached query warms the cache before measuring.
+	if n := cached.Load(); n != 21 {
+		t.Errorf("expected 21 queries for example.com., got %d", n)
+	}
+	if n := uncached.Load(); n != 20 {
+		t.Errorf("expected 20 queries for random labels, got %d", n)
+	}
+
+	failed := resp.Results[1].Cached
+	if failed.Errors != 20 || failed.ErrorRate != 1 || failed.Max != 0 {
+		t.Errorf("expected every SERVFAIL answer to count as an error, got %+v", failed)
+	}
+}
+
+func TestBench_Timeouts(t *testing.T) {
+	var queries atomic.Int32
+	addr := startTestServer(t, func(w mdns.ResponseWriter, r *mdns.Msg) {
+		// Drop every other query.
+		if queries.Add(1)%2 == 0 {
+			return
+		}
+		m := new(mdns.Msg)
+		m.SetReply(r)
+		_ = w.WriteMsg(m)
+	})
+
+	resp, err := Bench(context.Background(), 100*time.Millisecond, "example.com", "A", []string{addr}, 4, 1)
+	if err != nil {
+		t.Fatalf("Bench failed: %v", err)
+	}
+
+	stats := resp.Results[0].Cached
+	if stats.Timeouts != 2 || stats.ErrorRate != 0.5 {
+		t.Errorf("expected half of the queries to time out, got %+v", stats)
+	}
+}
+
+func TestBench_InvalidArguments(t *testing.T) {
+	if _, err := Bench(context.Background(), time.Second, "example.com", "A", nil, 10, 1); err == nil {
+		t.Error("expected an error without nameservers")
+	}
+	if _, err := Bench(context.Background(), time.Second, "example.com", "A", []string{"127.0.0.1"}, 0, 1); err == nil {
+		t.Error("expected an error for a zero query count")
+	}
+	if _, err := Bench(context.Background(), time.Second, "example.com", "BOGUS", []string{"127.0.0.1"}, 1, 1); err == nil {
+		t.Error("expected an error for an unknown record type")
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	latencies := make([]time.Duration, 100)
+	for i := range latencies {
+		latencies[i] = time.Duration(i+1) * time.Millisecond
+	}
+
+	tests := []struct {
+		p        int
+		expected time.Duration
+	}{
+		{50, 50 * time.Millisecond},
+		{95, 95 * time.Millisecond},
+		{99, 99 * time.Millisecond},
+		{100, 100 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := percentile(latencies, tt.p); got != tt.expected {
+			t.Errorf("percentile(%d) = %v, want %v", tt.p, got, tt.expected)
+		}
+	}
+
+	if got := percentile([]time.Duration{time.Second}, 99); got != time.Second {
+		t.Errorf("percentile of a single latency = %v, want 1s", got)
+	}
+}
+
+func TestIsTimeout(t *testing.T) {
+	if !isTimeout(context.DeadlineExceeded) {
+		t.Error("expected context.DeadlineExceeded to be a timeout")
+	}
+	if isTimeout(errors.New("connection refused")) {
+		t.Error("expected other errors not to be timeouts")
+	}
+}
@@ -2,139 +2,589 @@ package dns
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"log/slog"
+	"math"
 	"net"
+	"net/http"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	mdns "github.com/miekg/dns"
+
+	"watchr/internal/idn"
 )
 
+// defaultUDPSize is the EDNS buffer size advertised in queries, as recommended
+// by the DNS Flag Day 2020.
+const defaultUDPSize = 1232
+
 type Client struct {
 	timeout    time.Duration
 	nameserver string
+	server     server
+	port       string
+	tlsConfig  *tls.Config
+	spkiPins   []string
+	dohMethod  string
+	httpClient *http.Client
+	forceTCP   bool
+	tsig       *tsigKey
+	edns       *EDNSConfig
+	opts       []Option
+
+	// servers are tried in turn, for up to attempts rounds, when a query
+	// fails. Only the system resolver configuration lists more than one.
+	servers        []server
+	attempts       int
+	attemptTimeout time.Duration
+	rotate         bool
+	next           atomic.Uint32
+
+	search     bool
+	resolvConf *ResolvConf
 }
 
-func NewClient(timeout time.Duration, nameserver string) *Client {
-	if nameserver == "" {
-		nameserver = getSystemDNS()
+// Option configures optional behaviour of a Client.
+type Option func(*Client)
+
+// WithTLSServerName sets the name used to authenticate DNS-over-TLS servers,
+// overriding the host given in the nameserver address.
+func WithTLSServerName(name string) Option {
+	return func(c *Client) {
+		if name != "" {
+			c.server.serverName = name
+		}
 	}
+}
 
-	nameserver = ensurePort(nameserver)
+// WithSPKIPins restricts DNS-over-TLS servers to certificate chains containing
+// a public key matching one of the pins (base64 SHA-256 of the SPKI, as in
+// RFC 7858 section 4.2).
+func WithSPKIPins(pins []string) Option {
+	return func(c *Client) {
+		c.spkiPins = pins
+	}
+}
 
-	return &Client{
-		timeout:    timeout,
-		nameserver: nameserver,
+// WithDoHMethod sets the HTTP method (GET or POST) used for DNS-over-HTTPS
+// queries. POST is used by default.
+func WithDoHMethod(method string) Option {
+	return func(c *Client) {
+		if method != "" {
+			c.dohMethod = strings.ToUpper(method)
+		}
 	}
 }
 
-func getSystemDNS() string {
-	// Attempt to read system DNS configuration from /etc/resolv.conf (Linux/Unix)
-	// Note: This file does not exist on Windows. On non-Linux systems or when
-	// /etc/resolv.conf is unavailable, this falls back to a public DNS server.
-	config, err := mdns.ClientConfigFromFile("/etc/resolv.conf")
-	if err != nil || len(config.Servers) == 0 {
-		slog.Warn("failed to read system DNS configuration, using fallback public DNS server (8.8.8.8). Use --server flag to specify a different DNS server.", "error", err)
-		return "8.8.8.8"
+// WithTCP forces plain DNS queries to be sent over TCP instead of UDP.
+func WithTCP(enabled bool) Option {
+	return func(c *Client) {
+		c.forceTCP = enabled
 	}
-	return config.Servers[0]
 }
 
-func ensurePort(nameserver string) string {
-	if strings.Contains(nameserver, ":") {
-		return nameserver
+// WithTSIG signs zone transfer requests with the TSIG key name and its base64
+// encoded secret. The algorithm defaults to hmac-sha256.
+func WithTSIG(name string, algorithm string, secret string) Option {
+	return func(c *Client) {
+		if name == "" {
+			return
+		}
+		if algorithm == "" {
+			algorithm = mdns.HmacSHA256
+		}
+		c.tsig = &tsigKey{
+			name:      mdns.CanonicalName(name),
+			algorithm: mdns.CanonicalName(algorithm),
+			secret:    secret,
+		}
 	}
+}
 
-	if net.ParseIP(nameserver) != nil {
-		return net.JoinHostPort(nameserver, "53")
+// WithSearch applies the search list and ndots setting of the system resolver
+// configuration to the names given to Query.
+func WithSearch(enabled bool) Option {
+	return func(c *Client) {
+		c.search = enabled
 	}
+}
 
-	return nameserver
+// NewClient returns a client that queries nameserver. The nameserver may be a
+// plain "host[:port]" address (optionally prefixed with "udp://" or
+// "tcp://"), use the "tls://host[:port][#name]" form for
+// DNS-over-TLS, or be an "https://" URL for DNS-over-HTTPS. When empty, the
+// system resolver configuration is used: every nameserver it lists is tried in
+// turn, honoring its timeout, attempts and rotate options.
+func NewClient(timeout time.Duration, nameserver string, opts ...Option) *Client {
+	var conf *ResolvConf
+	if nameserver == "" {
+		conf = systemResolvConf()
+		nameserver = conf.Servers[0]
+	}
+
+	c := &Client{
+		timeout:   timeout,
+		server:    parseServer(nameserver),
+		port:      "53",
+		dohMethod: http.MethodPost,
+		attempts:  1,
+		opts:      opts,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.nameserver = c.server.String()
+	c.servers = []server{c.server}
+
+	if conf != nil {
+		for _, ns := range conf.Servers[1:] {
+			c.servers = append(c.servers, parseServer(ns))
+		}
+		c.attempts = conf.Attempts
+		c.attemptTimeout = min(conf.Timeout, timeout)
+		c.rotate = conf.Rotate
+	}
+
+	if c.search {
+		if conf == nil {
+			conf = systemResolvConf()
+		}
+		c.resolvConf = conf
+	}
+
+	switch c.server.transport {
+	case transportTLS:
+		c.tlsConfig = c.newTLSConfig()
+	case transportHTTPS:
+		c.tlsConfig = c.newTLSConfig()
+		c.httpClient = c.newHTTPClient()
+	}
+
+	return c
 }
 
-func (c *Client) Query(ctx context.Context, domain string, recordType string) (*Response, error) {
-	domain = mdns.Fqdn(domain)
+// systemResolvConf reads the system resolver configuration. When it cannot be
+// read or lists no nameserver, the nameserver on the local machine is used.
+func systemResolvConf() *ResolvConf {
+	conf, err := ReadResolvConf(resolvConfPath)
+	if err != nil {
+		slog.Warn("failed to read system DNS configuration, using the local nameserver. Use --server flag to specify a different DNS server.", "path", resolvConfPath, "error", err)
+		conf, _ = ParseResolvConf(strings.NewReader(""))
+	}
+
+	if len(conf.Servers) == 0 {
+		slog.Debug("no nameserver configured, using the local nameserver", "path", resolvConfPath)
+		conf.Servers = []string{localNameserver}
+	}
+
+	return conf
+}
+
+func ensurePort(nameserver string, port string) string {
+	if _, _, err := net.SplitHostPort(nameserver); err == nil {
+		return nameserver
+	}
+
+	return net.JoinHostPort(strings.Trim(nameserver, "[]"), port)
+}
 
+// Query sends a recursive query for the recordType records of domain. When
+// the client was created WithSearch, the names from the search list are tried
+// in turn until one of them has records of that type. When the name is an
+// alias, its CNAME chain is reconstructed in the response. Internationalized
+// names are queried by their A-label.
+func (c *Client) Query(ctx context.Context, domain string, recordType string) (*Response, error) {
 	qtype, err := parseRecordType(recordType)
 	if err != nil {
 		return nil, err
 	}
 
+	domain, err = idn.ToASCII(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.searchQuery(ctx, domain, qtype, recordType)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.IDN, err = idn.Parse(resp.Domain)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Chain = c.cnameChain(ctx, resp, qtype)
+
+	return resp, nil
+}
+
+// searchQuery queries the names from the search list in turn, or only domain when
+// the client was not created WithSearch.
+func (c *Client) searchQuery(ctx context.Context, domain string, qtype uint16, recordType string) (*Response, error) {
+	if c.resolvConf == nil {
+		return c.query(ctx, mdns.Fqdn(domain), qtype, recordType)
+	}
+
+	names := c.resolvConf.NameList(domain)
+	searched := make([]string, 0, len(names))
+	var nodata, last *Response
+	for _, name := range names {
+		resp, err := c.query(ctx, name, qtype, recordType)
+		if err != nil {
+			return nil, err
+		}
+		searched = append(searched, name)
+		resp.Searched = searched
+
+		if resp.Rcode == rcodeString(mdns.RcodeSuccess) && len(resp.Records) > 0 {
+			return resp, nil
+		}
+		if resp.Rcode == rcodeString(mdns.RcodeSuccess) && nodata == nil {
+			nodata = resp
+		}
+		last = resp
+	}
+
+	// No name had records of the requested type: prefer an existing name
+	// over NXDOMAIN, as the system resolver does.
+	if nodata != nil {
+		nodata.Searched = searched
+		return nodata, nil
+	}
+	return last, nil
+}
+
+func (c *Client) query(ctx context.Context, domain string, qtype uint16, recordType string) (*Response, error) {
 	m := new(mdns.Msg)
 	m.SetQuestion(domain, qtype)
 	m.RecursionDesired = true
+	c.setEDNS(m)
 
-	client := &mdns.Client{
-		Timeout: c.timeout,
+	slog.Debug("querying DNS", "domain", domain, "type", recordType, "nameserver", c.nameserver)
+	r, info, err := c.exchange(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.newResponse(domain, recordType, r, info), nil
+}
+
+// newResponse converts a DNS message into a Response, keeping the rcode,
+// header flags, EDNS information and every section of the message.
+func (c *Client) newResponse(domain string, recordType string, r *mdns.Msg, info *exchangeInfo) *Response {
+	response := &Response{
+		Domain:     domain,
+		RecordType: recordType,
+		Nameserver: c.nameserver,
+		QueryTime:  info.queryTime,
+		Attempts:   info.attempts,
+		Transport:  info.transport,
+		TLS:        info.tls,
+		HTTP:       info.http,
+		Rcode:      rcodeString(r.Rcode),
+		Flags:      parseFlags(r),
+		EDNS:       parseEDNS(r),
+		Records:    parseSection(r.Answer),
+		Authority:  parseSection(r.Ns),
+		Additional: parseSection(r.Extra),
+	}
+
+	if info.server != "" {
+		response.Nameserver = info.server
+	}
+
+	return response
+}
+
+func parseSection(rrs []mdns.RR) []Record {
+	records := make([]Record, 0, len(rrs))
+	for _, rr := range rrs {
+		if rr.Header().Rrtype == mdns.TypeOPT {
+			continue
+		}
+		if record := parseAnswer(rr); record != nil {
+			records = append(records, *record)
+		}
+	}
+	return records
+}
+
+func rcodeString(rcode int) string {
+	if name, ok := mdns.RcodeToString[rcode]; ok {
+		return name
+	}
+	return fmt.Sprintf("RCODE%d", rcode)
+}
+
+func parseFlags(r *mdns.Msg) Flags {
+	return Flags{
+		Response:           r.Response,
+		Authoritative:      r.Authoritative,
+		Truncated:          r.Truncated,
+		RecursionDesired:   r.RecursionDesired,
+		RecursionAvailable: r.RecursionAvailable,
+		AuthenticatedData:  r.AuthenticatedData,
+		CheckingDisabled:   r.CheckingDisabled,
+	}
+}
+
+// exchange sends m to the configured nameservers. When a server cannot be
+// reached, or answers SERVFAIL, REFUSED or NOTIMP, the next one is tried, for
+// up to c.attempts rounds over the list. With rotate set, every query starts
+// with the next server in the list. Every try is recorded in the returned
+// exchangeInfo.
+func (c *Client) exchange(ctx context.Context, m *mdns.Msg) (*mdns.Msg, *exchangeInfo, error) {
+	servers := c.servers
+	if c.rotate && len(servers) > 1 {
+		first := int(c.next.Add(1)-1) % len(servers)
+		servers = append(append(make([]server, 0, len(servers)), servers[first:]...), servers[:first]...)
+	}
+
+	attempts := make([]QueryAttempt, 0, 1)
+	var lastResp *mdns.Msg
+	var lastInfo *exchangeInfo
+	var lastErr error
+
+	for round := 0; round < c.attempts; round++ {
+		for _, s := range servers {
+			r, info, err := c.exchangeServer(ctx, m, s)
+
+			attempt := QueryAttempt{Server: s.String()}
+			if err != nil {
+				attempt.Error = err.Error()
+				attempts = append(attempts, attempt)
+				lastErr = err
+
+				slog.Debug("nameserver failed", "nameserver", s.String(), "error", err)
+				if ctx.Err() != nil {
+					return nil, nil, err
+				}
+				continue
+			}
+
+			attempt.Rcode = rcodeString(r.Rcode)
+			attempt.QueryTime = info.queryTime
+			attempts = append(attempts, attempt)
+
+			info.server = s.String()
+			info.attempts = attempts
+
+			switch r.Rcode {
+			case mdns.RcodeServerFailure, mdns.RcodeRefused, mdns.RcodeNotImplemented:
+				slog.Debug("nameserver returned an error", "nameserver", s.String(), "rcode", attempt.Rcode)
+				lastResp, lastInfo = r, info
+				continue
+			}
+
+			return r, info, nil
+		}
+	}
+
+	// Every server failed: return the last answer received, if any.
+	if lastResp != nil {
+		lastInfo.attempts = attempts
+		return lastResp, lastInfo, nil
+	}
+
+	if len(attempts) > 1 {
+		return nil, nil, fmt.Errorf("no nameserver responded after %d attempts: %w", len(attempts), lastErr)
+	}
+	return nil, nil, lastErr
+}
+
+// exchangeServer sends m to a single nameserver using its transport. With the
+// system resolver configuration, every try is bounded by its timeout option.
+func (c *Client) exchangeServer(ctx context.Context, m *mdns.Msg, s server) (*mdns.Msg, *exchangeInfo, error) {
+	if c.attemptTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.attemptTimeout)
+		defer cancel()
+	}
+
+	switch s.transport {
+	case transportUDP:
+		return c.exchangeWith(ctx, m, s.address)
+	case transportTCP:
+		return c.exchangePlain(ctx, m, s.address, true)
+	case transportTLS:
+		return c.exchangeTLS(ctx, m)
+	case transportHTTPS:
+		return c.exchangeHTTPS(ctx, m)
+	default:
+		return nil, nil, fmt.Errorf("unsupported nameserver transport: %s", s.transport)
+	}
+}
+
+// exchangeWith sends m as a plain DNS query to address, bypassing the
+// configured nameserver. It is used to talk to authoritative servers directly.
+func (c *Client) exchangeWith(ctx context.Context, m *mdns.Msg, address string) (*mdns.Msg, *exchangeInfo, error) {
+	return c.exchangePlain(ctx, m, address, c.forceTCP)
+}
+
+// exchangePlain sends m over UDP, retrying over TCP when the answer comes back
+// truncated, or straight over TCP when useTCP is set.
+func (c *Client) exchangePlain(ctx context.Context, m *mdns.Msg, address string, useTCP bool) (*mdns.Msg, *exchangeInfo, error) {
+	transport := transportUDP
+	if useTCP {
+		transport = transportTCP
 	}
 
-	slog.Debug("querying DNS", "domain", domain, "type", recordType, "nameserver", c.nameserver)
 	start := time.Now()
-	r, _, err := client.ExchangeContext(ctx, m, c.nameserver)
-	queryTime := time.Since(start)
+	for {
+		client := &mdns.Client{
+			Net:     transport,
+			Timeout: c.timeout,
+		}
+
+		r, _, err := client.ExchangeContext(ctx, m, address)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if r.Truncated && transport == transportUDP {
+			slog.Debug("truncated response, retrying over TCP", "domain", m.Question[0].Name, "nameserver", address)
+			transport = transportTCP
+			continue
+		}
+
+		return r, &exchangeInfo{queryTime: time.Since(start), transport: transport}, nil
+	}
+}
 
+// QueryMany queries several record types for the same domain concurrently and
+// groups the results per type, in the order the types were requested. Each
+// type is a single query for domain: the search list is not used and CNAME
+// chains are not followed, the records are those of the answer section.
+func (c *Client) QueryMany(ctx context.Context, domain string, recordTypes []string) (*MultiResponse, error) {
+	name, err := idn.Parse(mdns.Fqdn(domain))
 	if err != nil {
 		return nil, err
 	}
+	domain = name.ASCII
 
-	if r.Rcode != mdns.RcodeSuccess {
-		return &Response{
-			Domain:     domain,
-			RecordType: recordType,
-			Nameserver: c.nameserver,
-			QueryTime:  queryTime,
-			Records:    []Record{},
-		}, nil
+	types := make([]string, 0, len(recordTypes))
+	qtypes := make([]uint16, 0, len(recordTypes))
+	seen := make(map[string]bool, len(recordTypes))
+	for _, recordType := range recordTypes {
+		recordType = strings.ToUpper(strings.TrimSpace(recordType))
+		if recordType == "" || seen[recordType] {
+			continue
+		}
+		qtype, err := parseRecordType(recordType)
+		if err != nil {
+			return nil, err
+		}
+		seen[recordType] = true
+		types = append(types, recordType)
+		qtypes = append(qtypes, qtype)
 	}
 
-	response := &Response{
+	if len(types) == 0 {
+		return nil, fmt.Errorf("no record types specified")
+	}
+
+	response := &MultiResponse{
 		Domain:     domain,
-		RecordType: recordType,
+		IDN:        name,
 		Nameserver: c.nameserver,
-		QueryTime:  queryTime,
-		Records:    make([]Record, 0),
+		Results:    make([]TypeResult, len(types)),
 	}
 
-	for _, ans := range r.Answer {
-		record := parseAnswer(ans)
-		if record != nil {
-			response.Records = append(response.Records, *record)
-		}
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i, recordType := range types {
+		wg.Add(1)
+		go func(i int, recordType string) {
+			defer wg.Done()
+
+			result := TypeResult{
+				RecordType: recordType,
+				Records:    []Record{},
+			}
+
+			resp, err := c.query(ctx, domain, qtypes[i], recordType)
+			if err != nil {
+				slog.Debug("DNS query failed", "domain", domain, "type", recordType, "error", err)
+				result.Error = err.Error()
+			} else {
+				result.QueryTime = resp.QueryTime
+				result.Rcode = resp.Rcode
+				result.Records = resp.Records
+			}
+
+			response.Results[i] = result
+		}(i, recordType)
 	}
+	wg.Wait()
+	response.QueryTime = time.Since(start)
 
 	return response, nil
 }
 
+// SupportedRecordTypes returns the record types that can be queried, in the
+// order used when querying all of them at once.
+func SupportedRecordTypes() []string {
+	return []string{
+		"A", "AAAA", "CNAME", "DNAME", "MX", "NS", "TXT", "SOA", "SRV", "PTR", "CAA",
+		"HTTPS", "SVCB", "TLSA", "SSHFP", "NAPTR", "URI", "LOC", "DS", "DNSKEY",
+	}
+}
+
+// parseRecordType returns the numeric type of one of the supported record
+// types, or of a type given in the RFC 3597 "TYPEnnn" generic form.
 func parseRecordType(recordType string) (uint16, error) {
 	recordType = strings.ToUpper(recordType)
 
 	types := map[string]uint16{
-		"A":     mdns.TypeA,
-		"AAAA":  mdns.TypeAAAA,
-		"CNAME": mdns.TypeCNAME,
-		"MX":    mdns.TypeMX,
-		"NS":    mdns.TypeNS,
-		"TXT":   mdns.TypeTXT,
-		"SOA":   mdns.TypeSOA,
-		"SRV":   mdns.TypeSRV,
-		"PTR":   mdns.TypePTR,
-		"CAA":   mdns.TypeCAA,
+		"A":      mdns.TypeA,
+		"AAAA":   mdns.TypeAAAA,
+		"CNAME":  mdns.TypeCNAME,
+		"DNAME":  mdns.TypeDNAME,
+		"MX":     mdns.TypeMX,
+		"NS":     mdns.TypeNS,
+		"TXT":    mdns.TypeTXT,
+		"SOA":    mdns.TypeSOA,
+		"SRV":    mdns.TypeSRV,
+		"PTR":    mdns.TypePTR,
+		"CAA":    mdns.TypeCAA,
+		"HTTPS":  mdns.TypeHTTPS,
+		"SVCB":   mdns.TypeSVCB,
+		"TLSA":   mdns.TypeTLSA,
+		"SSHFP":  mdns.TypeSSHFP,
+		"NAPTR":  mdns.TypeNAPTR,
+		"URI":    mdns.TypeURI,
+		"LOC":    mdns.TypeLOC,
+		"DS":     mdns.TypeDS,
+		"DNSKEY": mdns.TypeDNSKEY,
 	}
 
-	qtype, ok := types[recordType]
-	if !ok {
-		return 0, fmt.Errorf("unsupported record type: %s", recordType)
+	if qtype, ok := types[recordType]; ok {
+		return qtype, nil
+	}
+
+	if number, ok := strings.CutPrefix(recordType, "TYPE"); ok {
+		if qtype, err := strconv.ParseUint(number, 10, 16); err == nil {
+			return uint16(qtype), nil
+		}
 	}
 
-	return qtype, nil
+	return 0, fmt.Errorf("unsupported record type: %s", recordType)
 }
 
 func parseAnswer(ans mdns.RR) *Record {
 	header := ans.Header()
 
 	record := &Record{
-		Type: mdns.TypeToString[header.Rrtype],
+		Name: header.Name,
+		Type: typeString(header.Rrtype),
 		TTL:  header.Ttl,
 	}
 
@@ -147,24 +597,164 @@ func parseAnswer(ans mdns.RR) *Record {
 		record.Value = rr.Target
 	case *mdns.MX:
 		record.Value = fmt.Sprintf("%d %s", rr.Preference, rr.Mx)
+		record.Data = &MXData{Preference: rr.Preference, Exchange: rr.Mx}
 	case *mdns.NS:
 		record.Value = rr.Ns
 	case *mdns.TXT:
 		record.Value = strings.Join(rr.Txt, " ")
+		record.Data = &TXTData{Strings: rr.Txt}
 	case *mdns.SOA:
 		record.Value = fmt.Sprintf("%s %s %d %d %d %d %d",
 			rr.Ns, rr.Mbox, rr.Serial, rr.Refresh, rr.Retry, rr.Expire, rr.Minttl)
+		record.Data = &SOAData{
+			Mname:   rr.Ns,
+			Rname:   rr.Mbox,
+			Serial:  rr.Serial,
+			Refresh: rr.Refresh,
+			Retry:   rr.Retry,
+			Expire:  rr.Expire,
+			Minimum: rr.Minttl,
+		}
 	case *mdns.SRV:
 		record.Value = fmt.Sprintf("%d %d %d %s",
 			rr.Priority, rr.Weight, rr.Port, rr.Target)
+		record.Data = &SRVData{Priority: rr.Priority, Weight: rr.Weight, Port: rr.Port, Target: rr.Target}
 	case *mdns.PTR:
 		record.Value = rr.Ptr
 	case *mdns.CAA:
 		record.Value = fmt.Sprintf("%d %s %s",
 			rr.Flag, rr.Tag, rr.Value)
+		record.Data = &CAAData{Flag: rr.Flag, Tag: rr.Tag, Value: rr.Value}
+	case *mdns.DS:
+		record.Value = fmt.Sprintf("%d %d %d %s",
+			rr.KeyTag, rr.Algorithm, rr.DigestType, strings.ToUpper(rr.Digest))
+		record.Data = &DSData{
+			KeyTag:     rr.KeyTag,
+			Algorithm:  rr.Algorithm,
+			DigestType: rr.DigestType,
+			Digest:     strings.ToUpper(rr.Digest),
+		}
+	case *mdns.DNSKEY:
+		record.Value = fmt.Sprintf("%d %d %d %s",
+			rr.Flags, rr.Protocol, rr.Algorithm, rr.PublicKey)
+		record.Data = &DNSKEYData{
+			Flags:     rr.Flags,
+			Protocol:  rr.Protocol,
+			Algorithm: rr.Algorithm,
+			KeyTag:    rr.KeyTag(),
+			PublicKey: rr.PublicKey,
+		}
+	case *mdns.RRSIG:
+		record.Value = fmt.Sprintf("%s %d %d %d %s %s %d %s %s",
+			mdns.TypeToString[rr.TypeCovered], rr.Algorithm, rr.Labels, rr.OrigTtl,
+			mdns.TimeToString(rr.Expiration), mdns.TimeToString(rr.Inception),
+			rr.KeyTag, rr.SignerName, rr.Signature)
+		record.Data = &RRSIGData{
+			TypeCovered: mdns.TypeToString[rr.TypeCovered],
+			Algorithm:   rr.Algorithm,
+			Labels:      rr.Labels,
+			OriginalTTL: rr.OrigTtl,
+			Expiration:  rrsigTime(rr.Expiration),
+			Inception:   rrsigTime(rr.Inception),
+			KeyTag:      rr.KeyTag,
+			SignerName:  rr.SignerName,
+			Signature:   rr.Signature,
+		}
+	case *mdns.DNAME:
+		record.Value = rr.Target
+	case *mdns.HTTPS:
+		record.Value = rdataString(rr)
+		record.Data = svcbData(&rr.SVCB)
+	case *mdns.SVCB:
+		record.Value = rdataString(rr)
+		record.Data = svcbData(rr)
+	case *mdns.TLSA:
+		record.Value = rdataString(rr)
+		record.Data = &TLSAData{
+			Usage:        rr.Usage,
+			Selector:     rr.Selector,
+			MatchingType: rr.MatchingType,
+			Certificate:  rr.Certificate,
+		}
+	case *mdns.SSHFP:
+		record.Value = rdataString(rr)
+		record.Data = &SSHFPData{Algorithm: rr.Algorithm, Type: rr.Type, Fingerprint: strings.ToUpper(rr.FingerPrint)}
+	case *mdns.NAPTR:
+		record.Value = rdataString(rr)
+		record.Data = &NAPTRData{
+			Order:       rr.Order,
+			Preference:  rr.Preference,
+			Flags:       rr.Flags,
+			Service:     rr.Service,
+			Regexp:      rr.Regexp,
+			Replacement: rr.Replacement,
+		}
+	case *mdns.URI:
+		record.Value = rdataString(rr)
+		record.Data = &URIData{Priority: rr.Priority, Weight: rr.Weight, Target: rr.Target}
+	case *mdns.LOC:
+		record.Value = rdataString(rr)
+		record.Data = locData(rr)
+	case *mdns.RFC3597:
+		// Types unknown to the library use the RFC 3597 generic form.
+		record.Value = fmt.Sprintf("\\# %d %s", len(rr.Rdata)/2, rr.Rdata)
 	default:
-		return nil
+		record.Value = rdataString(rr)
 	}
 
 	return record
 }
+
+func typeString(rrtype uint16) string {
+	if name, ok := mdns.TypeToString[rrtype]; ok {
+		return name
+	}
+	return fmt.Sprintf("TYPE%d", rrtype)
+}
+
+// rdataString returns the presentation format of the RDATA of rr, without the
+// owner name, TTL, class and type.
+func rdataString(rr mdns.RR) string {
+	return strings.TrimSpace(strings.TrimPrefix(rr.String(), rr.Header().String()))
+}
+
+func svcbData(rr *mdns.SVCB) *SVCBData {
+	data := &SVCBData{
+		Priority: rr.Priority,
+		Target:   rr.Target,
+		Params:   make([]SVCBParam, 0, len(rr.Value)),
+	}
+	for _, kv := range rr.Value {
+		data.Params = append(data.Params, SVCBParam{Key: kv.Key().String(), Value: kv.String()})
+	}
+	return data
+}
+
+// locData decodes the fixed-point coordinates and the exponent encoded
+// precisions of a LOC record, as described in RFC 1876.
+func locData(rr *mdns.LOC) *LOCData {
+	return &LOCData{
+		Latitude:            float64(int64(rr.Latitude)-mdns.LOC_EQUATOR) / mdns.LOC_DEGREES,
+		Longitude:           float64(int64(rr.Longitude)-mdns.LOC_PRIMEMERIDIAN) / mdns.LOC_DEGREES,
+		Altitude:            (float64(rr.Altitude) - mdns.LOC_ALTITUDEBASE*100) / 100,
+		Size:                locPrecision(rr.Size),
+		HorizontalPrecision: locPrecision(rr.HorizPre),
+		VerticalPrecision:   locPrecision(rr.VertPre),
+	}
+}
+
+// locPrecision converts a LOC size or precision, encoded as a mantissa and a
+// power of ten in centimeters, to meters.
+func locPrecision(value uint8) float64 {
+	return float64(value>>4) * math.Pow10(int(value&0x0f)) / 100
+}
+
+// rrsigTime converts an RRSIG timestamp, which uses serial number arithmetic,
+// to the time closest to now.
+func rrsigTime(t uint32) time.Time {
+	parsed, err := time.Parse("20060102150405", mdns.TimeToString(t))
+	if err != nil {
+		return time.Time{}
+	}
+	return parsed
+}
@@ -2,9 +2,14 @@ package dns
 
 import (
 	"context"
+	"math"
+	"reflect"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	mdns "github.com/miekg/dns"
 )
 
 func TestNewClient(t *testing.T) {
@@ -204,3 +209,398 @@ func TestClient_Query_CustomNameserver(t *testing.T) {
 		t.Error("expected at least one A record")
 	}
 }
+
+func TestClient_QueryMany(t *testing.T) {
+	addr := startTestServer(t, zoneHandler(
+		"example.com. 300 IN A 192.0.2.1",
+		"example.com. 300 IN AAAA 2001:db8::1",
+		"example.com. 300 IN MX 10 mail.example.com.",
+	))
+
+	client := NewClient(5*time.Second, addr)
+	ctx := context.Background()
+
+	resp, err := client.QueryMany(ctx, "example.com", []string{"a", "AAAA", "MX", "TXT", "A"})
+	if err != nil {
+		t.Fatalf("QueryMany failed: %v", err)
+	}
+
+	if resp.Domain != "example.com." {
+		t.Errorf("expected domain 'example.com.', got %s", resp.Domain)
+	}
+
+	if len(resp.Results) != 4 {
+		t.Fatalf("expected 4 results, got %d", len(resp.Results))
+	}
+
+	expected := []string{"A", "AAAA", "MX", "TXT"}
+	for i, result := range resp.Results {
+		if result.RecordType != expected[i] {
+			t.Errorf("expected result %d to be %s, got %s", i, expected[i], result.RecordType)
+		}
+		if result.Error != "" {
+			t.Errorf("unexpected error for %s: %s", result.RecordType, result.Error)
+		}
+	}
+
+	if len(resp.Results[2].Records) != 1 || resp.Results[2].Records[0].Value != "10 mail.example.com." {
+		t.Errorf("unexpected MX records: %+v", resp.Results[2].Records)
+	}
+
+	if len(resp.Results[3].Records) != 0 {
+		t.Errorf("expected no TXT records, got %d", len(resp.Results[3].Records))
+	}
+}
+
+func TestClient_QueryMany_IDN(t *testing.T) {
+	addr := startTestServer(t, zoneHandler("xn--bcher-kva.de. 300 IN A 192.0.2.1"))
+
+	client := NewClient(5*time.Second, addr)
+	resp, err := client.QueryMany(context.Background(), "bücher.de", []string{"A"})
+	if err != nil {
+		t.Fatalf("QueryMany failed: %v", err)
+	}
+
+	if resp.Domain != "xn--bcher-kva.de." || len(resp.Results[0].Records) != 1 {
+		t.Errorf("expected the A-label to be queried, got %s with %+v", resp.Domain, resp.Results)
+	}
+	if resp.IDN == nil || resp.IDN.Unicode != "bücher.de." {
+		t.Errorf("expected the U-label in the response, got %+v", resp.IDN)
+	}
+}
+
+func TestClient_QueryMany_SingleQuery(t *testing.T) {
+	var queries atomic.Int32
+	addr := startTestServer(t, func(w mdns.ResponseWriter, r *mdns.Msg) {
+		queries.Add(1)
+
+		m := new(mdns.Msg)
+		m.SetReply(r)
+		if r.Question[0].Name == "alias.example.com." {
+			m.Answer = append(m.Answer, mustRR(t, "alias.example.com. 300 IN CNAME target.example.com."))
+		}
+		_ = w.WriteMsg(m)
+	})
+
+	client := NewClient(5*time.Second, addr)
+	resp, err := client.QueryMany(context.Background(), "alias.example.com", []string{"A", "AAAA"})
+	if err != nil {
+		t.Fatalf("QueryMany failed: %v", err)
+	}
+
+	if n := queries.Load(); n != 2 {
+		t.Errorf("expected one query per type without following the CNAME, got %d queries", n)
+	}
+	for _, result := range resp.Results {
+		if len(result.Records) != 1 || result.Records[0].Type != "CNAME" {
+			t.Errorf("expected the CNAME record for %s, got %+v", result.RecordType, result.Records)
+		}
+	}
+}
+
+func TestClient_QueryMany_InvalidRecordType(t *testing.T) {
+	client := NewClient(5*time.Second, "127.0.0.1:53")
+	ctx := context.Background()
+
+	_, err := client.QueryMany(ctx, "example.com", []string{"A", "INVALID"})
+	if err == nil {
+		t.Error("expected error for invalid record type")
+	}
+}
+
+func TestClient_Query_FullResponse(t *testing.T) {
+	addr := startTestServer(t, func(w mdns.ResponseWriter, r *mdns.Msg) {
+		m := new(mdns.Msg)
+		m.SetRcode(r, mdns.RcodeNameError)
+		m.Authoritative = true
+		m.RecursionAvailable = true
+		m.Ns = append(m.Ns, mustRR(t, "example.com. 3600 IN SOA ns.example.com. hostmaster.example.com. 1 7200 3600 1209600 300"))
+		m.Extra = append(m.Extra, mustRR(t, "ns.example.com. 3600 IN A 192.0.2.53"))
+
+		opt := new(mdns.OPT)
+		opt.Hdr.Name = "."
+		opt.Hdr.Rrtype = mdns.TypeOPT
+		opt.SetUDPSize(1232)
+		opt.Option = append(opt.Option,
+			&mdns.EDNS0_NSID{Code: mdns.EDNS0NSID, Nsid: "6e7331"},
+			&mdns.EDNS0_EDE{InfoCode: mdns.ExtendedErrorCodeNotAuthoritative, ExtraText: "test"},
+		)
+		m.Extra = append(m.Extra, opt)
+
+		_ = w.WriteMsg(m)
+	})
+
+	client := NewClient(5*time.Second, addr)
+
+	resp, err := client.Query(context.Background(), "missing.example.com", "A")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	if resp.Rcode != "NXDOMAIN" {
+		t.Errorf("expected rcode NXDOMAIN, got %s", resp.Rcode)
+	}
+
+	if !resp.Flags.Response || !resp.Flags.Authoritative || !resp.Flags.RecursionDesired || !resp.Flags.RecursionAvailable {
+		t.Errorf("unexpected flags: %+v", resp.Flags)
+	}
+
+	if len(resp.Records) != 0 {
+		t.Errorf("expected no answer records, got %+v", resp.Records)
+	}
+
+	if len(resp.Authority) != 1 || resp.Authority[0].Type != "SOA" {
+		t.Errorf("expected SOA in authority section, got %+v", resp.Authority)
+	}
+
+	if len(resp.Additional) != 1 || resp.Additional[0].Value != "192.0.2.53" {
+		t.Errorf("expected A record in additional section without OPT, got %+v", resp.Additional)
+	}
+
+	if resp.EDNS == nil {
+		t.Fatal("expected EDNS information")
+	}
+
+	if resp.EDNS.UDPSize != 1232 {
+		t.Errorf("expected UDP size 1232, got %d", resp.EDNS.UDPSize)
+	}
+
+	if len(resp.EDNS.Options) != 2 {
+		t.Fatalf("expected 2 EDNS options, got %+v", resp.EDNS.Options)
+	}
+
+	if resp.EDNS.Options[0].Name != "NSID" || !strings.Contains(resp.EDNS.Options[0].Value, `"ns1"`) {
+		t.Errorf("unexpected NSID option: %+v", resp.EDNS.Options[0])
+	}
+
+	if resp.EDNS.Options[1].Name != "EDE" || !strings.Contains(resp.EDNS.Options[1].Value, "Not Authoritative") {
+		t.Errorf("unexpected EDE option: %+v", resp.EDNS.Options[1])
+	}
+}
+
+func TestClient_Query_IDN(t *testing.T) {
+	addr := startTestServer(t, zoneHandler("xn--bcher-kva.de. 300 IN A 192.0.2.1"))
+
+	client := NewClient(5*time.Second, addr)
+
+	resp, err := client.Query(context.Background(), "bücher.de", "A")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	if resp.Domain != "xn--bcher-kva.de." || len(resp.Records) != 1 {
+		t.Errorf("expected the A-label to be queried, got %s %+v", resp.Domain, resp.Records)
+	}
+	if resp.IDN == nil || resp.IDN.Unicode != "bücher.de." {
+		t.Errorf("expected the U-label in the response, got %+v", resp.IDN)
+	}
+
+	if _, err := client.Query(context.Background(), "-bücher.de", "A"); err == nil {
+		t.Error("expected an invalid IDN to be rejected")
+	}
+}
+
+func TestClient_Query_ServerFailure(t *testing.T) {
+	addr := startTestServer(t, func(w mdns.ResponseWriter, r *mdns.Msg) {
+		m := new(mdns.Msg)
+		m.SetRcode(r, mdns.RcodeServerFailure)
+		_ = w.WriteMsg(m)
+	})
+
+	client := NewClient(5*time.Second, addr)
+
+	resp, err := client.Query(context.Background(), "example.com", "A")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	if resp.Rcode != "SERVFAIL" {
+		t.Errorf("expected rcode SERVFAIL, got %s", resp.Rcode)
+	}
+
+	if resp.EDNS != nil {
+		t.Errorf("expected no EDNS information, got %+v", resp.EDNS)
+	}
+}
+
+func TestParseAnswer_Data(t *testing.T) {
+	tests := []struct {
+		rr    string
+		value string
+		data  interface{}
+	}{
+		{
+			rr:    "example.com. 300 IN A 192.0.2.1",
+			value: "192.0.2.1",
+			data:  nil,
+		},
+		{
+			rr:    "example.com. 300 IN MX 10 mail.example.com.",
+			value: "10 mail.example.com.",
+			data:  &MXData{Preference: 10, Exchange: "mail.example.com."},
+		},
+		{
+			rr:    "example.com. 300 IN TXT \"v=spf1\" \"-all\"",
+			value: "v=spf1 -all",
+			data:  &TXTData{Strings: []string{"v=spf1", "-all"}},
+		},
+		{
+			rr:    "example.com. 300 IN SOA ns1.example.com. hostmaster.example.com. 2024010101 7200 3600 1209600 300",
+			value: "ns1.example.com. hostmaster.example.com. 2024010101 7200 3600 1209600 300",
+			data: &SOAData{
+				Mname:   "ns1.example.com.",
+				Rname:   "hostmaster.example.com.",
+				Serial:  2024010101,
+				Refresh: 7200,
+				Retry:   3600,
+				Expire:  1209600,
+				Minimum: 300,
+			},
+		},
+		{
+			rr:    "_sip._tcp.example.com. 300 IN SRV 10 60 5060 sip.example.com.",
+			value: "10 60 5060 sip.example.com.",
+			data:  &SRVData{Priority: 10, Weight: 60, Port: 5060, Target: "sip.example.com."},
+		},
+		{
+			rr:    "example.com. 300 IN CAA 0 issue \"letsencrypt.org\"",
+			value: "0 issue letsencrypt.org",
+			data:  &CAAData{Flag: 0, Tag: "issue", Value: "letsencrypt.org"},
+		},
+		{
+			rr:    "example.com. 300 IN DS 12345 13 2 abcdef",
+			value: "12345 13 2 ABCDEF",
+			data:  &DSData{KeyTag: 12345, Algorithm: 13, DigestType: 2, Digest: "ABCDEF"},
+		},
+		{
+			rr:    "example.com. 300 IN DNAME example.net.",
+			value: "example.net.",
+			data:  nil,
+		},
+		{
+			rr:    "example.com. 300 IN HTTPS 1 . alpn=h3,h2 ipv4hint=192.0.2.1",
+			value: "1 . alpn=\"h3,h2\" ipv4hint=\"192.0.2.1\"",
+			data: &SVCBData{
+				Priority: 1,
+				Target:   ".",
+				Params: []SVCBParam{
+					{Key: "alpn", Value: "h3,h2"},
+					{Key: "ipv4hint", Value: "192.0.2.1"},
+				},
+			},
+		},
+		{
+			rr:    "_443._tcp.example.com. 300 IN TLSA 3 1 1 0123456789abcdef",
+			value: "3 1 1 0123456789abcdef",
+			data:  &TLSAData{Usage: 3, Selector: 1, MatchingType: 1, Certificate: "0123456789abcdef"},
+		},
+		{
+			rr:    "example.com. 300 IN SSHFP 4 2 0123456789abcdef",
+			value: "4 2 0123456789ABCDEF",
+			data:  &SSHFPData{Algorithm: 4, Type: 2, Fingerprint: "0123456789ABCDEF"},
+		},
+		{
+			rr:    "example.com. 300 IN NAPTR 100 10 \"S\" \"SIP+D2U\" \"\" _sip._udp.example.com.",
+			value: "100 10 \"S\" \"SIP+D2U\" \"\" _sip._udp.example.com.",
+			data: &NAPTRData{
+				Order:       100,
+				Preference:  10,
+				Flags:       "S",
+				Service:     "SIP+D2U",
+				Replacement: "_sip._udp.example.com.",
+			},
+		},
+		{
+			rr:    "_http._tcp.example.com. 300 IN URI 10 1 \"https://www.example.com/\"",
+			value: "10 1 \"https://www.example.com/\"",
+			data:  &URIData{Priority: 10, Weight: 1, Target: "https://www.example.com/"},
+		},
+		{
+			rr:    "example.com. 300 IN TYPE65534 \\# 3 abcdef",
+			value: "\\# 3 abcdef",
+			data:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		record := parseAnswer(mustRR(t, tt.rr))
+		if record == nil {
+			t.Fatalf("parseAnswer(%q) returned nil", tt.rr)
+		}
+
+		if record.Value != tt.value {
+			t.Errorf("parseAnswer(%q).Value = %q, want %q", tt.rr, record.Value, tt.value)
+		}
+
+		if !reflect.DeepEqual(record.Data, tt.data) {
+			t.Errorf("parseAnswer(%q).Data = %#v, want %#v", tt.rr, record.Data, tt.data)
+		}
+	}
+}
+
+func TestParseAnswer_RRSIGData(t *testing.T) {
+	record := parseAnswer(mustRR(t, "example.com. 300 IN RRSIG A 13 2 300 20300101000000 20200101000000 12345 example.com. c2lnbmF0dXJl"))
+	if record == nil {
+		t.Fatal("parseAnswer returned nil")
+	}
+
+	data, ok := record.Data.(*RRSIGData)
+	if !ok {
+		t.Fatalf("expected *RRSIGData, got %T", record.Data)
+	}
+
+	if data.TypeCovered != "A" || data.KeyTag != 12345 || data.SignerName != "example.com." {
+		t.Errorf("unexpected RRSIG data: %+v", data)
+	}
+
+	if !data.Expiration.Equal(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected expiration: %v", data.Expiration)
+	}
+
+	if !data.Inception.Equal(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected inception: %v", data.Inception)
+	}
+}
+
+func TestParseAnswer_LOCData(t *testing.T) {
+	record := parseAnswer(mustRR(t, "example.com. 300 IN LOC 52 22 23.000 N 4 53 32.000 E -2.00m 10m 100m 10m"))
+	if record == nil {
+		t.Fatal("parseAnswer returned nil")
+	}
+
+	data, ok := record.Data.(*LOCData)
+	if !ok {
+		t.Fatalf("expected *LOCData, got %T", record.Data)
+	}
+
+	within := func(got, want float64) bool {
+		return math.Abs(got-want) < 0.0001
+	}
+
+	if !within(data.Latitude, 52+22.0/60+23.0/3600) || !within(data.Longitude, 4+53.0/60+32.0/3600) {
+		t.Errorf("unexpected coordinates: %f, %f", data.Latitude, data.Longitude)
+	}
+
+	if !within(data.Altitude, -2) || !within(data.Size, 10) || !within(data.HorizontalPrecision, 100) || !within(data.VerticalPrecision, 10) {
+		t.Errorf("unexpected altitude or precision: %+v", data)
+	}
+}
+
+func TestParseRecordType(t *testing.T) {
+	for _, recordType := range SupportedRecordTypes() {
+		if _, err := parseRecordType(strings.ToLower(recordType)); err != nil {
+			t.Errorf("parseRecordType(%q) failed: %v", recordType, err)
+		}
+	}
+
+	qtype, err := parseRecordType("TYPE65534")
+	if err != nil || qtype != 65534 {
+		t.Errorf("parseRecordType(TYPE65534) = %d, %v", qtype, err)
+	}
+
+	for _, recordType := range []string{"INVALID", "TYPE", "TYPE70000"} {
+		if _, err := parseRecordType(recordType); err == nil {
+			t.Errorf("expected error for %q", recordType)
+		}
+	}
+}
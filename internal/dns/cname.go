@@ -0,0 +1,124 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	mdns "github.com/miekg/dns"
+)
+
+// maxCNAMEChain is the longest CNAME chain followed before giving up.
+const maxCNAMEChain = 8
+
+// CNAME chain statuses.
+const (
+	ChainOK       = "ok"
+	ChainLoop     = "loop"
+	ChainTooLong  = "too-long"
+	ChainDangling = "dangling"
+	ChainFailed   = "failed"
+)
+
+// cnameChain reconstructs the CNAME chain starting at the queried name from
+// the answer of resp. When the answer stops at a CNAME whose target is not in
+// it, the chain is followed with further queries. It returns nil when the
+// queried name is not an alias.
+func (c *Client) cnameChain(ctx context.Context, resp *Response, qtype uint16) *CNAMEChain {
+	if qtype == mdns.TypeCNAME {
+		return nil
+	}
+
+	name := mdns.CanonicalName(resp.Domain)
+	if _, _, ok := findCNAME(resp.Records, name); !ok {
+		return nil
+	}
+
+	chain := &CNAMEChain{
+		Hops:    make([]CNAMEHop, 0),
+		Records: make([]Record, 0),
+	}
+
+	records := resp.Records
+	rcode := resp.Rcode
+	seen := map[string]bool{name: true}
+
+	for {
+		target, ttl, ok := findCNAME(records, name)
+		if !ok {
+			break
+		}
+
+		chain.Hops = append(chain.Hops, CNAMEHop{Name: name, Target: target, TTL: ttl})
+		name = target
+
+		if seen[name] {
+			chain.Status = ChainLoop
+			chain.Error = fmt.Sprintf("CNAME loop: %s points back to %s", chain.Hops[len(chain.Hops)-1].Name, name)
+			break
+		}
+		seen[name] = true
+
+		if len(chain.Hops) > maxCNAMEChain {
+			chain.Status = ChainTooLong
+			chain.Error = fmt.Sprintf("CNAME chain is longer than %d hops", maxCNAMEChain)
+			break
+		}
+
+		// An NXDOMAIN rcode applies to the last name of the chain in the
+		// answer (RFC 6604), so the target is already known not to exist.
+		if hasOwner(records, name) || rcode == rcodeString(mdns.RcodeNameError) {
+			continue
+		}
+
+		slog.Debug("following CNAME chain", "name", name, "nameserver", c.nameserver)
+		next, err := c.query(ctx, name, qtype, resp.RecordType)
+		if err != nil {
+			chain.Status = ChainFailed
+			chain.Error = fmt.Sprintf("failed to resolve %s: %v", name, err)
+			break
+		}
+		records = next.Records
+		rcode = next.Rcode
+	}
+
+	chain.Target = name
+	chain.Rcode = rcode
+
+	if chain.Status != "" {
+		return chain
+	}
+
+	for _, record := range records {
+		if mdns.CanonicalName(record.Name) == name && record.Type != "CNAME" {
+			chain.Records = append(chain.Records, record)
+		}
+	}
+
+	chain.Status = ChainOK
+	if rcode == rcodeString(mdns.RcodeNameError) {
+		chain.Status = ChainDangling
+		chain.Error = fmt.Sprintf("CNAME target %s does not exist (NXDOMAIN)", name)
+	}
+
+	return chain
+}
+
+// findCNAME returns the target and TTL of the CNAME record owned by name.
+func findCNAME(records []Record, name string) (string, uint32, bool) {
+	for _, record := range records {
+		if record.Type == "CNAME" && mdns.CanonicalName(record.Name) == name {
+			return mdns.CanonicalName(record.Value), record.TTL, true
+		}
+	}
+	return "", 0, false
+}
+
+func hasOwner(records []Record, name string) bool {
+	for _, record := range records {
+		if mdns.CanonicalName(record.Name) == name {
+			return true
+		}
+	}
+	return false
+}
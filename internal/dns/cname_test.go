@@ -0,0 +1,235 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	mdns "github.com/miekg/dns"
+)
+
+// aliasHandler answers like an authoritative server that does not chase
+// aliases: a name owning a CNAME gets only that CNAME, and names without
+// records get NXDOMAIN.
+func aliasHandler(t *testing.T, records ...string) mdns.HandlerFunc {
+	rrs := make([]mdns.RR, 0, len(records))
+	for _, record := range records {
+		rrs = append(rrs, mustRR(t, record))
+	}
+
+	return func(w mdns.ResponseWriter, r *mdns.Msg) {
+		m := new(mdns.Msg)
+		m.SetReply(r)
+
+		q := r.Question[0]
+		exists := false
+		for _, rr := range rrs {
+			header := rr.Header()
+			if mdns.CanonicalName(header.Name) != mdns.CanonicalName(q.Name) {
+				continue
+			}
+			exists = true
+			if header.Rrtype == q.Qtype || header.Rrtype == mdns.TypeCNAME {
+				m.Answer = append(m.Answer, rr)
+			}
+		}
+
+		if !exists {
+			m.Rcode = mdns.RcodeNameError
+		}
+
+		_ = w.WriteMsg(m)
+	}
+}
+
+func TestClient_Query_CNAMEChain(t *testing.T) {
+	addr := startTestServer(t, func(w mdns.ResponseWriter, r *mdns.Msg) {
+		m := new(mdns.Msg)
+		m.SetReply(r)
+		m.Answer = append(m.Answer,
+			mustRR(t, "www.example.com. 300 IN CNAME cdn.example.net."),
+			mustRR(t, "cdn.example.net. 60 IN CNAME edge.example.org."),
+			mustRR(t, "edge.example.org. 20 IN A 192.0.2.1"),
+			mustRR(t, "edge.example.org. 20 IN A 192.0.2.2"),
+		)
+		_ = w.WriteMsg(m)
+	})
+
+	client := NewClient(time.Second, addr)
+
+	resp, err := client.Query(context.Background(), "www.example.com", "A")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	chain := resp.Chain
+	if chain == nil {
+		t.Fatal("expected a CNAME chain")
+	}
+
+	if chain.Status != ChainOK {
+		t.Errorf("expected status %s, got %s (%s)", ChainOK, chain.Status, chain.Error)
+	}
+
+	expected := []CNAMEHop{
+		{Name: "www.example.com.", Target: "cdn.example.net.", TTL: 300},
+		{Name: "cdn.example.net.", Target: "edge.example.org.", TTL: 60},
+	}
+	if len(chain.Hops) != len(expected) {
+		t.Fatalf("expected %d hops, got %+v", len(expected), chain.Hops)
+	}
+	for i, hop := range expected {
+		if chain.Hops[i] != hop {
+			t.Errorf("hop %d: expected %+v, got %+v", i, hop, chain.Hops[i])
+		}
+	}
+
+	if chain.Target != "edge.example.org." || len(chain.Records) != 2 {
+		t.Errorf("expected 2 records for edge.example.org., got %s %+v", chain.Target, chain.Records)
+	}
+}
+
+func TestClient_Query_CNAMEChainFollowed(t *testing.T) {
+	addr := startTestServer(t, aliasHandler(t,
+		"www.example.com. 300 IN CNAME cdn.example.net.",
+		"cdn.example.net. 60 IN CNAME edge.example.org.",
+		"edge.example.org. 20 IN A 192.0.2.1",
+	))
+
+	client := NewClient(time.Second, addr)
+
+	resp, err := client.Query(context.Background(), "www.example.com", "A")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	chain := resp.Chain
+	if chain == nil {
+		t.Fatal("expected a CNAME chain")
+	}
+
+	if chain.Status != ChainOK || len(chain.Hops) != 2 {
+		t.Errorf("expected a complete chain of 2 hops, got %s %+v", chain.Status, chain.Hops)
+	}
+
+	if len(chain.Records) != 1 || chain.Records[0].Value != "192.0.2.1" {
+		t.Errorf("expected the final A record, got %+v", chain.Records)
+	}
+}
+
+func TestClient_Query_CNAMEChainDangling(t *testing.T) {
+	addr := startTestServer(t, aliasHandler(t,
+		"shop.example.com. 300 IN CNAME example-shop.azurewebsites.test.",
+	))
+
+	client := NewClient(time.Second, addr)
+
+	resp, err := client.Query(context.Background(), "shop.example.com", "A")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	chain := resp.Chain
+	if chain == nil {
+		t.Fatal("expected a CNAME chain")
+	}
+
+	if chain.Status != ChainDangling {
+		t.Errorf("expected status %s, got %s", ChainDangling, chain.Status)
+	}
+	if chain.Target != "example-shop.azurewebsites.test." || chain.Rcode != "NXDOMAIN" {
+		t.Errorf("unexpected target: %s (%s)", chain.Target, chain.Rcode)
+	}
+}
+
+func TestClient_Query_CNAMEChainDanglingInAnswer(t *testing.T) {
+	var queries atomic.Int32
+	addr := startTestServer(t, func(w mdns.ResponseWriter, r *mdns.Msg) {
+		queries.Add(1)
+		m := new(mdns.Msg)
+		m.SetRcode(r, mdns.RcodeNameError)
+		m.Answer = append(m.Answer, mustRR(t, "shop.example.com. 300 IN CNAME gone.example.net."))
+		_ = w.WriteMsg(m)
+	})
+
+	client := NewClient(time.Second, addr)
+
+	resp, err := client.Query(context.Background(), "shop.example.com", "A")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	if resp.Chain == nil || resp.Chain.Status != ChainDangling {
+		t.Fatalf("expected a dangling chain, got %+v", resp.Chain)
+	}
+	if n := queries.Load(); n != 1 {
+		t.Errorf("expected the NXDOMAIN answer not to be followed, got %d queries", n)
+	}
+}
+
+func TestClient_Query_CNAMEChainLoop(t *testing.T) {
+	addr := startTestServer(t, aliasHandler(t,
+		"a.example.com. 300 IN CNAME b.example.com.",
+		"b.example.com. 300 IN CNAME a.example.com.",
+	))
+
+	client := NewClient(time.Second, addr)
+
+	resp, err := client.Query(context.Background(), "a.example.com", "A")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	if resp.Chain == nil || resp.Chain.Status != ChainLoop {
+		t.Fatalf("expected a CNAME loop, got %+v", resp.Chain)
+	}
+	if len(resp.Chain.Hops) != 2 {
+		t.Errorf("expected 2 hops, got %+v", resp.Chain.Hops)
+	}
+}
+
+func TestClient_Query_CNAMEChainTooLong(t *testing.T) {
+	records := make([]string, 0, maxCNAMEChain+2)
+	for i := 0; i <= maxCNAMEChain+1; i++ {
+		records = append(records, fmt.Sprintf("h%d.example.com. 300 IN CNAME h%d.example.com.", i, i+1))
+	}
+	addr := startTestServer(t, aliasHandler(t, records...))
+
+	client := NewClient(time.Second, addr)
+
+	resp, err := client.Query(context.Background(), "h0.example.com", "A")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	if resp.Chain == nil || resp.Chain.Status != ChainTooLong {
+		t.Fatalf("expected a too long chain, got %+v", resp.Chain)
+	}
+}
+
+func TestClient_Query_NoCNAMEChain(t *testing.T) {
+	addr := startTestServer(t, zoneHandler(
+		"www.example.com. 300 IN CNAME cdn.example.net.",
+		"example.com. 300 IN A 192.0.2.1",
+	))
+
+	client := NewClient(time.Second, addr)
+
+	resp, err := client.Query(context.Background(), "example.com", "A")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if resp.Chain != nil {
+		t.Errorf("expected no chain for a name that is not an alias, got %+v", resp.Chain)
+	}
+
+	resp, err = client.Query(context.Background(), "www.example.com", "CNAME")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if resp.Chain != nil {
+		t.Errorf("expected no chain for CNAME queries, got %+v", resp.Chain)
+	}
+}
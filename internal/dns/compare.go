@@ -0,0 +1,180 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	mdns "github.com/miekg/dns"
+
+	"watchr/internal/idn"
+)
+
+// ResolverSets are named sets of public resolvers that can be compared with
+// each other.
+var ResolverSets = map[string][]string{
+	"public": {
+		"8.8.8.8",
+		"1.1.1.1",
+		"9.9.9.9",
+		"208.67.222.222",
+	},
+	"google": {
+		"8.8.8.8",
+		"8.8.4.4",
+	},
+	"cloudflare": {
+		"1.1.1.1",
+		"1.0.0.1",
+	},
+	"quad9": {
+		"9.9.9.9",
+		"149.112.112.112",
+	},
+	"opendns": {
+		"208.67.222.222",
+		"208.67.220.220",
+	},
+	"dot": {
+		"tls://8.8.8.8#dns.google",
+		"tls://1.1.1.1#cloudflare-dns.com",
+		"tls://9.9.9.9#dns.quad9.net",
+	},
+	"doh": {
+		"https://dns.google/dns-query",
+		"https://cloudflare-dns.com/dns-query",
+		"https://dns.quad9.net/dns-query",
+	},
+}
+
+// ResolverSet returns the nameservers of the named resolver set.
+func ResolverSet(name string) ([]string, error) {
+	servers, ok := ResolverSets[strings.ToLower(name)]
+	if !ok {
+		names := make([]string, 0, len(ResolverSets))
+		for setName := range ResolverSets {
+			names = append(names, setName)
+		}
+		sort.Strings(names)
+
+		return nil, fmt.Errorf("unknown resolver set %q (available: %s)", name, strings.Join(names, ", "))
+	}
+
+	return servers, nil
+}
+
+// Compare sends the same query to every nameserver concurrently, using the
+// options the client was created with, and groups the nameservers by the
+// answer they returned. TTLs are ignored when comparing answers, as they
+// naturally differ between caches.
+func (c *Client) Compare(ctx context.Context, domain string, recordType string, nameservers []string) (*CompareResponse, error) {
+	if _, err := parseRecordType(recordType); err != nil {
+		return nil, err
+	}
+
+	if len(nameservers) == 0 {
+		return nil, fmt.Errorf("no nameservers to compare")
+	}
+
+	name, err := idn.Parse(mdns.Fqdn(domain))
+	if err != nil {
+		return nil, err
+	}
+	domain = name.ASCII
+
+	response := &CompareResponse{
+		Domain:     domain,
+		IDN:        name,
+		RecordType: strings.ToUpper(recordType),
+		Results:    make([]CompareResult, len(nameservers)),
+		Answers:    make([]CompareAnswer, 0),
+	}
+
+	slog.Debug("comparing DNS answers", "domain", domain, "type", recordType, "nameservers", nameservers)
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for i, nameserver := range nameservers {
+		wg.Add(1)
+		go func(i int, nameserver string) {
+			defer wg.Done()
+
+			client := NewClient(c.timeout, nameserver, c.opts...)
+			result := CompareResult{
+				Nameserver: client.nameserver,
+				Records:    make([]Record, 0),
+			}
+
+			resp, err := client.Query(ctx, domain, recordType)
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Rcode = resp.Rcode
+				result.Records = resp.Records
+				result.QueryTime = resp.QueryTime
+			}
+
+			response.Results[i] = result
+		}(i, nameserver)
+	}
+
+	wg.Wait()
+	response.QueryTime = time.Since(start)
+
+	groups := make(map[string]int)
+	for i, result := range response.Results {
+		if result.Error != "" {
+			continue
+		}
+
+		values := answerValues(result.Records)
+		key := result.Rcode + "\x00" + strings.Join(values, "\x00")
+
+		index, ok := groups[key]
+		if !ok {
+			index = len(response.Answers)
+			groups[key] = index
+			response.Answers = append(response.Answers, CompareAnswer{
+				Rcode:  result.Rcode,
+				Values: values,
+			})
+		}
+		response.Answers[index].Nameservers = append(response.Answers[index].Nameservers, response.Results[i].Nameserver)
+	}
+
+	// The answer returned by most nameservers comes first and is the one the
+	// others are compared against.
+	sort.SliceStable(response.Answers, func(i, j int) bool {
+		return len(response.Answers[i].Nameservers) > len(response.Answers[j].Nameservers)
+	})
+
+	if len(response.Answers) > 0 {
+		majority := make(map[string]bool)
+		for _, nameserver := range response.Answers[0].Nameservers {
+			majority[nameserver] = true
+		}
+		for i := range response.Results {
+			response.Results[i].Agrees = majority[response.Results[i].Nameserver]
+		}
+	}
+
+	response.Consistent = len(response.Answers) == 1 && len(response.Answers[0].Nameservers) == len(response.Results)
+
+	return response, nil
+}
+
+// answerValues returns the sorted "TYPE value" pairs of the records, so that
+// answers can be compared regardless of record order and TTL.
+func answerValues(records []Record) []string {
+	values := make([]string, 0, len(records))
+	for _, record := range records {
+		values = append(values, record.Type+" "+record.Value)
+	}
+	sort.Strings(values)
+
+	return values
+}
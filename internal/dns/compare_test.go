@@ -0,0 +1,134 @@
+package dns
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestClient_Compare(t *testing.T) {
+	current := zoneHandler("example.com. 300 IN A 192.0.2.2")
+	stale := zoneHandler("example.com. 3600 IN A 192.0.2.1")
+
+	first := startTestServer(t, current)
+	second := startTestServer(t, zoneHandler("example.com. 120 IN A 192.0.2.2"))
+	third := startTestServer(t, stale)
+
+	client := NewClient(5*time.Second, first)
+
+	resp, err := client.Compare(context.Background(), "example.com", "A", []string{first, second, third})
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+
+	if resp.Consistent {
+		t.Error("expected the answers to be inconsistent")
+	}
+
+	if len(resp.Results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(resp.Results))
+	}
+
+	// Results keep the order of the nameservers given.
+	for i, nameserver := range []string{first, second, third} {
+		if resp.Results[i].Nameserver != nameserver {
+			t.Errorf("expected result %d for %s, got %s", i, nameserver, resp.Results[i].Nameserver)
+		}
+	}
+
+	if !resp.Results[0].Agrees || !resp.Results[1].Agrees {
+		t.Error("expected the servers with the same answer but different TTLs to agree")
+	}
+
+	if resp.Results[2].Agrees {
+		t.Error("expected the stale server to disagree")
+	}
+
+	if len(resp.Answers) != 2 {
+		t.Fatalf("expected 2 distinct answers, got %d", len(resp.Answers))
+	}
+
+	if len(resp.Answers[0].Nameservers) != 2 || resp.Answers[0].Values[0] != "A 192.0.2.2" {
+		t.Errorf("expected the majority answer first, got %+v", resp.Answers[0])
+	}
+}
+
+func TestClient_Compare_Consistent(t *testing.T) {
+	handler := zoneHandler(
+		"example.com. 300 IN A 192.0.2.1",
+		"example.com. 300 IN A 192.0.2.2",
+	)
+
+	first := startTestServer(t, handler)
+	second := startTestServer(t, handler)
+
+	client := NewClient(5*time.Second, first)
+
+	resp, err := client.Compare(context.Background(), "example.com", "A", []string{first, second})
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+
+	if !resp.Consistent {
+		t.Errorf("expected the answers to be consistent, got %+v", resp.Answers)
+	}
+}
+
+func TestClient_Compare_IDN(t *testing.T) {
+	addr := startTestServer(t, zoneHandler("xn--bcher-kva.de. 300 IN A 192.0.2.1"))
+
+	client := NewClient(5*time.Second, addr)
+	resp, err := client.Compare(context.Background(), "bücher.de", "A", []string{addr})
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+
+	if resp.Domain != "xn--bcher-kva.de." || len(resp.Results[0].Records) != 1 {
+		t.Errorf("expected the A-label to be queried, got %s with %+v", resp.Domain, resp.Results)
+	}
+	if resp.IDN == nil || resp.IDN.Unicode != "bücher.de." {
+		t.Errorf("expected the U-label in the response, got %+v", resp.IDN)
+	}
+}
+
+func TestClient_Compare_UnreachableServer(t *testing.T) {
+	first := startTestServer(t, zoneHandler("example.com. 300 IN A 192.0.2.1"))
+
+	client := NewClient(500*time.Millisecond, first)
+
+	resp, err := client.Compare(context.Background(), "example.com", "A", []string{first, "127.0.0.1:1"})
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+
+	if resp.Consistent {
+		t.Error("expected the comparison not to be consistent when a server fails")
+	}
+
+	if resp.Results[1].Error == "" || resp.Results[1].Agrees {
+		t.Errorf("expected an error for the unreachable server, got %+v", resp.Results[1])
+	}
+}
+
+func TestClient_Compare_InvalidRecordType(t *testing.T) {
+	client := NewClient(5*time.Second, "127.0.0.1:53")
+
+	if _, err := client.Compare(context.Background(), "example.com", "INVALID", []string{"127.0.0.1:53"}); err == nil {
+		t.Error("expected error for invalid record type")
+	}
+}
+
+func TestResolverSet(t *testing.T) {
+	servers, err := ResolverSet("Public")
+	if err != nil {
+		t.Fatalf("ResolverSet failed: %v", err)
+	}
+
+	if len(servers) == 0 {
+		t.Error("expected the public set to contain servers")
+	}
+
+	if _, err := ResolverSet("unknown"); err == nil {
+		t.Error("expected error for an unknown set")
+	}
+}
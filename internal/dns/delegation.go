@@ -0,0 +1,348 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	mdns "github.com/miekg/dns"
+
+	"watchr/internal/idn"
+)
+
+// CheckDelegation compares the delegation of domain published by its parent
+// zone, the NS set and glue in the referral, with the NS set and addresses
+// served by the child zone itself. When registryNS is not empty, the
+// nameservers registered for the domain (e.g. from RDAP) are compared with the
+// parent NS set as well.
+func (c *Client) CheckDelegation(ctx context.Context, domain string, registryNS []string) (*DelegationResponse, error) {
+	name, err := idn.Parse(mdns.CanonicalName(domain))
+	if err != nil {
+		return nil, err
+	}
+	domain = name.ASCII
+
+	slog.Debug("checking delegation", "domain", domain)
+	start := time.Now()
+
+	response := &DelegationResponse{
+		Domain:      domain,
+		IDN:         name,
+		Nameserver:  c.nameserver,
+		ParentNS:    make([]string, 0),
+		ChildNS:     make([]string, 0),
+		Nameservers: make([]DelegationNameserver, 0),
+		Issues:      make([]string, 0),
+	}
+
+	for _, ns := range registryNS {
+		response.RegistryNS = append(response.RegistryNS, mdns.CanonicalName(ns))
+	}
+	sort.Strings(response.RegistryNS)
+
+	referral, err := c.parentReferral(ctx, domain, response)
+	if err != nil {
+		return nil, err
+	}
+
+	glue := make(map[string][]string)
+	for _, ns := range delegationRecords(referral, domain) {
+		response.ParentNS = append(response.ParentNS, mdns.CanonicalName(ns.Ns))
+	}
+	for _, rr := range referral.Extra {
+		switch glueRR := rr.(type) {
+		case *mdns.A:
+			name := mdns.CanonicalName(glueRR.Hdr.Name)
+			glue[name] = append(glue[name], glueRR.A.String())
+		case *mdns.AAAA:
+			name := mdns.CanonicalName(glueRR.Hdr.Name)
+			glue[name] = append(glue[name], glueRR.AAAA.String())
+		}
+	}
+	sort.Strings(response.ParentNS)
+
+	childNS, childServer, err := c.childNS(ctx, domain, response.ParentNS, glue)
+	if err != nil {
+		response.Issues = append(response.Issues, err.Error())
+	} else {
+		response.ChildNS = childNS
+		response.ChildServer = childServer
+	}
+
+	names := make(map[string]bool)
+	for _, list := range [][]string{response.ParentNS, response.ChildNS, response.RegistryNS} {
+		for _, name := range list {
+			names[name] = true
+		}
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		ns := DelegationNameserver{
+			Name:        name,
+			InParent:    containsString(response.ParentNS, name),
+			InChild:     containsString(response.ChildNS, name),
+			InRegistry:  containsString(response.RegistryNS, name),
+			InBailiwick: mdns.IsSubDomain(domain, name),
+			Glue:        glue[name],
+			Addresses:   make([]string, 0),
+		}
+		sort.Strings(ns.Glue)
+
+		if ns.InBailiwick && childServer != "" {
+			ns.Addresses = c.childAddresses(ctx, childServer, name)
+		} else if !ns.InBailiwick {
+			ns.Addresses = c.resolveAddresses(ctx, name)
+		}
+
+		if ns.Glue == nil {
+			ns.Glue = make([]string, 0)
+		}
+
+		checkDelegationNameserver(&ns, response)
+		response.Nameservers = append(response.Nameservers, ns)
+		response.Issues = append(response.Issues, ns.Issues...)
+	}
+
+	response.Consistent = len(response.Issues) == 0
+	response.QueryTime = time.Since(start)
+
+	return response, nil
+}
+
+// checkDelegationNameserver records the issues found for a single nameserver.
+func checkDelegationNameserver(ns *DelegationNameserver, response *DelegationResponse) {
+	if ns.InParent && !ns.InChild && len(response.ChildNS) > 0 {
+		ns.Issues = append(ns.Issues, fmt.Sprintf("%s is delegated by the parent but missing from the child NS set", ns.Name))
+	}
+	if ns.InChild && !ns.InParent {
+		ns.Issues = append(ns.Issues, fmt.Sprintf("%s is in the child NS set but not delegated by the parent", ns.Name))
+	}
+	if len(response.RegistryNS) > 0 && ns.InRegistry != ns.InParent {
+		if ns.InRegistry {
+			ns.Issues = append(ns.Issues, fmt.Sprintf("%s is registered but not published by the parent", ns.Name))
+		} else if ns.InParent {
+			ns.Issues = append(ns.Issues, fmt.Sprintf("%s is published by the parent but not registered", ns.Name))
+		}
+	}
+
+	if !ns.InParent || !ns.InBailiwick {
+		return
+	}
+
+	if len(ns.Glue) == 0 {
+		ns.Issues = append(ns.Issues, fmt.Sprintf("%s is in-bailiwick but the parent has no glue for it", ns.Name))
+		return
+	}
+
+	if response.ChildServer == "" {
+		return
+	}
+
+	for _, address := range ns.Glue {
+		if !containsIP(ns.Addresses, address) {
+			ns.Issues = append(ns.Issues, fmt.Sprintf("stale glue for %s: %s is not served by the child zone", ns.Name, address))
+		}
+	}
+	for _, address := range ns.Addresses {
+		if !containsIP(ns.Glue, address) {
+			ns.Issues = append(ns.Issues, fmt.Sprintf("missing glue for %s: %s is served by the child zone but not by the parent", ns.Name, address))
+		}
+	}
+}
+
+// parentReferral finds the parent zone of domain through the configured
+// resolver and asks its servers, without recursion, for the NS records of
+// domain, returning the first reply that holds them. Servers that fail or do
+// not return the delegation are skipped.
+func (c *Client) parentReferral(ctx context.Context, domain string, response *DelegationResponse) (*mdns.Msg, error) {
+	labels := mdns.SplitDomainName(domain)
+	if len(labels) == 0 {
+		return nil, fmt.Errorf("the root zone has no parent")
+	}
+
+	failures := make([]string, 0)
+	for i := 1; i <= len(labels); i++ {
+		parent := mdns.Fqdn(strings.Join(labels[i:], "."))
+
+		resp, err := c.Query(ctx, parent, "NS")
+		if err != nil {
+			return nil, err
+		}
+
+		nameservers := make([]string, 0)
+		for _, record := range resp.Records {
+			if record.Type == "NS" && mdns.CanonicalName(record.Name) == mdns.CanonicalName(parent) {
+				nameservers = append(nameservers, record.Value)
+			}
+		}
+		if len(nameservers) == 0 {
+			continue
+		}
+
+		response.ParentZone = mdns.CanonicalName(parent)
+		for _, name := range nameservers {
+			for _, address := range c.resolveAddresses(ctx, name) {
+				address = net.JoinHostPort(address, c.port)
+
+				r, err := c.queryAuthoritative(ctx, address, domain, mdns.TypeNS)
+				if err != nil {
+					failures = append(failures, fmt.Sprintf("%s (%s): %v", name, address, err))
+					continue
+				}
+				if r.Rcode != mdns.RcodeSuccess {
+					failures = append(failures, fmt.Sprintf("%s (%s): answered %s", name, address, rcodeString(r.Rcode)))
+					continue
+				}
+				if len(delegationRecords(r, domain)) == 0 {
+					failures = append(failures, fmt.Sprintf("%s (%s): did not return a delegation", name, address))
+					continue
+				}
+
+				response.ParentServer = mdns.CanonicalName(name)
+				response.ParentAddress = address
+				return r, nil
+			}
+		}
+
+		return nil, fmt.Errorf("no server for %s returned the delegation of %s: %s", parent, domain, strings.Join(failures, "; "))
+	}
+
+	return nil, fmt.Errorf("could not find the parent zone of %s", domain)
+}
+
+// delegationRecords returns the NS records of domain in a reply from a parent
+// server: the referral in the authority section or, when the server is
+// authoritative for domain as well, the answer section.
+func delegationRecords(r *mdns.Msg, domain string) []*mdns.NS {
+	sections := [][]mdns.RR{r.Ns}
+	if r.Authoritative {
+		sections = append(sections, r.Answer)
+	}
+
+	for _, section := range sections {
+		records := make([]*mdns.NS, 0)
+		for _, rr := range section {
+			if ns, ok := rr.(*mdns.NS); ok && mdns.CanonicalName(ns.Hdr.Name) == domain {
+				records = append(records, ns)
+			}
+		}
+		if len(records) > 0 {
+			return records
+		}
+	}
+
+	return nil
+}
+
+// childNS asks the delegated servers, without recursion, for the NS set of
+// domain and returns the one from the first authoritative answer, along with
+// the address of the server that gave it.
+func (c *Client) childNS(ctx context.Context, domain string, parentNS []string, glue map[string][]string) ([]string, string, error) {
+	failures := make([]string, 0)
+	for _, name := range parentNS {
+		addresses := glue[name]
+		if len(addresses) == 0 {
+			addresses = c.resolveAddresses(ctx, name)
+		}
+
+		for _, address := range addresses {
+			address = net.JoinHostPort(address, c.port)
+
+			r, err := c.queryAuthoritative(ctx, address, domain, mdns.TypeNS)
+			if err != nil {
+				failures = append(failures, fmt.Sprintf("%s (%s): %v", name, address, err))
+				continue
+			}
+			if !r.Authoritative || r.Rcode != mdns.RcodeSuccess {
+				failures = append(failures, fmt.Sprintf("%s (%s): lame (%s, aa=%t)", name, address, rcodeString(r.Rcode), r.Authoritative))
+				continue
+			}
+
+			nameservers := make([]string, 0)
+			for _, rr := range r.Answer {
+				if ns, ok := rr.(*mdns.NS); ok {
+					nameservers = append(nameservers, mdns.CanonicalName(ns.Ns))
+				}
+			}
+			sort.Strings(nameservers)
+
+			return nameservers, address, nil
+		}
+	}
+
+	return nil, "", fmt.Errorf("no delegated server answered authoritatively for %s: %s", domain, strings.Join(failures, "; "))
+}
+
+// childAddresses asks the child server at address for the IPv4 and IPv6
+// addresses of name.
+func (c *Client) childAddresses(ctx context.Context, address string, name string) []string {
+	addresses := make([]string, 0)
+	for _, qtype := range []uint16{mdns.TypeA, mdns.TypeAAAA} {
+		r, err := c.queryAuthoritative(ctx, address, name, qtype)
+		if err != nil {
+			slog.Debug("failed to query child zone", "name", name, "server", address, "error", err)
+			continue
+		}
+		for _, rr := range r.Answer {
+			switch rr := rr.(type) {
+			case *mdns.A:
+				addresses = append(addresses, rr.A.String())
+			case *mdns.AAAA:
+				addresses = append(addresses, rr.AAAA.String())
+			}
+		}
+	}
+	sort.Strings(addresses)
+
+	return addresses
+}
+
+// resolveAddresses resolves the IPv4 and IPv6 addresses of name through the
+// configured resolver.
+func (c *Client) resolveAddresses(ctx context.Context, name string) []string {
+	addresses := make([]string, 0)
+	for _, recordType := range []string{"A", "AAAA"} {
+		resp, err := c.Query(ctx, name, recordType)
+		if err != nil {
+			slog.Debug("failed to resolve nameserver", "nameserver", name, "type", recordType, "error", err)
+			continue
+		}
+		for _, record := range resp.Records {
+			if record.Type == recordType {
+				addresses = append(addresses, record.Value)
+			}
+		}
+	}
+	sort.Strings(addresses)
+
+	return addresses
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsIP(addresses []string, address string) bool {
+	ip := net.ParseIP(address)
+	for _, a := range addresses {
+		if net.ParseIP(a).Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
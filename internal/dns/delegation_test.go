@@ -0,0 +1,168 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	mdns "github.com/miekg/dns"
+)
+
+// startDelegationServers runs a resolver, a parent server for test. and a
+// child server for example.test. on loopback addresses sharing the same port,
+// and returns the resolver address.
+func startDelegationServers(t *testing.T) string {
+	t.Helper()
+
+	resolver := zoneHandler(
+		"test. 3600 IN NS ns.test.",
+		"ns.test. 3600 IN A 127.0.0.2",
+	)
+
+	parent := func(w mdns.ResponseWriter, r *mdns.Msg) {
+		m := new(mdns.Msg)
+		m.SetReply(r)
+		m.Ns = append(m.Ns,
+			mustRR(t, "example.test. 86400 IN NS ns1.example.test."),
+			mustRR(t, "example.test. 86400 IN NS ns2.example.test."),
+			mustRR(t, "example.test. 86400 IN NS ns3.example.test."),
+		)
+		m.Extra = append(m.Extra,
+			mustRR(t, "ns1.example.test. 86400 IN A 127.0.0.3"),
+			mustRR(t, "ns2.example.test. 86400 IN A 127.0.0.9"),
+		)
+		_ = w.WriteMsg(m)
+	}
+
+	child := zoneHandler(
+		"example.test. 3600 IN NS ns1.example.test.",
+		"example.test. 3600 IN NS ns2.example.test.",
+		"example.test. 3600 IN NS ns4.example.test.",
+		"ns1.example.test. 3600 IN A 127.0.0.3",
+		"ns1.example.test. 3600 IN AAAA ::1",
+		"ns2.example.test. 3600 IN A 127.0.0.4",
+	)
+	authoritative := func(w mdns.ResponseWriter, r *mdns.Msg) {
+		child(&authoritativeWriter{w}, r)
+	}
+
+	addr := startTestServer(t, resolver)
+	_, port, _ := net.SplitHostPort(addr)
+
+	startTestServerAt(t, net.JoinHostPort("127.0.0.2", port), parent)
+	startTestServerAt(t, net.JoinHostPort("127.0.0.3", port), authoritative)
+
+	return addr
+}
+
+// authoritativeWriter sets the AA bit on every message written.
+type authoritativeWriter struct {
+	mdns.ResponseWriter
+}
+
+func (w *authoritativeWriter) WriteMsg(m *mdns.Msg) error {
+	m.Authoritative = true
+	return w.ResponseWriter.WriteMsg(m)
+}
+
+func TestClient_CheckDelegation(t *testing.T) {
+	addr := startDelegationServers(t)
+	_, port, _ := net.SplitHostPort(addr)
+
+	client := NewClient(time.Second, addr)
+	client.port = port
+
+	resp, err := client.CheckDelegation(context.Background(), "example.test", []string{
+		"ns1.example.test", "ns2.example.test", "ns3.example.test", "ns5.example.test",
+	})
+	if err != nil {
+		t.Fatalf("CheckDelegation failed: %v", err)
+	}
+
+	if resp.ParentZone != "test." || resp.ParentServer != "ns.test." {
+		t.Errorf("unexpected parent: %s %s", resp.ParentZone, resp.ParentServer)
+	}
+
+	if resp.Consistent {
+		t.Error("expected the delegation not to be consistent")
+	}
+
+	if strings.Join(resp.ParentNS, " ") != "ns1.example.test. ns2.example.test. ns3.example.test." {
+		t.Errorf("unexpected parent NS set: %v", resp.ParentNS)
+	}
+
+	if strings.Join(resp.ChildNS, " ") != "ns1.example.test. ns2.example.test. ns4.example.test." {
+		t.Errorf("unexpected child NS set: %v", resp.ChildNS)
+	}
+
+	issues := strings.Join(resp.Issues, "\n")
+	for _, expected := range []string{
+		"missing glue for ns1.example.test.: ::1",
+		"stale glue for ns2.example.test.: 127.0.0.9",
+		"missing glue for ns2.example.test.: 127.0.0.4",
+		"ns3.example.test. is in-bailiwick but the parent has no glue",
+		"ns3.example.test. is delegated by the parent but missing from the child NS set",
+		"ns4.example.test. is in the child NS set but not delegated by the parent",
+		"ns5.example.test. is registered but not published by the parent",
+	} {
+		if !strings.Contains(issues, expected) {
+			t.Errorf("expected issue %q, got:\n%s", expected, issues)
+		}
+	}
+
+	if len(resp.Nameservers) != 5 {
+		t.Errorf("expected 5 nameservers, got %d", len(resp.Nameservers))
+	}
+}
+
+func TestClient_CheckDelegation_ParentFallback(t *testing.T) {
+	// 127.0.0.10 sorts first and refuses; 127.0.0.2 serves both the parent
+	// and the child zone, so it answers authoritatively instead of referring.
+	addr := startTestServer(t, zoneHandler(
+		"test. 3600 IN NS ns.test.",
+		"ns.test. 3600 IN A 127.0.0.2",
+		"ns.test. 3600 IN A 127.0.0.10",
+		"ns1.example.test. 3600 IN A 127.0.0.2",
+	))
+	_, port, _ := net.SplitHostPort(addr)
+
+	startTestServerAt(t, net.JoinHostPort("127.0.0.10", port), func(w mdns.ResponseWriter, r *mdns.Msg) {
+		m := new(mdns.Msg)
+		m.SetRcode(r, mdns.RcodeRefused)
+		_ = w.WriteMsg(m)
+	})
+	zone := zoneHandler(
+		"example.test. 3600 IN NS ns1.example.test.",
+		"ns1.example.test. 3600 IN A 127.0.0.2",
+	)
+	startTestServerAt(t, net.JoinHostPort("127.0.0.2", port), func(w mdns.ResponseWriter, r *mdns.Msg) {
+		zone(&authoritativeWriter{w}, r)
+	})
+
+	client := NewClient(time.Second, addr)
+	client.port = port
+
+	resp, err := client.CheckDelegation(context.Background(), "example.test", nil)
+	if err != nil {
+		t.Fatalf("CheckDelegation failed: %v", err)
+	}
+
+	if resp.ParentAddress != net.JoinHostPort("127.0.0.2", port) {
+		t.Errorf("expected the delegation from 127.0.0.2, got %s", resp.ParentAddress)
+	}
+	if strings.Join(resp.ParentNS, " ") != "ns1.example.test." || strings.Join(resp.ChildNS, " ") != "ns1.example.test." {
+		t.Errorf("unexpected NS sets: parent %v, child %v", resp.ParentNS, resp.ChildNS)
+	}
+}
+
+func TestClient_CheckDelegation_NoParent(t *testing.T) {
+	addr := startTestServer(t, zoneHandler())
+
+	client := NewClient(time.Second, addr)
+
+	if _, err := client.CheckDelegation(context.Background(), "example.test", nil); err == nil {
+		t.Error("expected error when no parent zone is found")
+	}
+}
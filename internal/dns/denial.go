@@ -0,0 +1,304 @@
+package dns
+
+import (
+	"fmt"
+	"strings"
+
+	mdns "github.com/miekg/dns"
+)
+
+// verifyDenial checks that the NSEC or NSEC3 records in the authority section
+// prove that name does not exist (nxdomain), or that it has no qtype RRset,
+// following RFC 4035 section 5.4 and RFC 5155 section 8. It returns a short
+// description of the proof. The signatures over the records are not checked.
+func verifyDenial(ns []mdns.RR, name string, qtype uint16, nxdomain bool) (string, error) {
+	name = mdns.CanonicalName(name)
+
+	nsecs := make([]*mdns.NSEC, 0)
+	nsec3s := make([]*mdns.NSEC3, 0)
+	for _, rr := range ns {
+		switch record := rr.(type) {
+		case *mdns.NSEC:
+			nsecs = append(nsecs, record)
+		case *mdns.NSEC3:
+			nsec3s = append(nsec3s, record)
+		}
+	}
+
+	switch {
+	case len(nsecs) > 0 && nxdomain:
+		return denyNameNSEC(nsecs, name)
+	case len(nsecs) > 0:
+		return denyTypeNSEC(nsecs, name, qtype)
+	case len(nsec3s) > 0 && nxdomain:
+		return denyNameNSEC3(nsec3s, name)
+	case len(nsec3s) > 0:
+		return denyTypeNSEC3(nsec3s, name, qtype)
+	default:
+		return "", fmt.Errorf("no NSEC or NSEC3 records")
+	}
+}
+
+// denyNameNSEC proves that name does not exist with an NSEC record covering
+// it and one covering the wildcard at its closest encloser.
+func denyNameNSEC(nsecs []*mdns.NSEC, name string) (string, error) {
+	covering := findCoveringNSEC(nsecs, name)
+	if covering == nil {
+		return "", fmt.Errorf("no NSEC record covers %s", name)
+	}
+
+	wildcard := wildcardOf(nsecClosestEncloser(covering, name))
+	if findCoveringNSEC(nsecs, wildcard) == nil {
+		return "", fmt.Errorf("no NSEC record proves that the wildcard %s does not exist", wildcard)
+	}
+
+	return fmt.Sprintf("NSEC proof that %s does not exist", name), nil
+}
+
+// denyTypeNSEC proves that name has no qtype RRset with the NSEC record of
+// name, the NSEC record covering it when it is an empty non-terminal, or the
+// NSEC record of the wildcard that would have matched it.
+func denyTypeNSEC(nsecs []*mdns.NSEC, name string, qtype uint16) (string, error) {
+	for _, nsec := range nsecs {
+		if mdns.CanonicalName(nsec.Hdr.Name) != name {
+			continue
+		}
+		if err := checkBitmap(nsec.TypeBitMap, name, qtype); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("NSEC proof that %s has no %s RRset", name, mdns.TypeToString[qtype]), nil
+	}
+
+	covering := findCoveringNSEC(nsecs, name)
+	if covering == nil {
+		return "", fmt.Errorf("no NSEC record matches %s", name)
+	}
+
+	// An empty non-terminal has no NSEC record of its own, the record covering
+	// it points at one of its descendants.
+	if next := mdns.CanonicalName(covering.NextDomain); next != name && mdns.IsSubDomain(name, next) {
+		return fmt.Sprintf("NSEC proof that the empty non-terminal %s has no %s RRset", name, mdns.TypeToString[qtype]), nil
+	}
+
+	wildcard := wildcardOf(nsecClosestEncloser(covering, name))
+	for _, nsec := range nsecs {
+		if mdns.CanonicalName(nsec.Hdr.Name) != wildcard {
+			continue
+		}
+		if err := checkBitmap(nsec.TypeBitMap, wildcard, qtype); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("NSEC proof that the wildcard %s has no %s RRset", wildcard, mdns.TypeToString[qtype]), nil
+	}
+
+	return "", fmt.Errorf("no NSEC record matches %s or the wildcard %s", name, wildcard)
+}
+
+// denyNameNSEC3 proves that name does not exist with the closest encloser
+// proof and an NSEC3 record covering the wildcard at the closest encloser.
+func denyNameNSEC3(nsec3s []*mdns.NSEC3, name string) (string, error) {
+	encloser, _, err := nsec3ClosestEncloser(nsec3s, name)
+	if err != nil {
+		return "", err
+	}
+
+	wildcard := wildcardOf(encloser)
+	if findCoveringNSEC3(nsec3s, wildcard) == nil {
+		return "", fmt.Errorf("no NSEC3 record proves that the wildcard %s does not exist", wildcard)
+	}
+
+	return fmt.Sprintf("NSEC3 proof that %s does not exist (closest encloser %s)", name, encloser), nil
+}
+
+// denyTypeNSEC3 proves that name has no qtype RRset with the NSEC3 record
+// matching name. Without one, a DS RRset may be denied by an opt-out span
+// covering the next closer name, and other types by the NSEC3 record of the
+// wildcard at the closest encloser.
+func denyTypeNSEC3(nsec3s []*mdns.NSEC3, name string, qtype uint16) (string, error) {
+	for _, nsec3 := range nsec3s {
+		if !nsec3.Match(name) {
+			continue
+		}
+		if err := checkBitmap(nsec3.TypeBitMap, name, qtype); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("NSEC3 proof that %s has no %s RRset", name, mdns.TypeToString[qtype]), nil
+	}
+
+	encloser, optOut, err := nsec3ClosestEncloser(nsec3s, name)
+	if err != nil {
+		return "", fmt.Errorf("no NSEC3 record matches %s: %w", name, err)
+	}
+
+	if qtype == mdns.TypeDS && optOut {
+		return fmt.Sprintf("NSEC3 opt-out proof that %s has no DS RRset", name), nil
+	}
+
+	wildcard := wildcardOf(encloser)
+	for _, nsec3 := range nsec3s {
+		if !nsec3.Match(wildcard) {
+			continue
+		}
+		if err := checkBitmap(nsec3.TypeBitMap, wildcard, qtype); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("NSEC3 proof that the wildcard %s has no %s RRset", wildcard, mdns.TypeToString[qtype]), nil
+	}
+
+	return "", fmt.Errorf("no NSEC3 record matches %s or the wildcard %s", name, wildcard)
+}
+
+// verifyExpansion checks that an answer for name synthesized from a wildcard,
+// whose RRSIG has only labels labels, comes with an NSEC record covering name
+// or an NSEC3 record covering the next closer name, proving that no closer
+// match exists (RFC 4035 section 5.3.4 and RFC 5155 section 8.8).
+func verifyExpansion(ns []mdns.RR, name string, labels uint8) error {
+	name = mdns.CanonicalName(name)
+
+	split := mdns.SplitDomainName(name)
+	if int(labels) >= len(split) {
+		return fmt.Errorf("RRSIG of %s has %d labels, the name only %d", name, labels, len(split))
+	}
+	nextCloser := mdns.Fqdn(strings.Join(split[len(split)-int(labels)-1:], "."))
+
+	for _, rr := range ns {
+		switch record := rr.(type) {
+		case *mdns.NSEC:
+			if nsecCovers(record, name) {
+				return nil
+			}
+		case *mdns.NSEC3:
+			if record.Cover(nextCloser) {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("%s was synthesized from a wildcard without an NSEC or NSEC3 proof that it does not exist", name)
+}
+
+// checkBitmap fails when the type bitmap of name lists qtype, or a CNAME that
+// the query would have followed. A DS RRset is only denied by the parent side
+// of a delegation (RFC 6840 section 4.4): the NS bit must be set and the SOA
+// bit clear, so that a record of the child zone apex cannot be replayed.
+func checkBitmap(bitmap []uint16, name string, qtype uint16) error {
+	if hasType(bitmap, qtype) {
+		return fmt.Errorf("the type bitmap of %s lists %s", name, mdns.TypeToString[qtype])
+	}
+	if qtype != mdns.TypeCNAME && hasType(bitmap, mdns.TypeCNAME) {
+		return fmt.Errorf("the type bitmap of %s lists CNAME", name)
+	}
+	if qtype == mdns.TypeDS {
+		if !hasType(bitmap, mdns.TypeNS) {
+			return fmt.Errorf("the type bitmap of %s does not list NS, it is not a delegation", name)
+		}
+		if hasType(bitmap, mdns.TypeSOA) {
+			return fmt.Errorf("the type bitmap of %s lists SOA, it comes from the child zone", name)
+		}
+	}
+	return nil
+}
+
+func hasType(bitmap []uint16, rtype uint16) bool {
+	for _, t := range bitmap {
+		if t == rtype {
+			return true
+		}
+	}
+	return false
+}
+
+// wildcardOf returns the wildcard name directly below encloser.
+func wildcardOf(encloser string) string {
+	if encloser == "." {
+		return "*."
+	}
+	return mdns.CanonicalName("*." + encloser)
+}
+
+// nsec3ClosestEncloser runs the closest encloser proof of RFC 5155 section
+// 8.3: the closest ancestor of name matched by an NSEC3 record, and an NSEC3
+// record covering the next closer name. It returns the closest encloser and
+// whether the covering record has the opt-out flag set.
+func nsec3ClosestEncloser(nsec3s []*mdns.NSEC3, name string) (string, bool, error) {
+	labels := mdns.SplitDomainName(name)
+	for i := 1; i <= len(labels); i++ {
+		encloser := mdns.Fqdn(strings.Join(labels[i:], "."))
+
+		matched := false
+		for _, nsec3 := range nsec3s {
+			if nsec3.Match(encloser) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		nextCloser := mdns.Fqdn(strings.Join(labels[i-1:], "."))
+		covering := findCoveringNSEC3(nsec3s, nextCloser)
+		if covering == nil {
+			return "", false, fmt.Errorf("no NSEC3 record covers the next closer name %s", nextCloser)
+		}
+
+		return mdns.CanonicalName(encloser), covering.Flags&1 == 1, nil
+	}
+
+	return "", false, fmt.Errorf("no NSEC3 record proves a closest encloser of %s", name)
+}
+
+func findCoveringNSEC3(nsec3s []*mdns.NSEC3, name string) *mdns.NSEC3 {
+	for _, nsec3 := range nsec3s {
+		if nsec3.Cover(name) {
+			return nsec3
+		}
+	}
+	return nil
+}
+
+func findCoveringNSEC(nsecs []*mdns.NSEC, name string) *mdns.NSEC {
+	for _, nsec := range nsecs {
+		if nsecCovers(nsec, name) {
+			return nsec
+		}
+	}
+	return nil
+}
+
+// nsecCovers reports whether name sorts strictly between the owner and the
+// next name of nsec in canonical order. The last NSEC record of a zone points
+// back at the apex and covers every name sorting after its owner.
+func nsecCovers(nsec *mdns.NSEC, name string) bool {
+	owner := mdns.CanonicalName(nsec.Hdr.Name)
+	next := mdns.CanonicalName(nsec.NextDomain)
+
+	if canonicalCompare(owner, next) < 0 {
+		return canonicalCompare(owner, name) < 0 && canonicalCompare(name, next) < 0
+	}
+	return canonicalCompare(owner, name) < 0 && mdns.IsSubDomain(next, name)
+}
+
+// nsecClosestEncloser returns the longest ancestor of name shared with the
+// owner or the next name of the NSEC record covering it.
+func nsecClosestEncloser(nsec *mdns.NSEC, name string) string {
+	common := max(mdns.CompareDomainName(name, nsec.Hdr.Name), mdns.CompareDomainName(name, nsec.NextDomain))
+
+	labels := mdns.SplitDomainName(name)
+	return mdns.Fqdn(strings.Join(labels[len(labels)-common:], "."))
+}
+
+// canonicalCompare orders two names following RFC 4034 section 6.1: label by
+// label starting with the rightmost one, comparing lowercased labels as bytes.
+func canonicalCompare(a, b string) int {
+	aLabels := mdns.SplitDomainName(mdns.CanonicalName(a))
+	bLabels := mdns.SplitDomainName(mdns.CanonicalName(b))
+
+	for i := 1; i <= len(aLabels) && i <= len(bLabels); i++ {
+		if c := strings.Compare(aLabels[len(aLabels)-i], bLabels[len(bLabels)-i]); c != 0 {
+			return c
+		}
+	}
+
+	return len(aLabels) - len(bLabels)
+}
@@ -0,0 +1,135 @@
+package dns
+
+import (
+	"strings"
+	"testing"
+
+	mdns "github.com/miekg/dns"
+)
+
+// nsec3Record returns the NSEC3 record of name in example., pointing at the
+// hash of next, with no salt or extra iterations.
+func nsec3Record(name, next string, optOut bool, types ...uint16) *mdns.NSEC3 {
+	var flags uint8
+	if optOut {
+		flags = 1
+	}
+
+	return &mdns.NSEC3{
+		Hdr:        mdns.RR_Header{Name: mdns.HashName(name, mdns.SHA1, 0, "") + ".example.", Rrtype: mdns.TypeNSEC3, Class: mdns.ClassINET, Ttl: 3600},
+		Hash:       mdns.SHA1,
+		Flags:      flags,
+		NextDomain: mdns.HashName(next, mdns.SHA1, 0, ""),
+		HashLength: 20,
+		TypeBitMap: types,
+	}
+}
+
+func TestVerifyDenial_NSEC(t *testing.T) {
+	chain := []mdns.RR{
+		mustRR(t, "example. 3600 IN NSEC b.example. SOA RRSIG NSEC DNSKEY"),
+		mustRR(t, "b.example. 3600 IN NSEC d.example. A RRSIG NSEC"),
+		mustRR(t, "d.example. 3600 IN NSEC example. CNAME RRSIG NSEC"),
+	}
+
+	tests := []struct {
+		name     string
+		ns       []mdns.RR
+		domain   string
+		qtype    uint16
+		nxdomain bool
+		err      string
+	}{
+		{"name covered", chain, "c.example.", mdns.TypeA, true, ""},
+		{"name after the last record", chain, "e.example.", mdns.TypeA, true, ""},
+		{"name exists", chain, "b.example.", mdns.TypeA, true, "no NSEC record covers"},
+		{"wildcard not denied", chain[1:], "c.example.", mdns.TypeA, true, "wildcard *.example."},
+		{"type absent", chain, "b.example.", mdns.TypeTXT, false, ""},
+		{"type present", chain, "b.example.", mdns.TypeA, false, "lists A"},
+		{"alias", chain, "d.example.", mdns.TypeA, false, "lists CNAME"},
+		{"no matching record", chain, "c.example.", mdns.TypeA, false, "no NSEC record matches"},
+		{"no records", nil, "c.example.", mdns.TypeA, true, "no NSEC or NSEC3 records"},
+		{"empty non-terminal", []mdns.RR{mustRR(t, "b.example. 3600 IN NSEC x.c.example. A RRSIG NSEC")}, "c.example.", mdns.TypeA, false, ""},
+		{"unsigned delegation", []mdns.RR{mustRR(t, "sub.example. 3600 IN NSEC t.example. NS RRSIG NSEC")}, "sub.example.", mdns.TypeDS, false, ""},
+		{"not a delegation", chain, "b.example.", mdns.TypeDS, false, "does not list NS"},
+		{"child zone apex", []mdns.RR{mustRR(t, "sub.example. 3600 IN NSEC a.sub.example. NS SOA RRSIG NSEC DNSKEY")}, "sub.example.", mdns.TypeDS, false, "lists SOA"},
+	}
+
+	for _, tt := range tests {
+		_, err := verifyDenial(tt.ns, tt.domain, tt.qtype, tt.nxdomain)
+		if tt.err == "" && err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+		if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestVerifyDenial_NSEC3(t *testing.T) {
+	chain := []mdns.RR{
+		nsec3Record("example.", "www.example.", false, mdns.TypeSOA, mdns.TypeRRSIG, mdns.TypeDNSKEY, mdns.TypeNSEC3PARAM),
+		nsec3Record("www.example.", "example.", false, mdns.TypeA, mdns.TypeRRSIG),
+	}
+	optOut := []mdns.RR{
+		nsec3Record("example.", "www.example.", true, mdns.TypeSOA, mdns.TypeRRSIG, mdns.TypeDNSKEY, mdns.TypeNSEC3PARAM),
+		nsec3Record("www.example.", "example.", true, mdns.TypeA, mdns.TypeRRSIG),
+	}
+
+	tests := []struct {
+		name     string
+		ns       []mdns.RR
+		domain   string
+		qtype    uint16
+		nxdomain bool
+		err      string
+	}{
+		{"closest encloser proof", chain, "missing.example.", mdns.TypeA, true, ""},
+		{"no closest encloser", chain[1:], "missing.example.", mdns.TypeA, true, "closest encloser"},
+		{"type absent", chain, "www.example.", mdns.TypeTXT, false, ""},
+		{"type present", chain, "www.example.", mdns.TypeA, false, "lists A"},
+		{"opt-out delegation", optOut, "sub.example.", mdns.TypeDS, false, ""},
+		{"delegation without opt-out", chain, "sub.example.", mdns.TypeDS, false, "no NSEC3 record matches"},
+	}
+
+	for _, tt := range tests {
+		_, err := verifyDenial(tt.ns, tt.domain, tt.qtype, tt.nxdomain)
+		if tt.err == "" && err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+		if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestVerifyExpansion(t *testing.T) {
+	nsec := mustRR(t, "b.example. 3600 IN NSEC d.example. A RRSIG NSEC")
+	nsec3s := []mdns.RR{
+		nsec3Record("example.", "www.example.", false, mdns.TypeSOA, mdns.TypeRRSIG),
+		nsec3Record("www.example.", "example.", false, mdns.TypeA, mdns.TypeRRSIG),
+	}
+
+	// x.c.example. matched *.c.example., whose RRSIG has 2 labels.
+	if err := verifyExpansion([]mdns.RR{nsec}, "x.c.example.", 2); err != nil {
+		t.Errorf("expected the NSEC record to prove the expansion: %v", err)
+	}
+	if err := verifyExpansion(nsec3s, "x.c.example.", 2); err != nil {
+		t.Errorf("expected the NSEC3 records to prove the expansion: %v", err)
+	}
+	if err := verifyExpansion(nil, "x.c.example.", 2); err == nil {
+		t.Error("expected a wildcard answer without proof to be rejected")
+	}
+	if err := verifyExpansion([]mdns.RR{nsec}, "x.e.example.", 2); err == nil {
+		t.Error("expected an NSEC record not covering the name to be rejected")
+	}
+}
+
+func TestCanonicalCompare(t *testing.T) {
+	ordered := []string{"example.", "a.example.", "yljkjljk.a.example.", "Z.a.example.", "zABC.a.EXAMPLE.", "z.example.", "*.z.example."}
+	for i := 1; i < len(ordered); i++ {
+		if canonicalCompare(ordered[i-1], ordered[i]) >= 0 {
+			t.Errorf("expected %s to sort before %s", ordered[i-1], ordered[i])
+		}
+	}
+}
@@ -0,0 +1,590 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	mdns "github.com/miekg/dns"
+
+	"watchr/internal/idn"
+)
+
+// Validation statuses, as defined in RFC 4035 section 4.3.
+const (
+	DNSSECSecure        = "secure"
+	DNSSECInsecure      = "insecure"
+	DNSSECBogus         = "bogus"
+	DNSSECIndeterminate = "indeterminate"
+)
+
+// DefaultTrustAnchors are the DS records of the root zone key signing keys
+// published by IANA (KSK-2017 and KSK-2024).
+var DefaultTrustAnchors = []string{
+	". IN DS 20326 8 2 E06D44B80B8F1D39A95C0B0D7C65D08458E880409BBC683457104237C7F8EC8D",
+	". IN DS 38696 8 2 683D2D0ACB8C9B712A1948B27F741219298D0A450D612C483AF444A4C0FB2B16",
+}
+
+// ValidateDNSSEC walks the chain of trust from the root trust anchors down to
+// the queried name, verifying the DNSKEY and DS records of every zone cut and
+// finally the signatures of the answer itself, or the NSEC and NSEC3 proof
+// that it does not exist. The CNAME records of an alias are verified with the
+// keys of their own zones. When trustAnchors is empty the DefaultTrustAnchors
+// are used.
+func (c *Client) ValidateDNSSEC(ctx context.Context, domain string, recordType string, trustAnchors []string) (*DNSSECResponse, error) {
+	qtype, err := parseRecordType(recordType)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := idn.Parse(mdns.Fqdn(domain))
+	if err != nil {
+		return nil, err
+	}
+	domain = parsed.ASCII
+
+	if len(trustAnchors) == 0 {
+		trustAnchors = DefaultTrustAnchors
+	}
+
+	anchors, err := parseTrustAnchors(trustAnchors)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Debug("validating DNSSEC chain", "domain", domain, "type", recordType, "nameserver", c.nameserver)
+	start := time.Now()
+
+	zones, err := c.findZoneCuts(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &DNSSECResponse{
+		Domain:     domain,
+		IDN:        parsed,
+		RecordType: strings.ToUpper(recordType),
+		Nameserver: c.nameserver,
+		Status:     DNSSECSecure,
+		Links:      make([]DNSSECLink, 0),
+		Records:    make([]Record, 0),
+	}
+
+	now := time.Now()
+	validated := make(map[string][]*mdns.DNSKEY)
+	var keys []*mdns.DNSKEY
+	response.Status, keys = c.validateChain(ctx, zones, anchors, validated, response, now)
+
+	r, err := c.queryDNSSEC(ctx, domain, qtype)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ans := range r.Answer {
+		if record := parseAnswer(ans); record != nil {
+			response.Records = append(response.Records, *record)
+		}
+	}
+
+	// Every link of a CNAME chain is signed by the zone of its owner name, so
+	// the chain of trust of each target's zone is walked before its RRset is
+	// verified.
+	name := domain
+	zone := zones[len(zones)-1]
+	for hops := 0; response.Status == DNSSECSecure; hops++ {
+		link := validateAnswer(name, qtype, zone, r, keys, now)
+		response.Links = append(response.Links, link)
+		response.Status = link.Status
+
+		target := aliasTarget(r.Answer, name)
+		if link.Status != DNSSECSecure || target == "" || qtype == mdns.TypeCNAME {
+			break
+		}
+		if hops >= maxCNAMEChain {
+			response.Links = append(response.Links, DNSSECLink{
+				Zone:   zone,
+				Type:   "CNAME",
+				Status: DNSSECIndeterminate,
+				Reason: fmt.Sprintf("CNAME chain is longer than %d hops", maxCNAMEChain),
+			})
+			response.Status = DNSSECIndeterminate
+			break
+		}
+
+		targetZones, err := c.findZoneCuts(ctx, target)
+		if err != nil {
+			return nil, err
+		}
+
+		name = target
+		zone = targetZones[len(targetZones)-1]
+		response.Status, keys = c.validateChain(ctx, targetZones, anchors, validated, response, now)
+	}
+
+	response.QueryTime = time.Since(start)
+
+	return response, nil
+}
+
+// validateChain walks the chain of trust from the root down to the last of
+// zones, appending a link to response for every zone that is not in validated
+// yet. It returns the status of the chain and the keys of the last zone;
+// validated records the keys of every secure zone.
+func (c *Client) validateChain(ctx context.Context, zones []string, anchors []*mdns.DS, validated map[string][]*mdns.DNSKEY, response *DNSSECResponse, now time.Time) (string, []*mdns.DNSKEY) {
+	trusted := anchors
+	var keys []*mdns.DNSKEY
+	for i, zone := range zones {
+		if zoneKeys, ok := validated[zone]; ok {
+			keys = zoneKeys
+			continue
+		}
+
+		if i > 0 {
+			link, ds := c.validateDelegation(ctx, zones[i-1], zone, keys, now)
+			response.Links = append(response.Links, link)
+			if link.Status != DNSSECSecure {
+				return link.Status, nil
+			}
+			trusted = ds
+		}
+
+		link, zoneKeys := c.validateZoneKeys(ctx, zone, trusted, now)
+		response.Links = append(response.Links, link)
+		if link.Status != DNSSECSecure {
+			return link.Status, nil
+		}
+		keys = zoneKeys
+		validated[zone] = zoneKeys
+	}
+
+	return DNSSECSecure, keys
+}
+
+func parseTrustAnchors(trustAnchors []string) ([]*mdns.DS, error) {
+	anchors := make([]*mdns.DS, 0, len(trustAnchors))
+	for _, anchor := range trustAnchors {
+		rr, err := mdns.NewRR(anchor)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trust anchor %q: %w", anchor, err)
+		}
+
+		ds, ok := rr.(*mdns.DS)
+		if !ok || ds.Hdr.Name != "." {
+			return nil, fmt.Errorf("invalid trust anchor %q: expected a root DS record", anchor)
+		}
+
+		anchors = append(anchors, ds)
+	}
+
+	return anchors, nil
+}
+
+func (c *Client) queryDNSSEC(ctx context.Context, name string, qtype uint16) (*mdns.Msg, error) {
+	m := new(mdns.Msg)
+	m.SetQuestion(name, qtype)
+	m.RecursionDesired = true
+	m.CheckingDisabled = true
+	m.SetEdns0(4096, true)
+
+	r, _, err := c.exchange(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// findZoneCuts returns the apex of every zone between the root and domain,
+// starting with the root. A name is considered a zone apex when the resolver
+// returns an SOA record owned by that name.
+func (c *Client) findZoneCuts(ctx context.Context, domain string) ([]string, error) {
+	zones := []string{"."}
+
+	labels := mdns.SplitDomainName(domain)
+	for i := len(labels) - 1; i >= 0; i-- {
+		name := mdns.Fqdn(strings.Join(labels[i:], "."))
+
+		r, err := c.queryDNSSEC(ctx, name, mdns.TypeSOA)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ans := range r.Answer {
+			if soa, ok := ans.(*mdns.SOA); ok && mdns.CanonicalName(soa.Hdr.Name) == mdns.CanonicalName(name) {
+				zones = append(zones, name)
+				break
+			}
+		}
+	}
+
+	return zones, nil
+}
+
+// validateZoneKeys fetches the DNSKEY RRset of zone and verifies that it is
+// signed by a key matching one of the trusted DS records.
+func (c *Client) validateZoneKeys(ctx context.Context, zone string, trusted []*mdns.DS, now time.Time) (DNSSECLink, []*mdns.DNSKEY) {
+	link := DNSSECLink{
+		Zone: zone,
+		Type: "DNSKEY",
+	}
+
+	r, err := c.queryDNSSEC(ctx, zone, mdns.TypeDNSKEY)
+	if err != nil {
+		link.Status = DNSSECIndeterminate
+		link.Reason = fmt.Sprintf("DNSKEY query failed: %v", err)
+		return link, nil
+	}
+
+	keys := make([]*mdns.DNSKEY, 0)
+	for _, ans := range r.Answer {
+		if key, ok := ans.(*mdns.DNSKEY); ok {
+			keys = append(keys, key)
+		}
+	}
+
+	if len(keys) == 0 {
+		link.Status = DNSSECBogus
+		link.Reason = "no DNSKEY records found"
+		return link, nil
+	}
+
+	// Only keys that match a trusted DS record may sign the DNSKEY RRset.
+	anchored := make([]*mdns.DNSKEY, 0)
+	for _, key := range keys {
+		for _, ds := range trusted {
+			if matchesDS(key, ds) {
+				anchored = append(anchored, key)
+				break
+			}
+		}
+	}
+
+	if len(anchored) == 0 {
+		link.Status = DNSSECBogus
+		link.Reason = fmt.Sprintf("no DNSKEY matches the DS records (key tags %s)", formatDSKeyTags(trusted))
+		return link, nil
+	}
+
+	tags, err := verifyRRsets(r.Answer, zone, anchored, now)
+	if err != nil {
+		link.Status = DNSSECBogus
+		link.Reason = err.Error()
+		return link, nil
+	}
+
+	link.Status = DNSSECSecure
+	link.KeyTags = tags
+	link.Reason = fmt.Sprintf("DNSKEY RRset signed by key %s matching a trusted DS record", formatKeyTags(tags))
+
+	return link, keys
+}
+
+// validateDelegation fetches the DS RRset for child from the parent zone and
+// verifies it against the parent's keys. A missing DS RRset is only accepted
+// as an insecure delegation when the parent proves its absence.
+func (c *Client) validateDelegation(ctx context.Context, parent, child string, parentKeys []*mdns.DNSKEY, now time.Time) (DNSSECLink, []*mdns.DS) {
+	link := DNSSECLink{
+		Zone: child,
+		Type: "DS",
+	}
+
+	r, err := c.queryDNSSEC(ctx, child, mdns.TypeDS)
+	if err != nil {
+		link.Status = DNSSECIndeterminate
+		link.Reason = fmt.Sprintf("DS query failed: %v", err)
+		return link, nil
+	}
+
+	ds := make([]*mdns.DS, 0)
+	for _, ans := range r.Answer {
+		if record, ok := ans.(*mdns.DS); ok {
+			ds = append(ds, record)
+		}
+	}
+
+	if len(ds) > 0 {
+		tags, err := verifyRRsets(r.Answer, parent, parentKeys, now)
+		if err != nil {
+			link.Status = DNSSECBogus
+			link.Reason = err.Error()
+			return link, nil
+		}
+
+		link.Status = DNSSECSecure
+		link.KeyTags = tags
+		link.Reason = fmt.Sprintf("DS RRset (key tags %s) signed by %s key %s", formatDSKeyTags(ds), parent, formatKeyTags(tags))
+		return link, ds
+	}
+
+	if r.Rcode != mdns.RcodeSuccess {
+		link.Status = DNSSECIndeterminate
+		link.Reason = fmt.Sprintf("DS query returned %s", mdns.RcodeToString[r.Rcode])
+		return link, nil
+	}
+
+	if _, err := verifyRRsets(r.Ns, parent, parentKeys, now); err != nil {
+		link.Status = DNSSECBogus
+		link.Reason = fmt.Sprintf("DS RRset absent without valid denial of existence: %v", err)
+		return link, nil
+	}
+
+	proof, err := verifyDenial(r.Ns, child, mdns.TypeDS, false)
+	if err != nil {
+		link.Status = DNSSECBogus
+		link.Reason = fmt.Sprintf("DS RRset absent without NSEC or NSEC3 proof: %v", err)
+		return link, nil
+	}
+
+	link.Status = DNSSECInsecure
+	link.Reason = fmt.Sprintf("parent zone proves there is no DS RRset (unsigned delegation): %s", proof)
+
+	return link, nil
+}
+
+// validateAnswer verifies the signatures over the RRset of name in the answer,
+// its qtype RRset or the CNAME pointing elsewhere, or when there is none over
+// the denial of existence in the authority section, which must then prove that
+// name or its qtype RRset does not exist.
+func validateAnswer(name string, qtype uint16, zone string, r *mdns.Msg, keys []*mdns.DNSKEY, now time.Time) DNSSECLink {
+	link := DNSSECLink{
+		Zone: zone,
+		Type: mdns.TypeToString[qtype],
+	}
+
+	if r.Rcode != mdns.RcodeSuccess && r.Rcode != mdns.RcodeNameError {
+		link.Status = DNSSECIndeterminate
+		link.Reason = fmt.Sprintf("query returned %s", mdns.RcodeToString[r.Rcode])
+		return link
+	}
+
+	section := ownedRRs(r.Answer, name)
+	description := fmt.Sprintf("%s %s RRset", name, mdns.TypeToString[qtype])
+	if aliasTarget(section, name) != "" {
+		link.Type = "CNAME"
+		description = fmt.Sprintf("%s CNAME RRset", name)
+	}
+	if len(section) == 0 {
+		section = r.Ns
+		description = "denial of existence"
+	}
+
+	if len(section) == 0 {
+		link.Status = DNSSECBogus
+		link.Reason = "empty response without denial of existence"
+		return link
+	}
+
+	tags, err := verifyRRsets(section, zone, keys, now)
+	if err != nil {
+		link.Status = DNSSECBogus
+		link.Reason = err.Error()
+		return link
+	}
+
+	if len(ownedRRs(r.Answer, name)) == 0 {
+		proof, err := verifyDenial(r.Ns, name, qtype, r.Rcode == mdns.RcodeNameError)
+		if err != nil {
+			link.Status = DNSSECBogus
+			link.Reason = fmt.Sprintf("denial of existence does not prove the answer: %v", err)
+			return link
+		}
+		description = proof
+	} else if labels, expanded := wildcardLabels(section, name); expanded {
+		proof := denialRRs(r.Ns)
+		if _, err := verifyRRsets(proof, zone, keys, now); err != nil {
+			link.Status = DNSSECBogus
+			link.Reason = fmt.Sprintf("wildcard answer without a valid proof that %s does not exist: %v", name, err)
+			return link
+		}
+		if err := verifyExpansion(proof, name, labels); err != nil {
+			link.Status = DNSSECBogus
+			link.Reason = err.Error()
+			return link
+		}
+		description += " (wildcard expansion)"
+	}
+
+	link.Status = DNSSECSecure
+	link.KeyTags = tags
+	link.Reason = fmt.Sprintf("%s signed by %s key %s", description, zone, formatKeyTags(tags))
+
+	return link
+}
+
+// wildcardLabels reports whether the RRSIG over the answer for name has fewer
+// labels than name, meaning the answer was synthesized from a wildcard, and
+// returns its label count.
+func wildcardLabels(rrs []mdns.RR, name string) (uint8, bool) {
+	count := mdns.CountLabel(name)
+	if strings.HasPrefix(name, "*.") {
+		count--
+	}
+
+	for _, rr := range rrs {
+		if sig, ok := rr.(*mdns.RRSIG); ok && int(sig.Labels) < count {
+			return sig.Labels, true
+		}
+	}
+	return 0, false
+}
+
+// denialRRs returns the NSEC and NSEC3 records of rrs and their signatures.
+func denialRRs(rrs []mdns.RR) []mdns.RR {
+	denials := make([]mdns.RR, 0)
+	for _, rr := range rrs {
+		switch record := rr.(type) {
+		case *mdns.NSEC, *mdns.NSEC3:
+			denials = append(denials, rr)
+		case *mdns.RRSIG:
+			if record.TypeCovered == mdns.TypeNSEC || record.TypeCovered == mdns.TypeNSEC3 {
+				denials = append(denials, rr)
+			}
+		}
+	}
+	return denials
+}
+
+// ownedRRs returns the records and signatures of rrs owned by name.
+func ownedRRs(rrs []mdns.RR, name string) []mdns.RR {
+	owned := make([]mdns.RR, 0)
+	for _, rr := range rrs {
+		if mdns.CanonicalName(rr.Header().Name) == mdns.CanonicalName(name) {
+			owned = append(owned, rr)
+		}
+	}
+	return owned
+}
+
+// aliasTarget returns the target of the CNAME record of name in rrs, or an
+// empty string when name is not an alias.
+func aliasTarget(rrs []mdns.RR, name string) string {
+	for _, rr := range rrs {
+		if cname, ok := rr.(*mdns.CNAME); ok && mdns.CanonicalName(cname.Hdr.Name) == mdns.CanonicalName(name) {
+			return mdns.CanonicalName(cname.Target)
+		}
+	}
+	return ""
+}
+
+// verifyRRsets checks that every RRset in rrs carries at least one valid
+// signature made by signer with one of keys. It returns the tags of the keys
+// that produced valid signatures.
+func verifyRRsets(rrs []mdns.RR, signer string, keys []*mdns.DNSKEY, now time.Time) ([]uint16, error) {
+	type rrsetKey struct {
+		name  string
+		rtype uint16
+	}
+
+	rrsets := make(map[rrsetKey][]mdns.RR)
+	order := make([]rrsetKey, 0)
+	sigs := make(map[rrsetKey][]*mdns.RRSIG)
+	for _, rr := range rrs {
+		if sig, ok := rr.(*mdns.RRSIG); ok {
+			k := rrsetKey{name: mdns.CanonicalName(sig.Hdr.Name), rtype: sig.TypeCovered}
+			sigs[k] = append(sigs[k], sig)
+			continue
+		}
+
+		k := rrsetKey{name: mdns.CanonicalName(rr.Header().Name), rtype: rr.Header().Rrtype}
+		if _, ok := rrsets[k]; !ok {
+			order = append(order, k)
+		}
+		rrsets[k] = append(rrsets[k], rr)
+	}
+
+	tags := make([]uint16, 0)
+	for _, k := range order {
+		rrset := rrsets[k]
+		typeName := mdns.TypeToString[k.rtype]
+
+		if len(sigs[k]) == 0 {
+			return nil, fmt.Errorf("%s %s RRset is not signed", k.name, typeName)
+		}
+
+		var lastErr error
+		verified := false
+		for _, sig := range sigs[k] {
+			if mdns.CanonicalName(sig.SignerName) != mdns.CanonicalName(signer) {
+				lastErr = fmt.Errorf("%s %s RRset signed by %s, expected %s", k.name, typeName, sig.SignerName, signer)
+				continue
+			}
+
+			if !sig.ValidityPeriod(now) {
+				lastErr = fmt.Errorf("%s %s RRSIG by key %d is outside its validity period (%s to %s)",
+					k.name, typeName, sig.KeyTag, mdns.TimeToString(sig.Inception), mdns.TimeToString(sig.Expiration))
+				continue
+			}
+
+			key := findKey(keys, sig)
+			if key == nil {
+				lastErr = fmt.Errorf("%s %s RRSIG made by unknown key %d", k.name, typeName, sig.KeyTag)
+				continue
+			}
+
+			if err := sig.Verify(key, rrset); err != nil {
+				lastErr = fmt.Errorf("%s %s RRSIG by key %d failed to verify: %v", k.name, typeName, sig.KeyTag, err)
+				continue
+			}
+
+			verified = true
+			tags = appendUnique(tags, sig.KeyTag)
+			break
+		}
+
+		if !verified {
+			return nil, lastErr
+		}
+	}
+
+	return tags, nil
+}
+
+func findKey(keys []*mdns.DNSKEY, sig *mdns.RRSIG) *mdns.DNSKEY {
+	for _, key := range keys {
+		if key.Algorithm == sig.Algorithm && key.KeyTag() == sig.KeyTag &&
+			mdns.CanonicalName(key.Hdr.Name) == mdns.CanonicalName(sig.SignerName) {
+			return key
+		}
+	}
+	return nil
+}
+
+func matchesDS(key *mdns.DNSKEY, ds *mdns.DS) bool {
+	if key.Algorithm != ds.Algorithm || key.KeyTag() != ds.KeyTag {
+		return false
+	}
+
+	computed := key.ToDS(ds.DigestType)
+	if computed == nil {
+		return false
+	}
+
+	return strings.EqualFold(computed.Digest, ds.Digest)
+}
+
+func appendUnique(tags []uint16, tag uint16) []uint16 {
+	for _, t := range tags {
+		if t == tag {
+			return tags
+		}
+	}
+	return append(tags, tag)
+}
+
+func formatKeyTags(tags []uint16) string {
+	parts := make([]string, len(tags))
+	for i, tag := range tags {
+		parts[i] = fmt.Sprintf("%d", tag)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatDSKeyTags(ds []*mdns.DS) string {
+	tags := make([]uint16, 0, len(ds))
+	for _, record := range ds {
+		tags = appendUnique(tags, record.KeyTag)
+	}
+	return formatKeyTags(tags)
+}
@@ -0,0 +1,433 @@
+package dns
+
+import (
+	"context"
+	"crypto"
+	"strings"
+	"testing"
+	"time"
+
+	mdns "github.com/miekg/dns"
+)
+
+type testSigner struct {
+	key  *mdns.DNSKEY
+	priv crypto.Signer
+}
+
+func newTestSigner(t *testing.T, zone string) *testSigner {
+	t.Helper()
+
+	key := &mdns.DNSKEY{
+		Hdr:       mdns.RR_Header{Name: zone, Rrtype: mdns.TypeDNSKEY, Class: mdns.ClassINET, Ttl: 3600},
+		Flags:     257,
+		Protocol:  3,
+		Algorithm: mdns.ECDSAP256SHA256,
+	}
+
+	priv, err := key.Generate(256)
+	if err != nil {
+		t.Fatalf("failed to generate key for %s: %v", zone, err)
+	}
+
+	return &testSigner{key: key, priv: priv.(crypto.Signer)}
+}
+
+func (s *testSigner) sign(t *testing.T, rrset ...mdns.RR) *mdns.RRSIG {
+	t.Helper()
+
+	now := time.Now()
+	sig := &mdns.RRSIG{
+		Hdr:        mdns.RR_Header{Ttl: 3600},
+		Algorithm:  s.key.Algorithm,
+		KeyTag:     s.key.KeyTag(),
+		SignerName: s.key.Hdr.Name,
+		Inception:  uint32(now.Add(-time.Hour).Unix()),
+		Expiration: uint32(now.Add(time.Hour).Unix()),
+	}
+
+	if err := sig.Sign(s.priv, rrset); err != nil {
+		t.Fatalf("failed to sign %s: %v", rrset[0].Header().Name, err)
+	}
+
+	return sig
+}
+
+func mustRR(t *testing.T, s string) mdns.RR {
+	t.Helper()
+
+	rr, err := mdns.NewRR(s)
+	if err != nil {
+		t.Fatalf("failed to parse %q: %v", s, err)
+	}
+	return rr
+}
+
+// signedTestHandler behaves like a recursive resolver for a small signed tree:
+// the signed root, the signed "example." and "other." zones and an unsigned
+// "insecure." zone. Names of "example." are aliases into "other." or match a
+// wildcard below the empty non-terminal "wild.example.", and the signed zones
+// deny the names and types they do not have with NSEC records.
+func signedTestHandler(t *testing.T) (mdns.HandlerFunc, string) {
+	t.Helper()
+
+	root := newTestSigner(t, ".")
+	example := newTestSigner(t, "example.")
+	other := newTestSigner(t, "other.")
+
+	records := make([]mdns.RR, 0)
+	denials := make(map[string][]mdns.RR)
+	add := func(signer *testSigner, rrset ...mdns.RR) {
+		if _, ok := rrset[0].(*mdns.NSEC); ok {
+			zone := signer.key.Hdr.Name
+			denials[zone] = append(denials[zone], rrset[0], signer.sign(t, rrset...))
+			return
+		}
+
+		records = append(records, rrset...)
+		if signer != nil {
+			records = append(records, signer.sign(t, rrset...))
+		}
+	}
+
+	add(root, mustRR(t, ". 3600 IN SOA a.root. hostmaster.root. 1 3600 600 86400 300"))
+	add(root, root.key)
+	add(root, example.key.ToDS(mdns.SHA256))
+	add(root, other.key.ToDS(mdns.SHA256))
+	add(root, mustRR(t, "insecure. 3600 IN NSEC other. NS RRSIG NSEC"))
+	add(root, mustRR(t, "example. 3600 IN NSEC insecure. NS DS RRSIG NSEC"))
+
+	add(example, mustRR(t, "example. 3600 IN SOA ns.example. hostmaster.example. 1 3600 600 86400 300"))
+	add(example, example.key)
+	add(example, mustRR(t, "www.example. 300 IN A 192.0.2.1"))
+	add(example, mustRR(t, "alias.example. 300 IN CNAME www.other."))
+	add(example, mustRR(t, "lost.example. 300 IN CNAME gone.other."))
+	add(example, mustRR(t, "*.wild.example. 300 IN A 192.0.2.5"))
+	add(example, mustRR(t, "example. 3600 IN NSEC alias.example. SOA RRSIG NSEC DNSKEY"))
+	add(example, mustRR(t, "alias.example. 3600 IN NSEC bad.example. CNAME RRSIG NSEC"))
+	add(example, mustRR(t, "bad.example. 3600 IN NSEC lost.example. A RRSIG NSEC"))
+	add(example, mustRR(t, "lost.example. 3600 IN NSEC *.wild.example. CNAME RRSIG NSEC"))
+	add(example, mustRR(t, "*.wild.example. 3600 IN NSEC www.example. A RRSIG NSEC"))
+	add(example, mustRR(t, "www.example. 3600 IN NSEC example. A RRSIG NSEC"))
+
+	// The signature covers a different address than the one served.
+	records = append(records, mustRR(t, "bad.example. 300 IN A 192.0.2.99"))
+	records = append(records, example.sign(t, mustRR(t, "bad.example. 300 IN A 192.0.2.2")))
+
+	add(other, mustRR(t, "other. 3600 IN SOA ns.other. hostmaster.other. 1 3600 600 86400 300"))
+	add(other, other.key)
+	add(other, mustRR(t, "www.other. 300 IN A 192.0.2.4"))
+	add(other, mustRR(t, "other. 3600 IN NSEC www.other. SOA RRSIG NSEC DNSKEY"))
+	add(other, mustRR(t, "www.other. 3600 IN NSEC other. A RRSIG NSEC"))
+
+	add(nil, mustRR(t, "insecure. 3600 IN SOA ns.insecure. hostmaster.insecure. 1 3600 600 86400 300"))
+	add(nil, mustRR(t, "www.insecure. 300 IN A 192.0.2.3"))
+
+	zones := []string{"example.", "insecure.", "other.", "."}
+
+	exists := func(name string) bool {
+		for _, rr := range records {
+			if mdns.IsSubDomain(name, mdns.CanonicalName(rr.Header().Name)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	handler := func(w mdns.ResponseWriter, r *mdns.Msg) {
+		m := new(mdns.Msg)
+		m.SetReply(r)
+
+		q := r.Question[0]
+		name := mdns.CanonicalName(q.Name)
+		do := r.IsEdns0() != nil && r.IsEdns0().Do()
+
+		// Aliases are followed, like a resolver does.
+		var answer []mdns.RR
+		synthesized := false
+		for i := 0; i < maxCNAMEChain; i++ {
+			answer = matchRecords(records, name, q.Qtype, do)
+			if len(answer) == 0 && q.Qtype != mdns.TypeCNAME {
+				answer = matchRecords(records, name, mdns.TypeCNAME, do)
+			}
+			if len(answer) == 0 && !exists(name) {
+				// Wildcard answers are synthesized with the owner name
+				// replaced, and keep the signatures of the wildcard.
+				wildcard := "*." + name[strings.Index(name, ".")+1:]
+				for _, rr := range matchRecords(records, wildcard, q.Qtype, do) {
+					rr = mdns.Copy(rr)
+					rr.Header().Name = name
+					answer = append(answer, rr)
+					synthesized = true
+				}
+			}
+			m.Answer = append(m.Answer, answer...)
+
+			target := ""
+			for _, rr := range answer {
+				if cname, ok := rr.(*mdns.CNAME); ok {
+					target = mdns.CanonicalName(cname.Target)
+				}
+			}
+			if target == "" {
+				break
+			}
+			name = target
+		}
+
+		if synthesized && do {
+			for _, zone := range zones {
+				if mdns.IsSubDomain(zone, name) {
+					m.Ns = append(m.Ns, denials[zone]...)
+					break
+				}
+			}
+		}
+
+		if len(answer) == 0 {
+			// DS records live in the parent zone.
+			lookup := name
+			if q.Qtype == mdns.TypeDS && name != "." {
+				lookup = name[strings.Index(name, ".")+1:]
+				if lookup == "" {
+					lookup = "."
+				}
+			}
+
+			for _, zone := range zones {
+				if mdns.IsSubDomain(zone, lookup) {
+					m.Ns = append(m.Ns, matchRecords(records, zone, mdns.TypeSOA, do)...)
+					if do {
+						m.Ns = append(m.Ns, denials[zone]...)
+					}
+					break
+				}
+			}
+
+			if !exists(name) {
+				m.Rcode = mdns.RcodeNameError
+			}
+		}
+
+		_ = w.WriteMsg(m)
+	}
+
+	return handler, root.key.ToDS(mdns.SHA256).String()
+}
+
+func matchRecords(records []mdns.RR, name string, qtype uint16, do bool) []mdns.RR {
+	matched := make([]mdns.RR, 0)
+	for _, rr := range records {
+		header := rr.Header()
+		if mdns.CanonicalName(header.Name) != name {
+			continue
+		}
+		if sig, ok := rr.(*mdns.RRSIG); ok {
+			if do && sig.TypeCovered == qtype {
+				matched = append(matched, rr)
+			}
+			continue
+		}
+		if header.Rrtype == qtype {
+			matched = append(matched, rr)
+		}
+	}
+	return matched
+}
+
+func TestClient_ValidateDNSSEC_Secure(t *testing.T) {
+	handler, anchor := signedTestHandler(t)
+	client := NewClient(5*time.Second, startTestServer(t, handler))
+
+	resp, err := client.ValidateDNSSEC(context.Background(), "www.example", "A", []string{anchor})
+	if err != nil {
+		t.Fatalf("ValidateDNSSEC failed: %v", err)
+	}
+
+	if resp.Status != DNSSECSecure {
+		t.Fatalf("expected status secure, got %s: %+v", resp.Status, resp.Links)
+	}
+
+	expected := []struct{ zone, rtype string }{
+		{".", "DNSKEY"},
+		{"example.", "DS"},
+		{"example.", "DNSKEY"},
+		{"example.", "A"},
+	}
+	if len(resp.Links) != len(expected) {
+		t.Fatalf("expected %d links, got %d: %+v", len(expected), len(resp.Links), resp.Links)
+	}
+	for i, link := range resp.Links {
+		if link.Zone != expected[i].zone || link.Type != expected[i].rtype {
+			t.Errorf("link %d: expected %s %s, got %s %s", i, expected[i].zone, expected[i].rtype, link.Zone, link.Type)
+		}
+		if link.Status != DNSSECSecure {
+			t.Errorf("link %d: expected secure, got %s (%s)", i, link.Status, link.Reason)
+		}
+	}
+
+	found := false
+	for _, record := range resp.Records {
+		if record.Type == "A" && record.Value == "192.0.2.1" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected A record in response, got %+v", resp.Records)
+	}
+}
+
+func TestClient_ValidateDNSSEC_WrongTrustAnchor(t *testing.T) {
+	handler, _ := signedTestHandler(t)
+	client := NewClient(5*time.Second, startTestServer(t, handler))
+
+	resp, err := client.ValidateDNSSEC(context.Background(), "www.example", "A", nil)
+	if err != nil {
+		t.Fatalf("ValidateDNSSEC failed: %v", err)
+	}
+
+	if resp.Status != DNSSECBogus {
+		t.Fatalf("expected status bogus, got %s", resp.Status)
+	}
+
+	if len(resp.Links) != 1 || resp.Links[0].Zone != "." {
+		t.Fatalf("expected validation to stop at the root, got %+v", resp.Links)
+	}
+
+	if !strings.Contains(resp.Links[0].Reason, "no DNSKEY matches") {
+		t.Errorf("unexpected reason: %s", resp.Links[0].Reason)
+	}
+}
+
+func TestClient_ValidateDNSSEC_Insecure(t *testing.T) {
+	handler, anchor := signedTestHandler(t)
+	client := NewClient(5*time.Second, startTestServer(t, handler))
+
+	resp, err := client.ValidateDNSSEC(context.Background(), "www.insecure", "A", []string{anchor})
+	if err != nil {
+		t.Fatalf("ValidateDNSSEC failed: %v", err)
+	}
+
+	if resp.Status != DNSSECInsecure {
+		t.Fatalf("expected status insecure, got %s: %+v", resp.Status, resp.Links)
+	}
+
+	last := resp.Links[len(resp.Links)-1]
+	if last.Zone != "insecure." || last.Type != "DS" {
+		t.Errorf("expected last link to be the insecure. DS link, got %s %s", last.Zone, last.Type)
+	}
+}
+
+func TestClient_ValidateDNSSEC_BadSignature(t *testing.T) {
+	handler, anchor := signedTestHandler(t)
+	client := NewClient(5*time.Second, startTestServer(t, handler))
+
+	resp, err := client.ValidateDNSSEC(context.Background(), "bad.example", "A", []string{anchor})
+	if err != nil {
+		t.Fatalf("ValidateDNSSEC failed: %v", err)
+	}
+
+	if resp.Status != DNSSECBogus {
+		t.Fatalf("expected status bogus, got %s", resp.Status)
+	}
+
+	last := resp.Links[len(resp.Links)-1]
+	if last.Type != "A" || !strings.Contains(last.Reason, "failed to verify") {
+		t.Errorf("unexpected final link: %+v", last)
+	}
+}
+
+func TestClient_ValidateDNSSEC_Denial(t *testing.T) {
+	handler, anchor := signedTestHandler(t)
+	client := NewClient(5*time.Second, startTestServer(t, handler))
+
+	tests := []struct {
+		domain     string
+		recordType string
+		reason     string
+	}{
+		{"missing.example", "A", "NSEC proof that missing.example. does not exist"},
+		{"www.example", "TXT", "NSEC proof that www.example. has no TXT RRset"},
+		{"wild.example", "A", "NSEC proof that the empty non-terminal wild.example. has no A RRset"},
+		{"x.wild.example", "A", "x.wild.example. A RRset (wildcard expansion)"},
+	}
+
+	for _, tt := range tests {
+		resp, err := client.ValidateDNSSEC(context.Background(), tt.domain, tt.recordType, []string{anchor})
+		if err != nil {
+			t.Fatalf("ValidateDNSSEC failed: %v", err)
+		}
+
+		if resp.Status != DNSSECSecure {
+			t.Errorf("%s %s: expected status secure, got %s: %+v", tt.domain, tt.recordType, resp.Status, resp.Links)
+			continue
+		}
+
+		last := resp.Links[len(resp.Links)-1]
+		if !strings.Contains(last.Reason, tt.reason) {
+			t.Errorf("%s %s: unexpected reason: %s", tt.domain, tt.recordType, last.Reason)
+		}
+	}
+}
+
+func TestClient_ValidateDNSSEC_CNAME(t *testing.T) {
+	handler, anchor := signedTestHandler(t)
+	client := NewClient(5*time.Second, startTestServer(t, handler))
+
+	resp, err := client.ValidateDNSSEC(context.Background(), "alias.example", "A", []string{anchor})
+	if err != nil {
+		t.Fatalf("ValidateDNSSEC failed: %v", err)
+	}
+
+	if resp.Status != DNSSECSecure {
+		t.Fatalf("expected status secure, got %s: %+v", resp.Status, resp.Links)
+	}
+
+	// The alias is signed by example., its target by other.
+	expected := []struct{ zone, rtype string }{
+		{".", "DNSKEY"},
+		{"example.", "DS"},
+		{"example.", "DNSKEY"},
+		{"example.", "CNAME"},
+		{"other.", "DS"},
+		{"other.", "DNSKEY"},
+		{"other.", "A"},
+	}
+	if len(resp.Links) != len(expected) {
+		t.Fatalf("expected %d links, got %d: %+v", len(expected), len(resp.Links), resp.Links)
+	}
+	for i, link := range resp.Links {
+		if link.Zone != expected[i].zone || link.Type != expected[i].rtype {
+			t.Errorf("link %d: expected %s %s, got %s %s", i, expected[i].zone, expected[i].rtype, link.Zone, link.Type)
+		}
+	}
+
+	// The target of the alias does not exist.
+	resp, err = client.ValidateDNSSEC(context.Background(), "lost.example", "A", []string{anchor})
+	if err != nil {
+		t.Fatalf("ValidateDNSSEC failed: %v", err)
+	}
+
+	last := resp.Links[len(resp.Links)-1]
+	if resp.Status != DNSSECSecure || last.Zone != "other." || !strings.Contains(last.Reason, "gone.other. does not exist") {
+		t.Errorf("expected a secure denial of the target, got %s: %+v", resp.Status, resp.Links)
+	}
+}
+
+func TestParseTrustAnchors_Invalid(t *testing.T) {
+	if _, err := parseTrustAnchors([]string{"example. IN DS 1 8 2 AABB"}); err == nil {
+		t.Error("expected error for non-root trust anchor")
+	}
+
+	if _, err := parseTrustAnchors([]string{"not a record"}); err == nil {
+		t.Error("expected error for malformed trust anchor")
+	}
+
+	anchors, err := parseTrustAnchors(DefaultTrustAnchors)
+	if err != nil {
+		t.Fatalf("failed to parse default trust anchors: %v", err)
+	}
+	if len(anchors) != 2 || anchors[0].KeyTag != 20326 {
+		t.Errorf("unexpected default trust anchors: %v", anchors)
+	}
+}
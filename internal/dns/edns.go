@@ -0,0 +1,281 @@
+package dns
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	mdns "github.com/miekg/dns"
+)
+
+// defaultPaddingBlock is the block size queries are padded to, as recommended
+// for clients by RFC 8467 section 4.1.
+const defaultPaddingBlock = 128
+
+// EDNSConfig controls the EDNS(0) OPT record attached to queries.
+type EDNSConfig struct {
+	// UDPSize is the advertised buffer size; defaultUDPSize is used when zero.
+	UDPSize  uint16
+	DNSSECOK bool
+	// ClientSubnet is sent as an EDNS Client Subnet option (RFC 7871).
+	ClientSubnet *net.IPNet
+	NSID         bool
+	// Cookie is the hex encoded DNS cookie (RFC 7873) sent with the query.
+	Cookie string
+	// Padding is the block size the query is padded to (RFC 7830).
+	Padding int
+}
+
+// WithEDNS sets the EDNS options attached to queries.
+func WithEDNS(config *EDNSConfig) Option {
+	return func(c *Client) {
+		c.edns = config
+	}
+}
+
+// ParseEDNSConfig builds an EDNSConfig from a client subnet, given as an
+// address or a CIDR prefix, and a list of "name[=value]" settings: nsid,
+// cookie[=hex], padding[=block size], bufsize=size and do. It returns nil when
+// nothing is set.
+func ParseEDNSConfig(subnet string, options []string) (*EDNSConfig, error) {
+	if subnet == "" && len(options) == 0 {
+		return nil, nil
+	}
+
+	config := &EDNSConfig{}
+
+	if subnet != "" {
+		ipNet, err := parseClientSubnet(subnet)
+		if err != nil {
+			return nil, err
+		}
+		config.ClientSubnet = ipNet
+	}
+
+	for _, option := range options {
+		name, value, hasValue := strings.Cut(strings.TrimSpace(option), "=")
+		switch strings.ToLower(name) {
+		case "nsid":
+			config.NSID = true
+		case "do":
+			config.DNSSECOK = true
+		case "bufsize":
+			size, err := strconv.ParseUint(value, 10, 16)
+			if err != nil || size < 512 {
+				return nil, fmt.Errorf("invalid EDNS buffer size %q, expected a number between 512 and 65535", value)
+			}
+			config.UDPSize = uint16(size)
+		case "cookie":
+			cookie, err := parseCookie(value, hasValue)
+			if err != nil {
+				return nil, err
+			}
+			config.Cookie = cookie
+		case "padding":
+			config.Padding = defaultPaddingBlock
+			if hasValue {
+				block, err := strconv.Atoi(value)
+				if err != nil || block < 1 || block > 65535 {
+					return nil, fmt.Errorf("invalid EDNS padding block size %q", value)
+				}
+				config.Padding = block
+			}
+		default:
+			return nil, fmt.Errorf("unsupported EDNS option %q (supported: nsid, cookie, padding, bufsize, do)", option)
+		}
+	}
+
+	return config, nil
+}
+
+// parseClientSubnet parses a CIDR prefix, or a single address which is used
+// with its full length.
+func parseClientSubnet(subnet string) (*net.IPNet, error) {
+	if !strings.Contains(subnet, "/") {
+		ip := net.ParseIP(subnet)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid client subnet %q", subnet)
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+	}
+
+	_, ipNet, err := net.ParseCIDR(subnet)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client subnet %q: %w", subnet, err)
+	}
+	return ipNet, nil
+}
+
+// parseCookie validates a hex encoded cookie: an 8 byte client cookie,
+// optionally followed by an 8 to 32 byte server cookie. A random client
+// cookie is generated when no value is given.
+func parseCookie(value string, hasValue bool) (string, error) {
+	if !hasValue {
+		b := make([]byte, 8)
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(b), nil
+	}
+
+	raw, err := hex.DecodeString(value)
+	if err != nil || (len(raw) != 8 && (len(raw) < 16 || len(raw) > 40)) {
+		return "", fmt.Errorf("invalid DNS cookie %q, expected 8 hex encoded bytes optionally followed by an 8 to 32 byte server cookie", value)
+	}
+	return strings.ToLower(value), nil
+}
+
+// setEDNS adds the OPT record described by the client's EDNS configuration to
+// m. The padding option is added last, once the size of the message is known.
+func (c *Client) setEDNS(m *mdns.Msg) {
+	config := c.edns
+	if config == nil {
+		m.SetEdns0(defaultUDPSize, false)
+		return
+	}
+
+	size := config.UDPSize
+	if size == 0 {
+		size = defaultUDPSize
+	}
+	m.SetEdns0(size, config.DNSSECOK)
+	opt := m.IsEdns0()
+
+	if config.ClientSubnet != nil {
+		ones, bits := config.ClientSubnet.Mask.Size()
+		subnet := &mdns.EDNS0_SUBNET{
+			Code:          mdns.EDNS0SUBNET,
+			Family:        1,
+			SourceNetmask: uint8(ones),
+			Address:       config.ClientSubnet.IP,
+		}
+		if bits == 128 {
+			subnet.Family = 2
+		}
+		opt.Option = append(opt.Option, subnet)
+	}
+
+	if config.NSID {
+		opt.Option = append(opt.Option, &mdns.EDNS0_NSID{Code: mdns.EDNS0NSID})
+	}
+
+	if config.Cookie != "" {
+		opt.Option = append(opt.Option, &mdns.EDNS0_COOKIE{Code: mdns.EDNS0COOKIE, Cookie: config.Cookie})
+	}
+
+	if config.Padding > 0 {
+		// The padding option itself adds a 4 byte header.
+		length := m.Len() + 4
+		padding := (config.Padding - length%config.Padding) % config.Padding
+		opt.Option = append(opt.Option, &mdns.EDNS0_PADDING{Padding: make([]byte, padding)})
+	}
+}
+
+// parseEDNS extracts the EDNS information from the OPT pseudo-record of r, or
+// returns nil when the response carries no OPT record.
+func parseEDNS(r *mdns.Msg) *EDNSInfo {
+	opt := r.IsEdns0()
+	if opt == nil {
+		return nil
+	}
+
+	info := &EDNSInfo{
+		Version:       opt.Version(),
+		UDPSize:       opt.UDPSize(),
+		ExtendedRcode: opt.ExtendedRcode(),
+		DNSSECOK:      opt.Do(),
+	}
+
+	for _, option := range opt.Option {
+		if subnet, ok := option.(*mdns.EDNS0_SUBNET); ok {
+			info.ClientSubnet = &ClientSubnet{
+				Address:      subnet.Address.String(),
+				SourcePrefix: subnet.SourceNetmask,
+				ScopePrefix:  subnet.SourceScope,
+			}
+		}
+		info.Options = append(info.Options, EDNSOption{
+			Code:  option.Option(),
+			Name:  ednsOptionName(option.Option()),
+			Value: ednsOptionValue(option),
+		})
+	}
+
+	return info
+}
+
+func ednsOptionName(code uint16) string {
+	switch code {
+	case mdns.EDNS0LLQ:
+		return "LLQ"
+	case mdns.EDNS0UL:
+		return "UL"
+	case mdns.EDNS0NSID:
+		return "NSID"
+	case mdns.EDNS0DAU:
+		return "DAU"
+	case mdns.EDNS0DHU:
+		return "DHU"
+	case mdns.EDNS0N3U:
+		return "N3U"
+	case mdns.EDNS0SUBNET:
+		return "ECS"
+	case mdns.EDNS0EXPIRE:
+		return "EXPIRE"
+	case mdns.EDNS0COOKIE:
+		return "COOKIE"
+	case mdns.EDNS0TCPKEEPALIVE:
+		return "TCP-KEEPALIVE"
+	case mdns.EDNS0PADDING:
+		return "PADDING"
+	case mdns.EDNS0EDE:
+		return "EDE"
+	default:
+		return fmt.Sprintf("OPT%d", code)
+	}
+}
+
+func ednsOptionValue(option mdns.EDNS0) string {
+	switch o := option.(type) {
+	case *mdns.EDNS0_NSID:
+		// The NSID is hex encoded; show the text form when it is printable.
+		raw, err := hex.DecodeString(o.Nsid)
+		if err == nil && isPrintable(raw) {
+			return fmt.Sprintf("%s (%q)", o.Nsid, string(raw))
+		}
+		return o.Nsid
+	case *mdns.EDNS0_EDE:
+		name := mdns.ExtendedErrorCodeToString[o.InfoCode]
+		if name == "" {
+			name = fmt.Sprintf("code %d", o.InfoCode)
+		}
+		if o.ExtraText != "" {
+			return fmt.Sprintf("%d (%s): %s", o.InfoCode, name, o.ExtraText)
+		}
+		return fmt.Sprintf("%d (%s)", o.InfoCode, name)
+	case *mdns.EDNS0_SUBNET:
+		return fmt.Sprintf("%s/%d, scope /%d", o.Address, o.SourceNetmask, o.SourceScope)
+	case *mdns.EDNS0_PADDING:
+		return fmt.Sprintf("%d bytes", len(o.Padding))
+	default:
+		return option.String()
+	}
+}
+
+func isPrintable(b []byte) bool {
+	if len(b) == 0 {
+		return false
+	}
+	for _, c := range b {
+		if c < 0x20 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,141 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	mdns "github.com/miekg/dns"
+)
+
+func TestClient_Query_EDNSConfig(t *testing.T) {
+	requests := make(chan *mdns.Msg, 1)
+	addr := startTestServer(t, func(w mdns.ResponseWriter, r *mdns.Msg) {
+		requests <- r
+
+		m := new(mdns.Msg)
+		m.SetReply(r)
+		m.Answer = append(m.Answer, mustRR(t, "cdn.example.com. 60 IN A 192.0.2.20"))
+
+		opt := new(mdns.OPT)
+		opt.Hdr.Name = "."
+		opt.Hdr.Rrtype = mdns.TypeOPT
+		opt.SetUDPSize(1232)
+		opt.Option = append(opt.Option, &mdns.EDNS0_SUBNET{
+			Code:          mdns.EDNS0SUBNET,
+			Family:        1,
+			SourceNetmask: 24,
+			SourceScope:   20,
+			Address:       net.ParseIP("203.0.113.0").To4(),
+		})
+		m.Extra = append(m.Extra, opt)
+
+		_ = w.WriteMsg(m)
+	})
+
+	config, err := ParseEDNSConfig("203.0.113.0/24", []string{"nsid", "cookie", "padding", "bufsize=4096", "do"})
+	if err != nil {
+		t.Fatalf("ParseEDNSConfig failed: %v", err)
+	}
+
+	client := NewClient(time.Second, addr, WithEDNS(config))
+
+	resp, err := client.Query(context.Background(), "cdn.example.com", "A")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	r := <-requests
+	opt := r.IsEdns0()
+	if opt == nil {
+		t.Fatal("expected the query to carry an OPT record")
+	}
+
+	if opt.UDPSize() != 4096 || !opt.Do() {
+		t.Errorf("expected udp 4096 with DO set, got udp %d do=%t", opt.UDPSize(), opt.Do())
+	}
+
+	options := make(map[uint16]mdns.EDNS0)
+	for _, option := range opt.Option {
+		options[option.Option()] = option
+	}
+
+	subnet, ok := options[mdns.EDNS0SUBNET].(*mdns.EDNS0_SUBNET)
+	if !ok {
+		t.Fatal("expected an ECS option in the query")
+	}
+	if subnet.Family != 1 || subnet.SourceNetmask != 24 || !subnet.Address.Equal(net.ParseIP("203.0.113.0")) {
+		t.Errorf("unexpected ECS option: %s", subnet)
+	}
+
+	for _, code := range []uint16{mdns.EDNS0NSID, mdns.EDNS0COOKIE, mdns.EDNS0PADDING} {
+		if _, ok := options[code]; !ok {
+			t.Errorf("expected %s option in the query", ednsOptionName(code))
+		}
+	}
+
+	if r.Len()%defaultPaddingBlock != 0 {
+		t.Errorf("expected the query to be padded to a multiple of %d bytes, got %d", defaultPaddingBlock, r.Len())
+	}
+
+	if resp.EDNS == nil || resp.EDNS.ClientSubnet == nil {
+		t.Fatal("expected the client subnet in the response")
+	}
+
+	ecs := resp.EDNS.ClientSubnet
+	if ecs.Address != "203.0.113.0" || ecs.SourcePrefix != 24 || ecs.ScopePrefix != 20 {
+		t.Errorf("unexpected client subnet: %+v", ecs)
+	}
+
+	if resp.EDNS.Options[0].Value != "203.0.113.0/24, scope /20" {
+		t.Errorf("unexpected ECS option value: %q", resp.EDNS.Options[0].Value)
+	}
+}
+
+func TestParseEDNSConfig(t *testing.T) {
+	config, err := ParseEDNSConfig("", nil)
+	if err != nil || config != nil {
+		t.Errorf("expected no config without settings, got %+v, %v", config, err)
+	}
+
+	config, err = ParseEDNSConfig("2001:db8::1", []string{"cookie=0102030405060708", "padding=468"})
+	if err != nil {
+		t.Fatalf("ParseEDNSConfig failed: %v", err)
+	}
+	if config.ClientSubnet.String() != "2001:db8::1/128" {
+		t.Errorf("expected 2001:db8::1/128, got %s", config.ClientSubnet)
+	}
+	if config.Cookie != "0102030405060708" || config.Padding != 468 {
+		t.Errorf("unexpected config: %+v", config)
+	}
+
+	config, err = ParseEDNSConfig("198.51.100.77/24", []string{"cookie"})
+	if err != nil {
+		t.Fatalf("ParseEDNSConfig failed: %v", err)
+	}
+	if config.ClientSubnet.String() != "198.51.100.0/24" {
+		t.Errorf("expected the subnet to be masked to 198.51.100.0/24, got %s", config.ClientSubnet)
+	}
+	if len(config.Cookie) != 16 {
+		t.Errorf("expected a random 8 byte client cookie, got %q", config.Cookie)
+	}
+
+	for _, tt := range []struct {
+		subnet  string
+		options []string
+	}{
+		{subnet: "not-an-address"},
+		{subnet: "203.0.113.0/33"},
+		{options: []string{"bufsize=100"}},
+		{options: []string{"bufsize"}},
+		{options: []string{"cookie=xyz"}},
+		{options: []string{"cookie=0102"}},
+		{options: []string{"padding=0"}},
+		{options: []string{"keepalive"}},
+	} {
+		if _, err := ParseEDNSConfig(tt.subnet, tt.options); err == nil {
+			t.Errorf("expected an error for subnet %q and options %v", tt.subnet, tt.options)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	mdns "github.com/miekg/dns"
+
+	"watchr/internal/idn"
+)
+
+// Enumerate resolves every label of the wordlist under zone for recordType,
+// using up to concurrency workers and sending at most rate queries per second
+// (no limit when rate is 0). Names answered with NOERROR exist, even when they
+// have no records of recordType. The zone is first probed for a wildcard, and
+// names whose answer is the wildcard's are left out of the results.
+func (c *Client) Enumerate(ctx context.Context, zone string, labels []string, recordType string, concurrency int, rate int) (*EnumResponse, error) {
+	qtype, err := parseRecordType(recordType)
+	if err != nil {
+		return nil, err
+	}
+	recordType = strings.ToUpper(recordType)
+
+	name, err := idn.Parse(mdns.CanonicalName(zone))
+	if err != nil {
+		return nil, err
+	}
+	zone = name.ASCII
+
+	names, err := enumNames(zone, labels)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no labels to enumerate")
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	start := time.Now()
+
+	wildcard, err := c.DetectWildcard(ctx, zone, recordType)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Debug("enumerating subdomains", "zone", zone, "candidates", len(names), "concurrency", concurrency, "rate", rate, "wildcard", wildcard.Detected)
+
+	// A closed channel never throttles; otherwise a ticker releases one query
+	// per tick.
+	var throttle <-chan time.Time
+	if rate > 0 {
+		ticker := time.NewTicker(max(time.Second/time.Duration(rate), time.Nanosecond))
+		defer ticker.Stop()
+		throttle = ticker.C
+	} else {
+		unlimited := make(chan time.Time)
+		close(unlimited)
+		throttle = unlimited
+	}
+
+	response := &EnumResponse{
+		Zone:       zone,
+		IDN:        name,
+		RecordType: recordType,
+		Nameserver: c.nameserver,
+		Candidates: len(names),
+		Wildcard:   wildcard,
+		Results:    make([]EnumResult, 0),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	queue := make(chan string)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range queue {
+				result, exists, wildcardMatch := c.enumName(ctx, name, qtype, recordType, wildcard)
+
+				mu.Lock()
+				switch {
+				case result.Error != "":
+					response.Failed++
+					response.Results = append(response.Results, result)
+				case !exists:
+				case wildcardMatch:
+					response.Filtered++
+				default:
+					response.Found++
+					response.Results = append(response.Results, result)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, name := range names {
+		select {
+		case <-ctx.Done():
+			break feed
+		case <-throttle:
+			queue <- name
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	sort.Slice(response.Results, func(i, j int) bool {
+		return response.Results[i].Name < response.Results[j].Name
+	})
+	response.QueryTime = time.Since(start)
+
+	if err := ctx.Err(); err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// enumName queries one candidate name and reports whether it exists, and
+// whether it is only answered by the wildcard: the zone has one and every
+// record of the answer is one of the wildcard's. An empty NOERROR answer is
+// never the wildcard's, as a wildcard does not cover names that exist.
+func (c *Client) enumName(ctx context.Context, name string, qtype uint16, recordType string, wildcard *Wildcard) (EnumResult, bool, bool) {
+	result := EnumResult{Name: name}
+
+	resp, err := c.query(ctx, name, qtype, recordType)
+	if err != nil {
+		slog.Debug("DNS query failed", "domain", name, "type", recordType, "error", err)
+		result.Error = err.Error()
+		return result, false, false
+	}
+	result.Rcode = resp.Rcode
+	result.Records = resp.Records
+
+	if resp.Rcode != rcodeString(mdns.RcodeSuccess) {
+		return result, false, false
+	}
+
+	if !wildcard.Detected || len(resp.Records) == 0 {
+		return result, true, false
+	}
+	for _, record := range resp.Records {
+		if !wildcard.Matches(record) {
+			return result, true, false
+		}
+	}
+
+	return result, true, true
+}
+
+// enumNames returns the names to query for the labels, in order and without
+// duplicates.
+func enumNames(zone string, labels []string) ([]string, error) {
+	zone = mdns.CanonicalName(zone)
+
+	names := make([]string, 0, len(labels))
+	seen := make(map[string]bool, len(labels))
+	for _, label := range labels {
+		label = strings.Trim(strings.ToLower(strings.TrimSpace(label)), ".")
+		if label == "" {
+			continue
+		}
+
+		label, err := idn.ToASCII(label)
+		if err != nil {
+			return nil, err
+		}
+
+		name := label + "." + zone
+		if zone == "." {
+			name = label + "."
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+
+	return names, nil
+}
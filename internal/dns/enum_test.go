@@ -0,0 +1,146 @@
+package dns
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	mdns "github.com/miekg/dns"
+)
+
+func TestClient_Enumerate(t *testing.T) {
+	addr := startTestServer(t, wildcardZoneHandler(t, wildcardZone[:4]...))
+
+	client := NewClient(time.Second, addr)
+
+	resp, err := client.Enumerate(context.Background(), "example.com", []string{"www", "mail", "ftp", "WWW", "", "vpn"}, "A", 4, 0)
+	if err != nil {
+		t.Fatalf("Enumerate failed: %v", err)
+	}
+
+	if resp.Candidates != 4 {
+		t.Errorf("expected 4 candidates after removing duplicates, got %d", resp.Candidates)
+	}
+	if resp.Wildcard == nil || resp.Wildcard.Detected {
+		t.Errorf("expected no wildcard, got %+v", resp.Wildcard)
+	}
+
+	names := make([]string, 0, len(resp.Results))
+	for _, result := range resp.Results {
+		names = append(names, result.Name)
+	}
+	// mail.example.com. has no A record, but exists.
+	if !reflect.DeepEqual(names, []string{"mail.example.com.", "www.example.com."}) {
+		t.Errorf("unexpected names: %v", names)
+	}
+	if resp.Found != 2 || resp.Filtered != 0 || resp.Failed != 0 {
+		t.Errorf("unexpected counts: found %d, filtered %d, failed %d", resp.Found, resp.Filtered, resp.Failed)
+	}
+}
+
+func TestClient_Enumerate_Wildcard(t *testing.T) {
+	zone := append([]string{}, wildcardZone...)
+	zone = append(zone, "api.example.com. 300 IN A 192.0.2.99")
+	addr := startTestServer(t, wildcardZoneHandler(t, zone...))
+
+	client := NewClient(time.Second, addr)
+
+	resp, err := client.Enumerate(context.Background(), "example.com", []string{"www", "mail", "ftp", "vpn", "api"}, "A", 2, 0)
+	if err != nil {
+		t.Fatalf("Enumerate failed: %v", err)
+	}
+
+	if resp.Wildcard == nil || !resp.Wildcard.Detected {
+		t.Fatalf("expected a wildcard, got %+v", resp.Wildcard)
+	}
+
+	// api.example.com. exists, but its answer cannot be told apart from the
+	// wildcard's. mail.example.com. has no A record, and the wildcard does not
+	// cover it, so its empty answer proves it exists.
+	names := make([]string, 0, len(resp.Results))
+	for _, result := range resp.Results {
+		names = append(names, result.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"mail.example.com.", "www.example.com."}) {
+		t.Errorf("expected mail.example.com. and www.example.com., got %+v", resp.Results)
+	}
+	if resp.Found != 2 || resp.Filtered != 3 {
+		t.Errorf("unexpected counts: found %d, filtered %d", resp.Found, resp.Filtered)
+	}
+}
+
+func TestClient_Enumerate_Rate(t *testing.T) {
+	addr := startTestServer(t, wildcardZoneHandler(t, wildcardZone[:4]...))
+
+	client := NewClient(time.Second, addr)
+
+	start := time.Now()
+	_, err := client.Enumerate(context.Background(), "example.com", []string{"a", "b", "c", "d", "e"}, "A", 5, 20)
+	if err != nil {
+		t.Fatalf("Enumerate failed: %v", err)
+	}
+
+	// Five queries at 20 per second take at least 200ms.
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("expected the queries to be rate limited, took %v", elapsed)
+	}
+}
+
+func TestClient_Enumerate_Failures(t *testing.T) {
+	addr := startTestServer(t, func(w mdns.ResponseWriter, r *mdns.Msg) {
+		m := new(mdns.Msg)
+		m.SetRcode(r, mdns.RcodeNameError)
+		if r.Question[0].Name == "down.example.com." {
+			return
+		}
+		_ = w.WriteMsg(m)
+	})
+
+	client := NewClient(200*time.Millisecond, addr)
+
+	resp, err := client.Enumerate(context.Background(), "example.com", []string{"www", "down"}, "A", 2, 0)
+	if err != nil {
+		t.Fatalf("Enumerate failed: %v", err)
+	}
+
+	if resp.Failed != 1 || len(resp.Results) != 1 || resp.Results[0].Error == "" {
+		t.Errorf("expected the unanswered query to be reported, got %+v", resp.Results)
+	}
+}
+
+func TestClient_Enumerate_IDN(t *testing.T) {
+	zone := append([]string{}, wildcardZone[:4]...)
+	zone = append(zone, "xn--bcher-kva.example.com. 300 IN A 192.0.2.20")
+	addr := startTestServer(t, wildcardZoneHandler(t, zone...))
+
+	client := NewClient(time.Second, addr)
+
+	resp, err := client.Enumerate(context.Background(), "example.com", []string{"Bücher", "xn--bcher-kva"}, "A", 1, 0)
+	if err != nil {
+		t.Fatalf("Enumerate failed: %v", err)
+	}
+
+	if resp.Candidates != 1 {
+		t.Errorf("expected the Unicode label and its A-label to be one candidate, got %d", resp.Candidates)
+	}
+	if resp.Found != 1 || len(resp.Results) != 1 || resp.Results[0].Name != "xn--bcher-kva.example.com." {
+		t.Errorf("expected xn--bcher-kva.example.com. to be found, got %+v", resp.Results)
+	}
+}
+
+func TestClient_Enumerate_InvalidLabel(t *testing.T) {
+	client := NewClient(time.Second, "127.0.0.1:53")
+
+	if _, err := client.Enumerate(context.Background(), "example.com", []string{"www", "bü\u200dcher"}, "A", 1, 0); err == nil {
+		t.Error("expected an error for a label that is not a valid IDN")
+	}
+}
+
+func TestClient_Enumerate_NoLabels(t *testing.T) {
+	client := NewClient(time.Second, "127.0.0.1:53")
+
+	if _, err := client.Enumerate(context.Background(), "example.com", []string{"", " "}, "A", 1, 0); err == nil {
+		t.Error("expected an error without labels")
+	}
+}
@@ -0,0 +1,134 @@
+package dns
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	mdns "github.com/miekg/dns"
+)
+
+// resolvConfPath is the resolver configuration used when no nameserver is
+// given.
+var resolvConfPath = "/etc/resolv.conf"
+
+// localNameserver is used when the configuration lists no nameserver, as
+// described in resolv.conf(5).
+const localNameserver = "127.0.0.1"
+
+// ResolvConf holds the resolver settings of a resolv.conf(5) file.
+type ResolvConf struct {
+	Servers  []string
+	Search   []string
+	Ndots    int
+	Timeout  time.Duration
+	Attempts int
+	Rotate   bool
+}
+
+// ReadResolvConf reads the resolver configuration from path.
+func ReadResolvConf(path string) (*ResolvConf, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	return ParseResolvConf(file)
+}
+
+// ParseResolvConf parses a resolv.conf(5) file. Unset options get the
+// defaults of the system resolver: ndots:1, timeout:5 and attempts:2.
+func ParseResolvConf(r io.Reader) (*ResolvConf, error) {
+	conf := &ResolvConf{
+		Servers:  make([]string, 0),
+		Search:   make([]string, 0),
+		Ndots:    1,
+		Timeout:  5 * time.Second,
+		Attempts: 2,
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], ";") {
+			continue
+		}
+
+		switch fields[0] {
+		case "nameserver":
+			if len(fields) > 1 {
+				conf.Servers = append(conf.Servers, fields[1])
+			}
+		case "domain":
+			// The last of the domain and search keywords wins.
+			conf.Search = make([]string, 0, 1)
+			if len(fields) > 1 {
+				conf.Search = append(conf.Search, fields[1])
+			}
+		case "search":
+			conf.Search = append(make([]string, 0, len(fields)-1), fields[1:]...)
+		case "options":
+			for _, option := range fields[1:] {
+				parseResolvOption(conf, option)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return conf, nil
+}
+
+func parseResolvOption(conf *ResolvConf, option string) {
+	name, value, _ := strings.Cut(option, ":")
+	n, err := strconv.Atoi(value)
+
+	switch name {
+	case "ndots":
+		if err == nil {
+			conf.Ndots = min(max(n, 0), 15)
+		}
+	case "timeout":
+		if err == nil {
+			conf.Timeout = time.Duration(min(max(n, 1), 30)) * time.Second
+		}
+	case "attempts":
+		if err == nil {
+			conf.Attempts = min(max(n, 1), 5)
+		}
+	case "rotate":
+		conf.Rotate = true
+	}
+}
+
+// NameList returns the names to query for name, applying the search list:
+// names ending with a dot are only tried as given, names with at least ndots
+// dots are tried as given before the search domains, and other names after
+// them.
+func (conf *ResolvConf) NameList(name string) []string {
+	if mdns.IsFqdn(name) {
+		return []string{name}
+	}
+
+	names := make([]string, 0, len(conf.Search)+1)
+	absolute := strings.Count(name, ".") >= conf.Ndots
+	if absolute {
+		names = append(names, mdns.Fqdn(name))
+	}
+	for _, domain := range conf.Search {
+		names = append(names, mdns.Fqdn(name+"."+strings.TrimSuffix(domain, ".")))
+	}
+	if !absolute {
+		names = append(names, mdns.Fqdn(name))
+	}
+
+	return names
+}
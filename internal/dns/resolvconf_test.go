@@ -0,0 +1,242 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	mdns "github.com/miekg/dns"
+)
+
+// useResolvConf points the system resolver configuration at a temporary file
+// with the given contents for the duration of the test.
+func useResolvConf(t *testing.T, contents string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "resolv.conf")
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("failed to write resolv.conf: %v", err)
+	}
+
+	previous := resolvConfPath
+	resolvConfPath = path
+	t.Cleanup(func() {
+		resolvConfPath = previous
+	})
+}
+
+// deadNameserver returns the address of a UDP port nothing listens on.
+func deadNameserver(t *testing.T) string {
+	t.Helper()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen on UDP: %v", err)
+	}
+	addr := pc.LocalAddr().String()
+	_ = pc.Close()
+
+	return addr
+}
+
+func TestParseResolvConf(t *testing.T) {
+	conf, err := ParseResolvConf(strings.NewReader(`# generated
+nameserver 192.0.2.1
+nameserver 2001:db8::53
+domain example.net
+search corp.example.com example.com
+; comment
+options ndots:2 timeout:3 attempts:4 rotate edns0
+`))
+	if err != nil {
+		t.Fatalf("ParseResolvConf failed: %v", err)
+	}
+
+	expected := &ResolvConf{
+		Servers:  []string{"192.0.2.1", "2001:db8::53"},
+		Search:   []string{"corp.example.com", "example.com"},
+		Ndots:    2,
+		Timeout:  3 * time.Second,
+		Attempts: 4,
+		Rotate:   true,
+	}
+	if !reflect.DeepEqual(conf, expected) {
+		t.Errorf("ParseResolvConf = %+v, want %+v", conf, expected)
+	}
+}
+
+func TestParseResolvConf_Defaults(t *testing.T) {
+	conf, err := ParseResolvConf(strings.NewReader("search example.com\ndomain example.net\noptions ndots:99 attempts:0\n"))
+	if err != nil {
+		t.Fatalf("ParseResolvConf failed: %v", err)
+	}
+
+	if len(conf.Servers) != 0 {
+		t.Errorf("expected no servers, got %v", conf.Servers)
+	}
+	if !reflect.DeepEqual(conf.Search, []string{"example.net"}) {
+		t.Errorf("expected the last domain keyword to win, got %v", conf.Search)
+	}
+	if conf.Ndots != 15 || conf.Attempts != 1 || conf.Timeout != 5*time.Second || conf.Rotate {
+		t.Errorf("unexpected options: %+v", conf)
+	}
+}
+
+func TestResolvConf_NameList(t *testing.T) {
+	conf := &ResolvConf{Search: []string{"corp.example.com", "example.com."}, Ndots: 1}
+
+	tests := []struct {
+		name     string
+		ndots    int
+		expected []string
+	}{
+		{"www", 1, []string{"www.corp.example.com.", "www.example.com.", "www."}},
+		{"www.example.org", 1, []string{"www.example.org.", "www.example.org.corp.example.com.", "www.example.org.example.com."}},
+		{"www.example.org", 3, []string{"www.example.org.corp.example.com.", "www.example.org.example.com.", "www.example.org."}},
+		{"www.example.org.", 1, []string{"www.example.org."}},
+	}
+
+	for _, tt := range tests {
+		conf.Ndots = tt.ndots
+		if names := conf.NameList(tt.name); !reflect.DeepEqual(names, tt.expected) {
+			t.Errorf("NameList(%q) with ndots %d = %v, want %v", tt.name, tt.ndots, names, tt.expected)
+		}
+	}
+}
+
+func TestNewClient_SystemResolverFallback(t *testing.T) {
+	useResolvConf(t, "search example.com\n")
+
+	client := NewClient(time.Second, "")
+	if client.nameserver != "127.0.0.1:53" {
+		t.Errorf("expected the local nameserver, got %s", client.nameserver)
+	}
+
+	resolvConfPath = filepath.Join(t.TempDir(), "missing.conf")
+	client = NewClient(time.Second, "")
+	if client.nameserver != "127.0.0.1:53" {
+		t.Errorf("expected the local nameserver, got %s", client.nameserver)
+	}
+}
+
+func TestClient_Query_Failover(t *testing.T) {
+	dead := deadNameserver(t)
+	failing := startTestServer(t, func(w mdns.ResponseWriter, r *mdns.Msg) {
+		m := new(mdns.Msg)
+		m.SetRcode(r, mdns.RcodeServerFailure)
+		_ = w.WriteMsg(m)
+	})
+	working := startTestServer(t, zoneHandler("example.com. 300 IN A 192.0.2.1"))
+
+	useResolvConf(t, "nameserver "+dead+"\nnameserver "+failing+"\nnameserver "+working+"\noptions timeout:1\n")
+
+	client := NewClient(5*time.Second, "")
+
+	resp, err := client.Query(context.Background(), "example.com", "A")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	if resp.Nameserver != working {
+		t.Errorf("expected the answer to come from %s, got %s", working, resp.Nameserver)
+	}
+
+	if len(resp.Records) != 1 || resp.Records[0].Value != "192.0.2.1" {
+		t.Errorf("unexpected records: %+v", resp.Records)
+	}
+
+	if len(resp.Attempts) != 3 {
+		t.Fatalf("expected 3 attempts, got %+v", resp.Attempts)
+	}
+	if resp.Attempts[0].Server != dead || resp.Attempts[0].Error == "" {
+		t.Errorf("expected the first attempt to fail, got %+v", resp.Attempts[0])
+	}
+	if resp.Attempts[1].Rcode != "SERVFAIL" {
+		t.Errorf("expected the second attempt to return SERVFAIL, got %+v", resp.Attempts[1])
+	}
+	if resp.Attempts[2].Rcode != "NOERROR" {
+		t.Errorf("expected the third attempt to succeed, got %+v", resp.Attempts[2])
+	}
+}
+
+func TestClient_Query_Attempts(t *testing.T) {
+	dead := deadNameserver(t)
+	useResolvConf(t, "nameserver "+dead+"\noptions attempts:3 timeout:1\n")
+
+	client := NewClient(time.Second, "")
+
+	_, err := client.Query(context.Background(), "example.com", "A")
+	if err == nil || !strings.Contains(err.Error(), "after 3 attempts") {
+		t.Errorf("expected the query to fail after 3 attempts, got %v", err)
+	}
+}
+
+func TestClient_Query_Rotate(t *testing.T) {
+	first := startTestServer(t, zoneHandler("example.com. 300 IN A 192.0.2.1"))
+	second := startTestServer(t, zoneHandler("example.com. 300 IN A 192.0.2.2"))
+	useResolvConf(t, "nameserver "+first+"\nnameserver "+second+"\noptions rotate\n")
+
+	client := NewClient(time.Second, "")
+
+	servers := make([]string, 0, 3)
+	for i := 0; i < 3; i++ {
+		resp, err := client.Query(context.Background(), "example.com", "A")
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		servers = append(servers, resp.Nameserver)
+	}
+
+	if !reflect.DeepEqual(servers, []string{first, second, first}) {
+		t.Errorf("expected queries to rotate over the servers, got %v", servers)
+	}
+}
+
+func TestClient_Query_Search(t *testing.T) {
+	addr := startTestServer(t, zoneHandler(
+		"www.example.com. 300 IN A 192.0.2.1",
+		"mail.corp.example.com. 300 IN MX 10 mx.corp.example.com.",
+	))
+	useResolvConf(t, "nameserver "+addr+"\nsearch corp.example.com example.com\n")
+
+	client := NewClient(time.Second, "", WithSearch(true))
+
+	resp, err := client.Query(context.Background(), "www", "A")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	if resp.Domain != "www.example.com." {
+		t.Errorf("expected www.example.com., got %s", resp.Domain)
+	}
+	if !reflect.DeepEqual(resp.Searched, []string{"www.corp.example.com.", "www.example.com."}) {
+		t.Errorf("unexpected searched names: %v", resp.Searched)
+	}
+
+	// mail.corp.example.com exists but has no A record.
+	resp, err = client.Query(context.Background(), "mail", "A")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	if resp.Domain != "mail.corp.example.com." || resp.Rcode != "NOERROR" {
+		t.Errorf("expected the NODATA answer for mail.corp.example.com., got %s %s", resp.Domain, resp.Rcode)
+	}
+	if len(resp.Searched) != 3 {
+		t.Errorf("expected every name to be searched, got %v", resp.Searched)
+	}
+
+	client = NewClient(time.Second, "")
+	resp, err = client.Query(context.Background(), "www", "A")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if resp.Domain != "www." || resp.Searched != nil {
+		t.Errorf("expected no search without WithSearch, got %s %v", resp.Domain, resp.Searched)
+	}
+}
@@ -0,0 +1,94 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"time"
+
+	mdns "github.com/miekg/dns"
+)
+
+// ReverseName returns the in-addr.arpa or ip6.arpa name used to look up the
+// PTR records of an IPv4 or IPv6 address.
+func ReverseName(address string) (string, error) {
+	if net.ParseIP(address) == nil {
+		return "", fmt.Errorf("invalid IP address: %s", address)
+	}
+
+	return mdns.ReverseAddr(address)
+}
+
+// ForwardConfirm performs a forward-confirmed reverse DNS (FCrDNS) check: it
+// looks up the PTR records of address and resolves every hostname found back
+// to addresses of the same family, confirming the check when one of them
+// matches address.
+func (c *Client) ForwardConfirm(ctx context.Context, address string) (*FCrDNSResponse, error) {
+	name, err := ReverseName(address)
+	if err != nil {
+		return nil, err
+	}
+
+	ip := net.ParseIP(address)
+	forwardType := "AAAA"
+	if ip.To4() != nil {
+		forwardType = "A"
+	}
+
+	slog.Debug("checking forward-confirmed reverse DNS", "address", address, "name", name)
+	start := time.Now()
+
+	resp, err := c.Query(ctx, name, "PTR")
+	if err != nil {
+		return nil, err
+	}
+
+	response := &FCrDNSResponse{
+		IP:          address,
+		ReverseName: name,
+		Nameserver:  c.nameserver,
+		Rcode:       resp.Rcode,
+		PTR:         make([]Record, 0),
+		Hostnames:   make([]FCrDNSHostname, 0),
+	}
+
+	for _, record := range resp.Records {
+		if record.Type != "PTR" {
+			continue
+		}
+		response.PTR = append(response.PTR, record)
+
+		hostname := FCrDNSHostname{
+			Hostname:  record.Value,
+			Addresses: make([]string, 0),
+		}
+
+		forward, err := c.Query(ctx, record.Value, forwardType)
+		if err != nil {
+			hostname.Error = err.Error()
+		} else {
+			for _, fr := range forward.Records {
+				if fr.Type != forwardType {
+					continue
+				}
+				hostname.Addresses = append(hostname.Addresses, fr.Value)
+				if net.ParseIP(fr.Value).Equal(ip) {
+					hostname.Matched = true
+				}
+			}
+			if len(hostname.Addresses) == 0 {
+				hostname.Error = fmt.Sprintf("no %s records found (%s)", forwardType, forward.Rcode)
+			}
+		}
+
+		if hostname.Matched {
+			response.Confirmed = true
+		}
+		response.Hostnames = append(response.Hostnames, hostname)
+	}
+
+	response.QueryTime = time.Since(start)
+
+	return response, nil
+}
@@ -0,0 +1,104 @@
+package dns
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestReverseName(t *testing.T) {
+	tests := []struct {
+		address  string
+		expected string
+	}{
+		{"192.0.2.10", "10.2.0.192.in-addr.arpa."},
+		{"2001:db8::1", "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa."},
+	}
+
+	for _, tt := range tests {
+		name, err := ReverseName(tt.address)
+		if err != nil {
+			t.Fatalf("ReverseName(%q) failed: %v", tt.address, err)
+		}
+		if name != tt.expected {
+			t.Errorf("ReverseName(%q) = %q, want %q", tt.address, name, tt.expected)
+		}
+	}
+
+	if _, err := ReverseName("example.com"); err == nil {
+		t.Error("expected error for a non-IP address")
+	}
+}
+
+func TestClient_ForwardConfirm(t *testing.T) {
+	addr := startTestServer(t, zoneHandler(
+		"10.2.0.192.in-addr.arpa. 300 IN PTR mail.example.com.",
+		"10.2.0.192.in-addr.arpa. 300 IN PTR stale.example.com.",
+		"mail.example.com. 300 IN A 192.0.2.10",
+		"stale.example.com. 300 IN A 192.0.2.99",
+	))
+
+	client := NewClient(5*time.Second, addr)
+
+	resp, err := client.ForwardConfirm(context.Background(), "192.0.2.10")
+	if err != nil {
+		t.Fatalf("ForwardConfirm failed: %v", err)
+	}
+
+	if resp.ReverseName != "10.2.0.192.in-addr.arpa." {
+		t.Errorf("unexpected reverse name: %s", resp.ReverseName)
+	}
+
+	if !resp.Confirmed {
+		t.Error("expected the check to be confirmed")
+	}
+
+	if len(resp.PTR) != 2 || len(resp.Hostnames) != 2 {
+		t.Fatalf("expected 2 PTR records and hostnames, got %d and %d", len(resp.PTR), len(resp.Hostnames))
+	}
+
+	for _, hostname := range resp.Hostnames {
+		switch hostname.Hostname {
+		case "mail.example.com.":
+			if !hostname.Matched {
+				t.Error("expected mail.example.com. to match")
+			}
+		case "stale.example.com.":
+			if hostname.Matched {
+				t.Error("expected stale.example.com. not to match")
+			}
+		default:
+			t.Errorf("unexpected hostname: %s", hostname.Hostname)
+		}
+	}
+}
+
+func TestClient_ForwardConfirm_Mismatch(t *testing.T) {
+	addr := startTestServer(t, zoneHandler(
+		"1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa. 300 IN PTR host.example.com.",
+		"host.example.com. 300 IN A 192.0.2.1",
+	))
+
+	client := NewClient(5*time.Second, addr)
+
+	resp, err := client.ForwardConfirm(context.Background(), "2001:db8::1")
+	if err != nil {
+		t.Fatalf("ForwardConfirm failed: %v", err)
+	}
+
+	if resp.Confirmed {
+		t.Error("expected the check not to be confirmed")
+	}
+
+	if len(resp.Hostnames) != 1 || resp.Hostnames[0].Error == "" {
+		t.Errorf("expected an error for the missing AAAA records, got %+v", resp.Hostnames)
+	}
+}
+
+func TestClient_ForwardConfirm_InvalidAddress(t *testing.T) {
+	client := NewClient(5*time.Second, "127.0.0.1:53")
+
+	if _, err := client.ForwardConfirm(context.Background(), "not-an-ip"); err == nil {
+		t.Error("expected error for an invalid address")
+	}
+}
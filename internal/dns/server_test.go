@@ -0,0 +1,241 @@
+package dns
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	mdns "github.com/miekg/dns"
+)
+
+// startTestServer runs a local DNS server on UDP and TCP using the same port
+// and returns its address. The servers are shut down when the test finishes.
+func startTestServer(t *testing.T, handler mdns.HandlerFunc) string {
+	t.Helper()
+
+	return startTestServerAt(t, "127.0.0.1:0", handler)
+}
+
+// listenAttempts is how many ports startTestServerAt tries when asked for a
+// random one.
+const listenAttempts = 20
+
+// startTestServerAt is like startTestServer but listens on the given address.
+// When the port is 0, a port free for both TCP and UDP is picked.
+func startTestServerAt(t *testing.T, address string, handler mdns.HandlerFunc) string {
+	t.Helper()
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		t.Fatalf("invalid address %s: %v", address, err)
+	}
+
+	attempts := 1
+	if port == "0" {
+		attempts = listenAttempts
+	}
+
+	var (
+		l  net.Listener
+		pc net.PacketConn
+	)
+	for i := 0; i < attempts; i++ {
+		l, err = net.Listen("tcp", address)
+		if err != nil {
+			t.Fatalf("failed to listen on TCP: %v", err)
+		}
+
+		// The kernel picks the TCP port, and the same port number may
+		// already be taken for UDP, so try another one when it is.
+		_, port, _ = net.SplitHostPort(l.Addr().String())
+		pc, err = net.ListenPacket("udp", net.JoinHostPort(host, port))
+		if err == nil {
+			break
+		}
+		_ = l.Close()
+	}
+	if err != nil {
+		t.Fatalf("failed to listen on UDP: %v", err)
+	}
+	addr := l.Addr().String()
+
+	udpServer := &mdns.Server{PacketConn: pc, Handler: handler}
+	tcpServer := &mdns.Server{Listener: l, Handler: handler}
+
+	go func() {
+		_ = udpServer.ActivateAndServe()
+	}()
+	go func() {
+		_ = tcpServer.ActivateAndServe()
+	}()
+
+	t.Cleanup(func() {
+		_ = udpServer.Shutdown()
+		_ = tcpServer.Shutdown()
+	})
+
+	return addr
+}
+
+// zoneHandler answers every question from the given records, matching on
+// owner name and type.
+func zoneHandler(records ...string) mdns.HandlerFunc {
+	rrs := make([]mdns.RR, 0, len(records))
+	for _, record := range records {
+		rr, err := mdns.NewRR(record)
+		if err != nil {
+			panic(err)
+		}
+		rrs = append(rrs, rr)
+	}
+
+	return func(w mdns.ResponseWriter, r *mdns.Msg) {
+		m := new(mdns.Msg)
+		m.SetReply(r)
+
+		q := r.Question[0]
+		for _, rr := range rrs {
+			header := rr.Header()
+			if mdns.CanonicalName(header.Name) == mdns.CanonicalName(q.Name) && header.Rrtype == q.Qtype {
+				m.Answer = append(m.Answer, rr)
+			}
+		}
+
+		_ = w.WriteMsg(m)
+	}
+}
+
+// newTestCertificate returns a self-signed certificate valid for 127.0.0.1
+// and "dot.test", along with a pool that trusts it.
+func newTestCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "dot.test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"dot.test"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, pool
+}
+
+// startTLSTestServer runs a local DNS-over-TLS server and returns its address
+// along with a pool that trusts its certificate.
+func startTLSTestServer(t *testing.T, handler mdns.HandlerFunc) (string, *x509.CertPool, *x509.Certificate) {
+	t.Helper()
+
+	cert, pool := newTestCertificate(t)
+
+	l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatalf("failed to listen on TLS: %v", err)
+	}
+
+	srv := &mdns.Server{Listener: l, Net: "tcp-tls", Handler: handler}
+	go func() {
+		_ = srv.ActivateAndServe()
+	}()
+
+	t.Cleanup(func() {
+		_ = srv.Shutdown()
+	})
+
+	return l.Addr().String(), pool, cert.Leaf
+}
+
+// wildcardZoneHandler answers like the authoritative server of the zone made
+// of the given records, which must include its SOA: names without records get
+// NXDOMAIN, types without records get NODATA, both with the SOA in the
+// authority section, and a "*" label answers for the names it covers.
+func wildcardZoneHandler(t *testing.T, records ...string) mdns.HandlerFunc {
+	rrs := make([]mdns.RR, 0, len(records))
+	var soa mdns.RR
+	for _, record := range records {
+		rr := mustRR(t, record)
+		if rr.Header().Rrtype == mdns.TypeSOA {
+			soa = rr
+		}
+		rrs = append(rrs, rr)
+	}
+	if soa == nil {
+		t.Fatal("wildcardZoneHandler needs a SOA record")
+	}
+
+	return func(w mdns.ResponseWriter, r *mdns.Msg) {
+		m := new(mdns.Msg)
+		m.SetReply(r)
+		m.Authoritative = true
+
+		q := r.Question[0]
+		qname := mdns.CanonicalName(q.Name)
+
+		owner := ""
+		for _, name := range []string{qname, "*." + qname[strings.Index(qname, ".")+1:]} {
+			for _, rr := range rrs {
+				if mdns.CanonicalName(rr.Header().Name) == name {
+					owner = name
+				}
+			}
+			if owner != "" {
+				break
+			}
+		}
+
+		if owner == "" {
+			m.Rcode = mdns.RcodeNameError
+			m.Ns = append(m.Ns, soa)
+			_ = w.WriteMsg(m)
+			return
+		}
+
+		for _, rr := range rrs {
+			header := rr.Header()
+			if mdns.CanonicalName(header.Name) != owner {
+				continue
+			}
+			if header.Rrtype == q.Qtype || header.Rrtype == mdns.TypeCNAME {
+				answer := mdns.Copy(rr)
+				answer.Header().Name = q.Name
+				m.Answer = append(m.Answer, answer)
+			}
+		}
+		if len(m.Answer) == 0 {
+			m.Ns = append(m.Ns, soa)
+		}
+
+		_ = w.WriteMsg(m)
+	}
+}
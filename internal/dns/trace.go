@@ -0,0 +1,323 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	mdns "github.com/miekg/dns"
+
+	"watchr/internal/idn"
+)
+
+const (
+	maxTraceHops   = 32
+	maxTraceCNAMEs = 8
+)
+
+// RootHints are the IPv4 addresses of the root servers, as "name=address".
+var RootHints = []string{
+	"a.root-servers.net=198.41.0.4",
+	"b.root-servers.net=170.247.170.2",
+	"c.root-servers.net=192.33.4.12",
+	"d.root-servers.net=199.7.91.13",
+	"e.root-servers.net=192.203.230.10",
+	"f.root-servers.net=192.5.5.241",
+	"g.root-servers.net=192.112.36.4",
+	"h.root-servers.net=198.97.190.53",
+	"i.root-servers.net=192.36.148.17",
+	"j.root-servers.net=192.58.128.30",
+	"k.root-servers.net=193.0.14.129",
+	"l.root-servers.net=199.7.83.42",
+	"m.root-servers.net=202.12.27.33",
+}
+
+type traceServer struct {
+	name    string
+	address string
+}
+
+// Trace resolves domain iteratively, starting from the root hints and
+// following referrals down to the authoritative servers, recording every hop.
+// Each root hint is given as "name=address"; when roots is empty RootHints is
+// used.
+func (c *Client) Trace(ctx context.Context, domain string, recordType string, roots []string) (*TraceResponse, error) {
+	qtype, err := parseRecordType(recordType)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := idn.Parse(mdns.Fqdn(domain))
+	if err != nil {
+		return nil, err
+	}
+	domain = parsed.ASCII
+
+	if len(roots) == 0 {
+		roots = RootHints
+	}
+
+	rootServers, err := c.parseRootHints(roots)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &TraceResponse{
+		Domain:     domain,
+		IDN:        parsed,
+		RecordType: strings.ToUpper(recordType),
+		Hops:       make([]TraceHop, 0),
+		Records:    make([]Record, 0),
+	}
+
+	slog.Debug("tracing DNS resolution", "domain", domain, "type", recordType)
+	start := time.Now()
+
+	name := domain
+	zone := "."
+	servers := rootServers
+	cnames := 0
+
+	for len(response.Hops) < maxTraceHops {
+		hop, r := c.traceHop(ctx, name, qtype, zone, servers)
+		response.Hops = append(response.Hops, hop)
+
+		if r == nil {
+			response.Error = hop.Error
+			break
+		}
+
+		if len(r.Answer) > 0 {
+			target := cnameTarget(r.Answer, name, qtype)
+			if target == "" {
+				for _, ans := range r.Answer {
+					if record := parseAnswer(ans); record != nil {
+						response.Records = append(response.Records, *record)
+					}
+				}
+				break
+			}
+
+			cnames++
+			if cnames > maxTraceCNAMEs {
+				response.Error = fmt.Sprintf("too many CNAMEs (more than %d)", maxTraceCNAMEs)
+				break
+			}
+
+			// Restart from the root for the CNAME target.
+			name = target
+			zone = "."
+			servers = rootServers
+			continue
+		}
+
+		if r.Rcode != mdns.RcodeSuccess || r.Authoritative || hop.Referral == "" {
+			if r.Rcode == mdns.RcodeSuccess && !r.Authoritative && hop.Referral == "" {
+				response.Error = fmt.Sprintf("%s (%s) returned neither an answer nor a referral", hop.Server, hop.Address)
+			}
+			break
+		}
+
+		next, err := c.referralServers(ctx, r, hop.Referral, hop.Nameservers)
+		if err != nil {
+			response.Error = err.Error()
+			break
+		}
+
+		zone = hop.Referral
+		servers = next
+	}
+
+	if len(response.Hops) >= maxTraceHops && response.Error == "" && len(response.Records) == 0 {
+		response.Error = fmt.Sprintf("resolution did not finish after %d hops", maxTraceHops)
+	}
+
+	response.QueryTime = time.Since(start)
+
+	return response, nil
+}
+
+func (c *Client) parseRootHints(roots []string) ([]traceServer, error) {
+	servers := make([]traceServer, 0, len(roots))
+	for _, root := range roots {
+		name, address, ok := strings.Cut(root, "=")
+		if !ok {
+			name, address = root, root
+		}
+
+		if net.ParseIP(address) != nil {
+			address = net.JoinHostPort(address, c.port)
+		}
+
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			return nil, fmt.Errorf("invalid root hint %q: %w", root, err)
+		}
+
+		servers = append(servers, traceServer{name: name, address: address})
+	}
+	return servers, nil
+}
+
+// traceHop queries the servers for zone in order until one of them responds.
+// The returned message is nil when none of them did.
+func (c *Client) traceHop(ctx context.Context, name string, qtype uint16, zone string, servers []traceServer) (TraceHop, *mdns.Msg) {
+	hop := TraceHop{
+		Name: name,
+		Zone: zone,
+	}
+
+	m := new(mdns.Msg)
+	m.SetQuestion(name, qtype)
+	m.RecursionDesired = false
+
+	failures := make([]string, 0)
+	for _, server := range servers {
+		hop.Server = server.name
+		hop.Address = server.address
+
+		slog.Debug("tracing hop", "name", name, "zone", zone, "server", server.name, "address", server.address)
+		r, info, err := c.exchangeWith(ctx, m, server.address)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s (%s): %v", server.name, server.address, err))
+			continue
+		}
+		hop.QueryTime = info.queryTime
+
+		hop.Rcode = mdns.RcodeToString[r.Rcode]
+		hop.Authoritative = r.Authoritative
+		if len(failures) > 0 {
+			hop.Failures = failures
+		}
+
+		for _, ans := range r.Answer {
+			if record := parseAnswer(ans); record != nil {
+				hop.Answer = append(hop.Answer, *record)
+			}
+		}
+
+		if len(r.Answer) == 0 && r.Rcode == mdns.RcodeSuccess {
+			hop.Referral, hop.Nameservers = referral(r, zone)
+			for _, extra := range r.Extra {
+				switch extra.(type) {
+				case *mdns.A, *mdns.AAAA:
+					if record := parseAnswer(extra); record != nil {
+						hop.Glue = append(hop.Glue, *record)
+					}
+				}
+			}
+		}
+
+		return hop, r
+	}
+
+	hop.Failures = failures
+	hop.Error = fmt.Sprintf("no server for %s responded", zone)
+
+	return hop, nil
+}
+
+// referral extracts the delegated zone and its nameservers from the authority
+// section. Only delegations to a zone below the current one are accepted, to
+// avoid upward referrals and loops.
+func referral(r *mdns.Msg, zone string) (string, []string) {
+	var delegated string
+	nameservers := make([]string, 0)
+
+	for _, rr := range r.Ns {
+		ns, ok := rr.(*mdns.NS)
+		if !ok {
+			continue
+		}
+
+		owner := mdns.CanonicalName(ns.Hdr.Name)
+		if owner == mdns.CanonicalName(zone) || !mdns.IsSubDomain(zone, owner) {
+			continue
+		}
+
+		if delegated == "" {
+			delegated = owner
+		}
+		if owner == delegated {
+			nameservers = append(nameservers, ns.Ns)
+		}
+	}
+
+	return delegated, nameservers
+}
+
+// referralServers returns the addresses of the nameservers for the delegated
+// zone, preferring IPv4 glue from the referral and falling back to resolving
+// the nameserver names through the configured resolver.
+func (c *Client) referralServers(ctx context.Context, r *mdns.Msg, zone string, nameservers []string) ([]traceServer, error) {
+	servers := make([]traceServer, 0)
+	unresolved := make([]string, 0)
+	for _, ns := range nameservers {
+		found := false
+		for _, extra := range r.Extra {
+			if a, ok := extra.(*mdns.A); ok && mdns.CanonicalName(a.Hdr.Name) == mdns.CanonicalName(ns) {
+				servers = append(servers, traceServer{name: ns, address: net.JoinHostPort(a.A.String(), c.port)})
+				found = true
+			}
+		}
+		if !found {
+			unresolved = append(unresolved, ns)
+		}
+	}
+
+	if len(servers) > 0 {
+		return servers, nil
+	}
+
+	for _, ns := range unresolved {
+		resp, err := c.Query(ctx, ns, "A")
+		if err != nil {
+			slog.Debug("failed to resolve nameserver", "nameserver", ns, "error", err)
+			continue
+		}
+		for _, record := range resp.Records {
+			if record.Type == "A" {
+				servers = append(servers, traceServer{name: ns, address: net.JoinHostPort(record.Value, c.port)})
+			}
+		}
+	}
+
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("could not find an address for any nameserver of %s", zone)
+	}
+
+	return servers, nil
+}
+
+// cnameTarget returns the target of the CNAME chain starting at name when the
+// answer does not already contain records of the requested type.
+func cnameTarget(answer []mdns.RR, name string, qtype uint16) string {
+	if qtype == mdns.TypeCNAME {
+		return ""
+	}
+
+	targets := make(map[string]string)
+	for _, rr := range answer {
+		if rr.Header().Rrtype == qtype {
+			return ""
+		}
+		if cname, ok := rr.(*mdns.CNAME); ok {
+			targets[mdns.CanonicalName(cname.Hdr.Name)] = cname.Target
+		}
+	}
+
+	target := ""
+	current := mdns.CanonicalName(name)
+	for i := 0; i < len(targets); i++ {
+		next, ok := targets[current]
+		if !ok {
+			break
+		}
+		target = next
+		current = mdns.CanonicalName(next)
+	}
+
+	return target
+}
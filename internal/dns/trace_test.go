@@ -0,0 +1,162 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	mdns "github.com/miekg/dns"
+)
+
+// startTraceServers runs a root, a TLD and an authoritative server on
+// separate loopback addresses sharing the same port, and returns that port.
+func startTraceServers(t *testing.T) string {
+	t.Helper()
+
+	root := func(w mdns.ResponseWriter, r *mdns.Msg) {
+		m := new(mdns.Msg)
+		m.SetReply(r)
+		m.Ns = append(m.Ns, mustRR(t, "test. 172800 IN NS ns.test."))
+		m.Extra = append(m.Extra, mustRR(t, "ns.test. 172800 IN A 127.0.0.2"))
+		_ = w.WriteMsg(m)
+	}
+
+	tld := func(w mdns.ResponseWriter, r *mdns.Msg) {
+		m := new(mdns.Msg)
+		m.SetReply(r)
+		m.Ns = append(m.Ns,
+			mustRR(t, "example.test. 86400 IN NS ns1.example.test."),
+			mustRR(t, "example.test. 86400 IN NS ns2.example.test."),
+		)
+		m.Extra = append(m.Extra,
+			mustRR(t, "ns1.example.test. 86400 IN A 127.0.0.3"),
+			mustRR(t, "ns2.example.test. 86400 IN AAAA ::1"),
+		)
+		_ = w.WriteMsg(m)
+	}
+
+	auth := func(w mdns.ResponseWriter, r *mdns.Msg) {
+		m := new(mdns.Msg)
+		m.SetReply(r)
+		m.Authoritative = true
+
+		switch mdns.CanonicalName(r.Question[0].Name) {
+		case "www.example.test.":
+			m.Answer = append(m.Answer, mustRR(t, "www.example.test. 300 IN A 192.0.2.1"))
+		case "alias.example.test.":
+			m.Answer = append(m.Answer, mustRR(t, "alias.example.test. 300 IN CNAME www.example.test."))
+		default:
+			m.Rcode = mdns.RcodeNameError
+		}
+		_ = w.WriteMsg(m)
+	}
+
+	addr := startTestServer(t, root)
+	_, port, _ := net.SplitHostPort(addr)
+
+	startTestServerAt(t, net.JoinHostPort("127.0.0.2", port), tld)
+	startTestServerAt(t, net.JoinHostPort("127.0.0.3", port), auth)
+
+	return port
+}
+
+func TestClient_Trace(t *testing.T) {
+	port := startTraceServers(t)
+
+	client := NewClient(5*time.Second, "127.0.0.1:"+port)
+	client.port = port
+
+	resp, err := client.Trace(context.Background(), "www.example.test", "A", []string{"root.test=127.0.0.1"})
+	if err != nil {
+		t.Fatalf("Trace failed: %v", err)
+	}
+
+	if resp.Error != "" {
+		t.Fatalf("unexpected trace error: %s", resp.Error)
+	}
+
+	if len(resp.Hops) != 3 {
+		t.Fatalf("expected 3 hops, got %d: %+v", len(resp.Hops), resp.Hops)
+	}
+
+	expected := []struct{ zone, referral string }{
+		{".", "test."},
+		{"test.", "example.test."},
+		{"example.test.", ""},
+	}
+	for i, hop := range resp.Hops {
+		if hop.Zone != expected[i].zone {
+			t.Errorf("hop %d: expected zone %s, got %s", i, expected[i].zone, hop.Zone)
+		}
+		if hop.Referral != expected[i].referral {
+			t.Errorf("hop %d: expected referral %q, got %q", i, expected[i].referral, hop.Referral)
+		}
+	}
+
+	if len(resp.Hops[1].Nameservers) != 2 || len(resp.Hops[1].Glue) != 2 {
+		t.Errorf("expected 2 nameservers with glue in the TLD referral, got %+v", resp.Hops[1])
+	}
+
+	if !resp.Hops[2].Authoritative {
+		t.Error("expected final hop to be authoritative")
+	}
+
+	if len(resp.Records) != 1 || resp.Records[0].Value != "192.0.2.1" {
+		t.Errorf("unexpected final records: %+v", resp.Records)
+	}
+}
+
+func TestClient_Trace_FollowsCNAME(t *testing.T) {
+	port := startTraceServers(t)
+
+	client := NewClient(5*time.Second, "127.0.0.1:"+port)
+	client.port = port
+
+	resp, err := client.Trace(context.Background(), "alias.example.test", "A", []string{"root.test=127.0.0.1"})
+	if err != nil {
+		t.Fatalf("Trace failed: %v", err)
+	}
+
+	if len(resp.Hops) != 6 {
+		t.Fatalf("expected 6 hops, got %d", len(resp.Hops))
+	}
+
+	if resp.Hops[3].Name != "www.example.test." || resp.Hops[3].Zone != "." {
+		t.Errorf("expected resolution to restart at the root for the CNAME target, got %+v", resp.Hops[3])
+	}
+
+	if len(resp.Records) != 1 || resp.Records[0].Value != "192.0.2.1" {
+		t.Errorf("unexpected final records: %+v", resp.Records)
+	}
+}
+
+func TestClient_Trace_NXDOMAIN(t *testing.T) {
+	port := startTraceServers(t)
+
+	client := NewClient(5*time.Second, "127.0.0.1:"+port)
+	client.port = port
+
+	resp, err := client.Trace(context.Background(), "missing.example.test", "A", []string{"root.test=127.0.0.1"})
+	if err != nil {
+		t.Fatalf("Trace failed: %v", err)
+	}
+
+	last := resp.Hops[len(resp.Hops)-1]
+	if last.Rcode != "NXDOMAIN" {
+		t.Errorf("expected NXDOMAIN on the last hop, got %s", last.Rcode)
+	}
+
+	if len(resp.Records) != 0 {
+		t.Errorf("expected no records, got %+v", resp.Records)
+	}
+}
+
+func TestClient_Trace_InvalidRootHint(t *testing.T) {
+	client := NewClient(5*time.Second, "127.0.0.1:53")
+
+	_, err := client.Trace(context.Background(), "example.com", "A", []string{"root=not-an-address"})
+	if err == nil {
+		t.Error("expected error for invalid root hint")
+	}
+}
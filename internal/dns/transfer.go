@@ -0,0 +1,289 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	mdns "github.com/miekg/dns"
+
+	"watchr/internal/idn"
+)
+
+// Zone transfer statuses.
+const (
+	TransferOK      = "transferred"
+	TransferRefused = "refused"
+	TransferFailed  = "failed"
+)
+
+// tsigFudge is the allowed clock skew, in seconds, for TSIG signed requests.
+const tsigFudge = 300
+
+// TransferHandler receives the records of a zone transfer as each message of
+// the transfer arrives.
+type TransferHandler func(server *TransferServer, records []Record) error
+
+type tsigKey struct {
+	name      string
+	algorithm string
+	secret    string
+}
+
+// Transfer resolves the NS set of zone through the configured resolver and
+// requests a zone transfer from every authoritative server: a full transfer
+// (AXFR), or an incremental transfer (IXFR) of the changes since serial when
+// ixfr is set. Requests are signed when the client was created WithTSIG.
+// Servers that deny the transfer are reported as refused, the others as
+// transferred along with the number of records they sent. The records of the
+// first server to allow the transfer are passed to handler as they arrive,
+// without being kept in the response.
+func (c *Client) Transfer(ctx context.Context, zone string, ixfr bool, serial uint32, handler TransferHandler) (*TransferResponse, error) {
+	name, err := idn.Parse(mdns.CanonicalName(zone))
+	if err != nil {
+		return nil, err
+	}
+	zone = name.ASCII
+
+	transferType := "AXFR"
+	if ixfr {
+		transferType = "IXFR"
+	}
+
+	slog.Debug("requesting zone transfer", "zone", zone, "type", transferType)
+	start := time.Now()
+
+	nsResp, err := c.Query(ctx, zone, "NS")
+	if err != nil {
+		return nil, err
+	}
+
+	response := &TransferResponse{
+		Zone:        zone,
+		IDN:         name,
+		Type:        transferType,
+		Serial:      serial,
+		Nameserver:  c.nameserver,
+		Nameservers: make([]string, 0),
+		Servers:     make([]TransferServer, 0),
+	}
+	if c.tsig != nil {
+		response.TSIGKey = c.tsig.name
+	}
+
+	for _, record := range nsResp.Records {
+		if record.Type == "NS" {
+			response.Nameservers = append(response.Nameservers, mdns.CanonicalName(record.Value))
+		}
+	}
+	sort.Strings(response.Nameservers)
+
+	if len(response.Nameservers) == 0 {
+		return nil, fmt.Errorf("no NS records found for %s (%s)", zone, nsResp.Rcode)
+	}
+
+	for _, name := range response.Nameservers {
+		for _, address := range c.nameserverAddresses(ctx, name) {
+			response.Servers = append(response.Servers, TransferServer{
+				Nameserver: address.Nameserver,
+				Address:    address.Address,
+				Family:     address.Family,
+				Error:      address.Error,
+			})
+		}
+	}
+
+	// The servers transfer concurrently, and the first one to send records
+	// becomes the source streamed to handler.
+	stream := &transferStream{handler: handler}
+
+	var wg sync.WaitGroup
+	for i := range response.Servers {
+		if response.Servers[i].Error != "" {
+			response.Servers[i].Status = TransferFailed
+			continue
+		}
+
+		wg.Add(1)
+		go func(server *TransferServer) {
+			defer wg.Done()
+			c.transferServer(ctx, server, zone, ixfr, serial, stream)
+		}(&response.Servers[i])
+	}
+	wg.Wait()
+
+	for _, server := range response.Servers {
+		if server.Status == TransferOK {
+			response.Transferred++
+		}
+	}
+	if stream.source != nil {
+		response.Source = stream.source.Address
+	}
+	response.QueryTime = time.Since(start)
+
+	return response, stream.err
+}
+
+// transferStream passes the records of a single server to a handler.
+type transferStream struct {
+	handler TransferHandler
+
+	mu     sync.Mutex
+	source *TransferServer
+	err    error
+}
+
+// write passes records to the handler when server is the source, making it
+// the source when there is none yet.
+func (s *transferStream) write(server *TransferServer, records []Record) error {
+	if s.handler == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	if s.source == nil {
+		s.source = server
+	}
+	isSource := s.source == server
+	s.mu.Unlock()
+
+	if !isSource {
+		return nil
+	}
+
+	if err := s.handler(server, records); err != nil {
+		s.err = err
+		return err
+	}
+	return nil
+}
+
+// replyHeaderConn keeps the DNS header of the first reply read from a TCP
+// connection, as the transfer envelopes do not carry the reply itself.
+type replyHeaderConn struct {
+	net.Conn
+	header []byte
+}
+
+// tcpHeaderSize is the two byte length prefix and the DNS message header.
+const tcpHeaderSize = 2 + 12
+
+func (c *replyHeaderConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if missing := tcpHeaderSize - len(c.header); missing > 0 {
+		c.header = append(c.header, p[:min(n, missing)]...)
+	}
+	return n, err
+}
+
+// rcode returns the rcode of the first reply, when its header was read.
+func (c *replyHeaderConn) rcode() (int, bool) {
+	if len(c.header) < tcpHeaderSize {
+		return 0, false
+	}
+	// The low four bits of the fourth header byte hold the rcode.
+	return int(c.header[2+3] & 0x0f), true
+}
+
+// transferServer requests the transfer of zone from a single server over TCP.
+func (c *Client) transferServer(ctx context.Context, server *TransferServer, zone string, ixfr bool, serial uint32, stream *transferStream) {
+	start := time.Now()
+	defer func() {
+		server.QueryTime = time.Since(start)
+	}()
+
+	m := new(mdns.Msg)
+	if ixfr {
+		m.SetIxfr(zone, serial, ".", ".")
+	} else {
+		m.SetAxfr(zone)
+	}
+
+	t := &mdns.Transfer{
+		DialTimeout:  c.timeout,
+		ReadTimeout:  c.timeout,
+		WriteTimeout: c.timeout,
+	}
+	if c.tsig != nil {
+		t.TsigSecret = map[string]string{c.tsig.name: c.tsig.secret}
+		m.SetTsig(c.tsig.name, c.tsig.algorithm, tsigFudge, time.Now().Unix())
+	}
+
+	dialer := &net.Dialer{Timeout: c.timeout}
+	conn, err := dialer.DialContext(ctx, transportTCP, server.Address)
+	if err != nil {
+		server.Status = TransferFailed
+		server.Error = err.Error()
+		return
+	}
+	replyConn := &replyHeaderConn{Conn: conn}
+	t.Conn = &mdns.Conn{Conn: replyConn}
+
+	slog.Debug("transferring zone", "zone", zone, "nameserver", server.Nameserver, "address", server.Address)
+	envelopes, err := t.In(m, server.Address)
+	if err != nil {
+		_ = conn.Close()
+		server.Status = TransferFailed
+		server.Error = err.Error()
+		return
+	}
+
+	var failure error
+	seenSOA := false
+	for envelope := range envelopes {
+		if failure != nil {
+			// Drain the channel so the transfer goroutine can finish.
+			continue
+		}
+		if envelope.Error != nil {
+			failure = envelope.Error
+			continue
+		}
+
+		records := make([]Record, 0, len(envelope.RR))
+		for _, rr := range envelope.RR {
+			if soa, ok := rr.(*mdns.SOA); ok && !seenSOA {
+				server.Serial = soa.Serial
+				seenSOA = true
+			}
+			if record := parseAnswer(rr); record != nil {
+				records = append(records, *record)
+			}
+		}
+		server.RecordCount += len(records)
+
+		if err := stream.write(server, records); err != nil {
+			failure = fmt.Errorf("failed to write records: %w", err)
+			_ = t.Close()
+		}
+	}
+
+	if failure != nil {
+		server.Status = TransferFailed
+		server.Error = failure.Error()
+		if rcode, ok := replyConn.rcode(); ok && rcode != mdns.RcodeSuccess {
+			server.Status = transferStatus(rcode)
+			server.Rcode = rcodeString(rcode)
+		}
+		return
+	}
+
+	server.Status = TransferOK
+	server.Rcode = rcodeString(mdns.RcodeSuccess)
+}
+
+// transferStatus classifies the rcode of a failed transfer. Servers answering
+// with REFUSED or NOTAUTH denied the transfer; anything else is a failure.
+func transferStatus(rcode int) string {
+	switch rcode {
+	case mdns.RcodeRefused, mdns.RcodeNotAuth:
+		return TransferRefused
+	default:
+		return TransferFailed
+	}
+}
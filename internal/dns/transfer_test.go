@@ -0,0 +1,226 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	mdns "github.com/miekg/dns"
+)
+
+const testTSIGSecret = "c2VjcmV0LWtleS1mb3ItdGVzdHM="
+
+// transferHandler serves example.test zone transfers. When requireTSIG is set,
+// unsigned or badly signed requests are answered with NOTAUTH.
+func transferHandler(t *testing.T, requireTSIG bool) mdns.HandlerFunc {
+	zone := []mdns.RR{
+		mustRR(t, "example.test. 3600 IN SOA ns1.example.test. hostmaster.example.test. 2024010101 7200 3600 1209600 300"),
+		mustRR(t, "example.test. 3600 IN NS ns1.example.test."),
+		mustRR(t, "www.example.test. 300 IN A 192.0.2.10"),
+		mustRR(t, `example.test. 300 IN TXT "v=spf1 -all"`),
+		mustRR(t, "example.test. 3600 IN SOA ns1.example.test. hostmaster.example.test. 2024010101 7200 3600 1209600 300"),
+	}
+
+	return func(w mdns.ResponseWriter, r *mdns.Msg) {
+		if requireTSIG && (r.IsTsig() == nil || w.TsigStatus() != nil) {
+			m := new(mdns.Msg)
+			m.SetRcode(r, mdns.RcodeNotAuth)
+			_ = w.WriteMsg(m)
+			return
+		}
+
+		ch := make(chan *mdns.Envelope)
+		done := make(chan struct{})
+		go func() {
+			_ = new(mdns.Transfer).Out(w, r, ch)
+			close(done)
+		}()
+		ch <- &mdns.Envelope{RR: zone}
+		close(ch)
+		<-done
+	}
+}
+
+func refusingHandler(w mdns.ResponseWriter, r *mdns.Msg) {
+	m := new(mdns.Msg)
+	m.SetRcode(r, mdns.RcodeRefused)
+	_ = w.WriteMsg(m)
+}
+
+func TestClient_Transfer(t *testing.T) {
+	addr := startTestServer(t, zoneHandler(
+		"example.test. 3600 IN NS ns1.example.test.",
+		"example.test. 3600 IN NS ns2.example.test.",
+		"ns1.example.test. 3600 IN A 127.0.0.2",
+		"ns2.example.test. 3600 IN A 127.0.0.3",
+	))
+	_, port, _ := net.SplitHostPort(addr)
+
+	startTestServerAt(t, net.JoinHostPort("127.0.0.2", port), transferHandler(t, false))
+	startTestServerAt(t, net.JoinHostPort("127.0.0.3", port), refusingHandler)
+
+	client := NewClient(time.Second, addr)
+	client.port = port
+
+	streamed := make([]Record, 0)
+	resp, err := client.Transfer(context.Background(), "example.test", false, 0, func(server *TransferServer, records []Record) error {
+		if server.Nameserver != "ns1.example.test." {
+			t.Errorf("expected records from ns1 only, got %s", server.Nameserver)
+		}
+		streamed = append(streamed, records...)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Transfer failed: %v", err)
+	}
+
+	if resp.Type != "AXFR" {
+		t.Errorf("expected AXFR, got %s", resp.Type)
+	}
+
+	if resp.Transferred != 1 {
+		t.Errorf("expected 1 server to allow the transfer, got %d", resp.Transferred)
+	}
+
+	servers := make(map[string]TransferServer)
+	for _, server := range resp.Servers {
+		servers[server.Nameserver] = server
+	}
+
+	open := servers["ns1.example.test."]
+	if open.Status != TransferOK {
+		t.Fatalf("expected ns1 to be %s, got %s (%s)", TransferOK, open.Status, open.Error)
+	}
+	if open.Serial != 2024010101 {
+		t.Errorf("expected serial 2024010101, got %d", open.Serial)
+	}
+	if open.RecordCount != 5 || len(streamed) != 5 {
+		t.Errorf("expected 5 records, got %d (%d streamed)", open.RecordCount, len(streamed))
+	}
+	if resp.Source != open.Address {
+		t.Errorf("expected the records to come from %s, got %s", open.Address, resp.Source)
+	}
+
+	refused := servers["ns2.example.test."]
+	if refused.Status != TransferRefused {
+		t.Errorf("expected ns2 to be %s, got %s", TransferRefused, refused.Status)
+	}
+	if refused.Rcode != "REFUSED" {
+		t.Errorf("expected REFUSED rcode, got %s", refused.Rcode)
+	}
+	if refused.RecordCount != 0 {
+		t.Errorf("expected no records from ns2, got %d", refused.RecordCount)
+	}
+}
+
+func TestClient_Transfer_TSIG(t *testing.T) {
+	addr := startTestServer(t, zoneHandler(
+		"example.test. 3600 IN NS ns1.example.test.",
+		"ns1.example.test. 3600 IN A 127.0.0.2",
+	))
+	_, port, _ := net.SplitHostPort(addr)
+
+	l, err := net.Listen("tcp", net.JoinHostPort("127.0.0.2", port))
+	if err != nil {
+		t.Fatalf("failed to listen on TCP: %v", err)
+	}
+	server := &mdns.Server{
+		Listener:   l,
+		Handler:    transferHandler(t, true),
+		TsigSecret: map[string]string{"transfer.key.": testTSIGSecret},
+	}
+	go func() {
+		_ = server.ActivateAndServe()
+	}()
+	t.Cleanup(func() {
+		_ = server.Shutdown()
+	})
+
+	client := NewClient(time.Second, addr)
+	client.port = port
+
+	resp, err := client.Transfer(context.Background(), "example.test", false, 0, nil)
+	if err != nil {
+		t.Fatalf("Transfer failed: %v", err)
+	}
+	if resp.Servers[0].Status != TransferRefused || resp.Servers[0].Rcode != "NOTAUTH" {
+		t.Errorf("expected unsigned transfer to be %s, got %s %s (%s)", TransferRefused, resp.Servers[0].Status, resp.Servers[0].Rcode, resp.Servers[0].Error)
+	}
+
+	client = NewClient(time.Second, addr, WithTSIG("transfer.key", "", testTSIGSecret))
+	client.port = port
+
+	resp, err = client.Transfer(context.Background(), "example.test", false, 0, nil)
+	if err != nil {
+		t.Fatalf("Transfer failed: %v", err)
+	}
+	if resp.TSIGKey != "transfer.key." {
+		t.Errorf("expected TSIG key transfer.key., got %q", resp.TSIGKey)
+	}
+	if resp.Servers[0].Status != TransferOK {
+		t.Errorf("expected signed transfer to be %s, got %s (%s)", TransferOK, resp.Servers[0].Status, resp.Servers[0].Error)
+	}
+}
+
+func TestClient_Transfer_IXFR(t *testing.T) {
+	addr := startTestServer(t, zoneHandler(
+		"example.test. 3600 IN NS ns1.example.test.",
+		"ns1.example.test. 3600 IN A 127.0.0.2",
+	))
+	_, port, _ := net.SplitHostPort(addr)
+
+	qtypes := make(chan uint16, 1)
+	startTestServerAt(t, net.JoinHostPort("127.0.0.2", port), func(w mdns.ResponseWriter, r *mdns.Msg) {
+		qtypes <- r.Question[0].Qtype
+		refusingHandler(w, r)
+	})
+
+	client := NewClient(time.Second, addr)
+	client.port = port
+
+	resp, err := client.Transfer(context.Background(), "example.test", true, 2024010100, nil)
+	if err != nil {
+		t.Fatalf("Transfer failed: %v", err)
+	}
+
+	if resp.Type != "IXFR" || resp.Serial != 2024010100 {
+		t.Errorf("expected IXFR from serial 2024010100, got %s %d", resp.Type, resp.Serial)
+	}
+	if qtype := <-qtypes; qtype != mdns.TypeIXFR {
+		t.Errorf("expected an IXFR question, got %s", mdns.TypeToString[qtype])
+	}
+
+	// Serial 0 is a valid serial to request the changes from.
+	resp, err = client.Transfer(context.Background(), "example.test", true, 0, nil)
+	if err != nil {
+		t.Fatalf("Transfer failed: %v", err)
+	}
+	if qtype := <-qtypes; resp.Type != "IXFR" || qtype != mdns.TypeIXFR {
+		t.Errorf("expected an IXFR from serial 0, got %s (%s question)", resp.Type, mdns.TypeToString[qtype])
+	}
+}
+
+func TestClient_Transfer_HandlerError(t *testing.T) {
+	addr := startTestServer(t, zoneHandler(
+		"example.test. 3600 IN NS ns1.example.test.",
+		"ns1.example.test. 3600 IN A 127.0.0.2",
+	))
+	_, port, _ := net.SplitHostPort(addr)
+
+	startTestServerAt(t, net.JoinHostPort("127.0.0.2", port), transferHandler(t, false))
+
+	client := NewClient(time.Second, addr)
+	client.port = port
+
+	resp, err := client.Transfer(context.Background(), "example.test", false, 0, func(*TransferServer, []Record) error {
+		return errors.New("broken pipe")
+	})
+	if err == nil || err.Error() != "broken pipe" {
+		t.Fatalf("expected the handler error, got %v", err)
+	}
+	if resp.Servers[0].Status != TransferFailed {
+		t.Errorf("expected the transfer to fail, got %s", resp.Servers[0].Status)
+	}
+}
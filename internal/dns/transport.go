@@ -0,0 +1,283 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	mdns "github.com/miekg/dns"
+
+	tlsinfo "watchr/internal/tls"
+)
+
+const (
+	transportUDP   = "udp"
+	transportTCP   = "tcp"
+	transportTLS   = "tls"
+	transportHTTPS = "https"
+
+	dohContentType = "application/dns-message"
+	// maxDoHResponseSize is the largest possible DNS message.
+	maxDoHResponseSize = 65535
+)
+
+// server is a parsed nameserver address.
+type server struct {
+	transport  string
+	address    string
+	serverName string
+}
+
+type exchangeInfo struct {
+	queryTime time.Duration
+	transport string
+	tls       *TLSInfo
+	http      *HTTPInfo
+	server    string
+	attempts  []QueryAttempt
+}
+
+// parseServer parses a nameserver given either as a plain "host[:port]"
+// address, as a URL-like "tls://host[:port][#name]" address, or as a
+// DNS-over-HTTPS URL.
+func parseServer(nameserver string) server {
+	scheme, rest, found := strings.Cut(nameserver, "://")
+	if !found {
+		return server{transport: transportUDP, address: ensurePort(nameserver, "53")}
+	}
+
+	scheme = strings.ToLower(scheme)
+	switch scheme {
+	case "udp", "dns":
+		return server{transport: transportUDP, address: ensurePort(rest, "53")}
+	case transportTCP:
+		return server{transport: transportTCP, address: ensurePort(rest, "53")}
+	case transportTLS:
+		host, name, _ := strings.Cut(rest, "#")
+		address := ensurePort(host, "853")
+		if name == "" {
+			name, _, _ = net.SplitHostPort(address)
+		}
+		return server{transport: transportTLS, address: address, serverName: name}
+	case transportHTTPS:
+		serverName := ""
+		if u, err := url.Parse(nameserver); err == nil {
+			serverName = u.Hostname()
+		}
+		return server{transport: transportHTTPS, address: nameserver, serverName: serverName}
+	default:
+		return server{transport: scheme, address: rest}
+	}
+}
+
+func (s server) String() string {
+	switch s.transport {
+	case transportUDP, transportHTTPS:
+		return s.address
+	default:
+		return s.transport + "://" + s.address
+	}
+}
+
+func (c *Client) newTLSConfig() *tls.Config {
+	config := &tls.Config{
+		ServerName: c.server.serverName,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if len(c.spkiPins) > 0 {
+		pins := c.spkiPins
+		config.VerifyConnection = func(state tls.ConnectionState) error {
+			if matchedSPKIPin(state.PeerCertificates, pins) == "" {
+				return fmt.Errorf("no certificate in the chain presented by %s matches the configured SPKI pins", c.server.address)
+			}
+			return nil
+		}
+	}
+
+	return config
+}
+
+func (c *Client) exchangeTLS(ctx context.Context, m *mdns.Msg) (*mdns.Msg, *exchangeInfo, error) {
+	client := &mdns.Client{
+		Net:       "tcp-tls",
+		TLSConfig: c.tlsConfig,
+		Timeout:   c.timeout,
+	}
+
+	start := time.Now()
+	conn, err := client.DialContext(ctx, c.server.address)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	handshakeTime := time.Since(start)
+
+	r, _, err := client.ExchangeWithConnContext(ctx, m, conn)
+	queryTime := time.Since(start)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info := &exchangeInfo{queryTime: queryTime, transport: transportTLS}
+	if tlsConn, ok := conn.Conn.(*tls.Conn); ok {
+		info.tls = c.tlsInfo(tlsConn.ConnectionState())
+		info.tls.HandshakeTime = handshakeTime
+	}
+
+	return r, info, nil
+}
+
+func (c *Client) newHTTPClient() *http.Client {
+	return &http.Client{
+		Timeout: c.timeout,
+		Transport: &http.Transport{
+			Proxy:             http.ProxyFromEnvironment,
+			TLSClientConfig:   c.tlsConfig,
+			ForceAttemptHTTP2: true,
+		},
+	}
+}
+
+// exchangeHTTPS sends m to a DNS-over-HTTPS server as described in RFC 8484,
+// either as the "dns" query parameter of a GET request or as the body of a
+// POST request.
+func (c *Client) exchangeHTTPS(ctx context.Context, m *mdns.Msg) (*mdns.Msg, *exchangeInfo, error) {
+	// RFC 8484 section 4.1 recommends a zero ID to improve cacheability.
+	id := m.Id
+	m.Id = 0
+	packed, err := m.Pack()
+	m.Id = id
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var req *http.Request
+	switch c.dohMethod {
+	case http.MethodGet:
+		u, err := url.Parse(c.server.address)
+		if err != nil {
+			return nil, nil, err
+		}
+		query := u.Query()
+		query.Set("dns", base64.RawURLEncoding.EncodeToString(packed))
+		u.RawQuery = query.Encode()
+
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, nil, err
+		}
+	case http.MethodPost:
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, c.server.address, bytes.NewReader(packed))
+		if err != nil {
+			return nil, nil, err
+		}
+		req.Header.Set("Content-Type", dohContentType)
+	default:
+		return nil, nil, fmt.Errorf("unsupported DNS-over-HTTPS method: %s", c.dohMethod)
+	}
+
+	req.Header.Set("Accept", dohContentType)
+	req.Header.Set("User-Agent", "watchr/1.0")
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDoHResponseSize+1))
+	queryTime := time.Since(start)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("DNS-over-HTTPS query failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	if len(body) > maxDoHResponseSize {
+		return nil, nil, fmt.Errorf("DNS-over-HTTPS response exceeds %d bytes", maxDoHResponseSize)
+	}
+
+	r := new(mdns.Msg)
+	if err := r.Unpack(body); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode DNS-over-HTTPS response: %w", err)
+	}
+	r.Id = id
+
+	info := &exchangeInfo{
+		queryTime: queryTime,
+		transport: transportHTTPS,
+		http: &HTTPInfo{
+			Method:      req.Method,
+			StatusCode:  resp.StatusCode,
+			Status:      resp.Status,
+			Proto:       resp.Proto,
+			ContentType: resp.Header.Get("Content-Type"),
+		},
+	}
+
+	if resp.TLS != nil {
+		info.tls = c.tlsInfo(*resp.TLS)
+	}
+
+	return r, info, nil
+}
+
+func (c *Client) tlsInfo(state tls.ConnectionState) *TLSInfo {
+	info := &TLSInfo{
+		Version:     tlsinfo.VersionString(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+		ServerName:  c.server.serverName,
+		Verified:    len(state.VerifiedChains) > 0,
+	}
+
+	if len(state.PeerCertificates) > 0 {
+		leaf := state.PeerCertificates[0]
+		info.Subject = leaf.Subject.String()
+		info.Issuer = leaf.Issuer.String()
+		info.NotAfter = leaf.NotAfter
+		info.SPKIPin = spkiPin(leaf)
+	}
+
+	if len(c.spkiPins) > 0 {
+		info.MatchedPin = matchedSPKIPin(state.PeerCertificates, c.spkiPins)
+	}
+
+	return info
+}
+
+// spkiPin returns the base64 encoded SHA-256 digest of the certificate's
+// SubjectPublicKeyInfo.
+func spkiPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func matchedSPKIPin(certs []*x509.Certificate, pins []string) string {
+	for _, cert := range certs {
+		pin := spkiPin(cert)
+		for _, expected := range pins {
+			if pin == strings.TrimSpace(expected) {
+				return pin
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,354 @@
+package dns
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mdns "github.com/miekg/dns"
+)
+
+func TestParseServer(t *testing.T) {
+	tests := []struct {
+		input      string
+		transport  string
+		address    string
+		serverName string
+	}{
+		{"8.8.8.8", "udp", "8.8.8.8:53", ""},
+		{"8.8.8.8:5353", "udp", "8.8.8.8:5353", ""},
+		{"2001:4860:4860::8888", "udp", "[2001:4860:4860::8888]:53", ""},
+		{"udp://1.1.1.1", "udp", "1.1.1.1:53", ""},
+		{"tcp://1.1.1.1", "tcp", "1.1.1.1:53", ""},
+		{"tls://1.1.1.1", "tls", "1.1.1.1:853", "1.1.1.1"},
+		{"tls://dns.google", "tls", "dns.google:853", "dns.google"},
+		{"tls://1.1.1.1:8853#cloudflare-dns.com", "tls", "1.1.1.1:8853", "cloudflare-dns.com"},
+		{"tls://[2606:4700:4700::1111]", "tls", "[2606:4700:4700::1111]:853", "2606:4700:4700::1111"},
+		{"https://dns.google/dns-query", "https", "https://dns.google/dns-query", "dns.google"},
+	}
+
+	for _, tt := range tests {
+		s := parseServer(tt.input)
+		if s.transport != tt.transport || s.address != tt.address || s.serverName != tt.serverName {
+			t.Errorf("parseServer(%q) = %+v, expected {%s %s %s}", tt.input, s, tt.transport, tt.address, tt.serverName)
+		}
+	}
+}
+
+func TestClient_Query_TLS(t *testing.T) {
+	addr, pool, _ := startTLSTestServer(t, zoneHandler("example.com. 300 IN A 192.0.2.1"))
+
+	client := NewClient(5*time.Second, "tls://"+addr)
+	client.tlsConfig.RootCAs = pool
+
+	resp, err := client.Query(context.Background(), "example.com", "A")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	if resp.Nameserver != "tls://"+addr {
+		t.Errorf("expected nameserver tls://%s, got %s", addr, resp.Nameserver)
+	}
+
+	if len(resp.Records) != 1 || resp.Records[0].Value != "192.0.2.1" {
+		t.Errorf("unexpected records: %+v", resp.Records)
+	}
+
+	if resp.TLS == nil {
+		t.Fatal("expected TLS details in response")
+	}
+
+	if !resp.TLS.Verified {
+		t.Error("expected certificate to be verified")
+	}
+
+	if resp.TLS.ServerName != "127.0.0.1" {
+		t.Errorf("expected server name 127.0.0.1, got %s", resp.TLS.ServerName)
+	}
+
+	if resp.TLS.Version == "" || resp.TLS.CipherSuite == "" || resp.TLS.SPKIPin == "" {
+		t.Errorf("expected TLS version, cipher suite and SPKI pin, got %+v", resp.TLS)
+	}
+}
+
+func TestClient_Query_TLSAuthenticationName(t *testing.T) {
+	addr, pool, _ := startTLSTestServer(t, zoneHandler("example.com. 300 IN A 192.0.2.1"))
+
+	client := NewClient(5*time.Second, "tls://"+addr, WithTLSServerName("dot.test"))
+	client.tlsConfig.RootCAs = pool
+
+	resp, err := client.Query(context.Background(), "example.com", "A")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	if resp.TLS.ServerName != "dot.test" {
+		t.Errorf("expected server name dot.test, got %s", resp.TLS.ServerName)
+	}
+
+	client = NewClient(5*time.Second, "tls://"+addr+"#wrong.test")
+	client.tlsConfig.RootCAs = pool
+
+	if _, err := client.Query(context.Background(), "example.com", "A"); err == nil {
+		t.Error("expected error for mismatched authentication name")
+	}
+}
+
+func TestClient_Query_TLSUntrusted(t *testing.T) {
+	addr, _, _ := startTLSTestServer(t, zoneHandler("example.com. 300 IN A 192.0.2.1"))
+
+	client := NewClient(5*time.Second, "tls://"+addr)
+
+	if _, err := client.Query(context.Background(), "example.com", "A"); err == nil {
+		t.Error("expected error for untrusted certificate")
+	}
+}
+
+func TestClient_Query_TLSSPKIPin(t *testing.T) {
+	addr, pool, leaf := startTLSTestServer(t, zoneHandler("example.com. 300 IN A 192.0.2.1"))
+	pin := spkiPin(leaf)
+
+	client := NewClient(5*time.Second, "tls://"+addr, WithSPKIPins([]string{"AAAA", pin}))
+	client.tlsConfig.RootCAs = pool
+
+	resp, err := client.Query(context.Background(), "example.com", "A")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	if resp.TLS.MatchedPin != pin {
+		t.Errorf("expected matched pin %s, got %s", pin, resp.TLS.MatchedPin)
+	}
+
+	client = NewClient(5*time.Second, "tls://"+addr, WithSPKIPins([]string{"AAAA"}))
+	client.tlsConfig.RootCAs = pool
+
+	_, err = client.Query(context.Background(), "example.com", "A")
+	if err == nil || !strings.Contains(err.Error(), "SPKI") {
+		t.Errorf("expected SPKI pin mismatch error, got %v", err)
+	}
+}
+
+func TestClient_Query_UnsupportedTransport(t *testing.T) {
+	client := NewClient(5*time.Second, "quic://127.0.0.1")
+
+	if _, err := client.Query(context.Background(), "example.com", "A"); err == nil {
+		t.Error("expected error for unsupported transport")
+	}
+}
+
+// startDoHTestServer runs a local DNS-over-HTTPS server answering every query
+// with a single A record, and records the HTTP method of the last request.
+func startDoHTestServer(t *testing.T, http2 bool) (*httptest.Server, *x509.CertPool, *string) {
+	t.Helper()
+
+	method := new(string)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*method = r.Method
+
+		var packed []byte
+		var err error
+		switch r.Method {
+		case http.MethodGet:
+			packed, err = base64.RawURLEncoding.DecodeString(r.URL.Query().Get("dns"))
+		case http.MethodPost:
+			if r.Header.Get("Content-Type") != "application/dns-message" {
+				http.Error(w, "unsupported media type", http.StatusUnsupportedMediaType)
+				return
+			}
+			packed, err = io.ReadAll(r.Body)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		q := new(mdns.Msg)
+		if err := q.Unpack(packed); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		m := new(mdns.Msg)
+		m.SetReply(q)
+		m.Answer = append(m.Answer, mustRR(t, q.Question[0].Name+" 300 IN A 192.0.2.1"))
+
+		out, err := m.Pack()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/dns-message")
+		_, _ = w.Write(out)
+	}))
+	srv.EnableHTTP2 = http2
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+
+	return srv, pool, method
+}
+
+func TestClient_Query_HTTPS(t *testing.T) {
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		t.Run(method, func(t *testing.T) {
+			srv, pool, got := startDoHTestServer(t, false)
+
+			client := NewClient(5*time.Second, srv.URL+"/dns-query", WithDoHMethod(method))
+			client.tlsConfig.RootCAs = pool
+
+			resp, err := client.Query(context.Background(), "example.com", "A")
+			if err != nil {
+				t.Fatalf("Query failed: %v", err)
+			}
+
+			if *got != method {
+				t.Errorf("expected %s request, got %s", method, *got)
+			}
+
+			if resp.Nameserver != srv.URL+"/dns-query" {
+				t.Errorf("expected nameserver %s/dns-query, got %s", srv.URL, resp.Nameserver)
+			}
+
+			if len(resp.Records) != 1 || resp.Records[0].Value != "192.0.2.1" {
+				t.Errorf("unexpected records: %+v", resp.Records)
+			}
+
+			if resp.HTTP == nil {
+				t.Fatal("expected HTTP details in response")
+			}
+
+			if resp.HTTP.StatusCode != http.StatusOK || resp.HTTP.Proto != "HTTP/1.1" || resp.HTTP.Method != method {
+				t.Errorf("unexpected HTTP details: %+v", resp.HTTP)
+			}
+
+			if resp.TLS == nil || !resp.TLS.Verified {
+				t.Errorf("expected verified TLS details, got %+v", resp.TLS)
+			}
+		})
+	}
+}
+
+func TestClient_Query_HTTPS_HTTP2(t *testing.T) {
+	srv, pool, _ := startDoHTestServer(t, true)
+
+	client := NewClient(5*time.Second, srv.URL+"/dns-query")
+	client.tlsConfig.RootCAs = pool
+
+	resp, err := client.Query(context.Background(), "example.com", "A")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	if resp.HTTP.Proto != "HTTP/2.0" {
+		t.Errorf("expected HTTP/2.0, got %s", resp.HTTP.Proto)
+	}
+}
+
+func TestClient_Query_HTTPS_ErrorStatus(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad request", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+
+	client := NewClient(5*time.Second, srv.URL+"/dns-query")
+	client.tlsConfig.RootCAs = pool
+
+	_, err := client.Query(context.Background(), "example.com", "A")
+	if err == nil || !strings.Contains(err.Error(), "status 400") {
+		t.Errorf("expected status 400 error, got %v", err)
+	}
+}
+
+// truncatingHandler sets the TC bit on UDP responses and only returns the
+// full answer over TCP.
+func truncatingHandler(t *testing.T) mdns.HandlerFunc {
+	return func(w mdns.ResponseWriter, r *mdns.Msg) {
+		m := new(mdns.Msg)
+		m.SetReply(r)
+
+		if w.RemoteAddr().Network() == "udp" {
+			m.Truncated = true
+		} else {
+			m.Answer = append(m.Answer, mustRR(t, "example.com. 300 IN TXT \"served over tcp\""))
+		}
+
+		_ = w.WriteMsg(m)
+	}
+}
+
+func TestClient_Query_TruncatedFallsBackToTCP(t *testing.T) {
+	addr := startTestServer(t, truncatingHandler(t))
+
+	client := NewClient(5*time.Second, addr)
+
+	resp, err := client.Query(context.Background(), "example.com", "TXT")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	if resp.Transport != "tcp" {
+		t.Errorf("expected transport tcp, got %s", resp.Transport)
+	}
+
+	if resp.Flags.Truncated {
+		t.Error("expected final answer not to be truncated")
+	}
+
+	if len(resp.Records) != 1 || resp.Records[0].Value != "served over tcp" {
+		t.Errorf("unexpected records: %+v", resp.Records)
+	}
+}
+
+func TestClient_Query_UDP(t *testing.T) {
+	addr := startTestServer(t, zoneHandler("example.com. 300 IN A 192.0.2.1"))
+
+	client := NewClient(5*time.Second, addr)
+
+	resp, err := client.Query(context.Background(), "example.com", "A")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	if resp.Transport != "udp" {
+		t.Errorf("expected transport udp, got %s", resp.Transport)
+	}
+}
+
+func TestClient_Query_ForceTCP(t *testing.T) {
+	var network string
+	addr := startTestServer(t, func(w mdns.ResponseWriter, r *mdns.Msg) {
+		network = w.RemoteAddr().Network()
+		zoneHandler("example.com. 300 IN A 192.0.2.1")(w, r)
+	})
+
+	for _, client := range []*Client{
+		NewClient(5*time.Second, addr, WithTCP(true)),
+		NewClient(5*time.Second, "tcp://"+addr),
+	} {
+		resp, err := client.Query(context.Background(), "example.com", "A")
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+
+		if resp.Transport != "tcp" || network != "tcp" {
+			t.Errorf("expected query over tcp, got transport %s and server network %s", resp.Transport, network)
+		}
+
+		if len(resp.Records) != 1 {
+			t.Errorf("expected 1 record, got %d", len(resp.Records))
+		}
+	}
+}
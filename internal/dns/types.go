@@ -2,18 +2,497 @@ package dns
 
 import (
 	"time"
+
+	"watchr/internal/idn"
 )
 
 type Response struct {
+	Domain     string         `json:"domain"`
+	IDN        *idn.Name      `json:"idn,omitempty"`
+	RecordType string         `json:"recordType"`
+	Nameserver string         `json:"nameserver"`
+	QueryTime  time.Duration  `json:"queryTime"`
+	Searched   []string       `json:"searched,omitempty"`
+	Attempts   []QueryAttempt `json:"attempts,omitempty"`
+	Transport  string         `json:"transport"`
+	TLS        *TLSInfo       `json:"tls,omitempty"`
+	HTTP       *HTTPInfo      `json:"http,omitempty"`
+	Rcode      string         `json:"rcode"`
+	Flags      Flags          `json:"flags"`
+	EDNS       *EDNSInfo      `json:"edns,omitempty"`
+	Records    []Record       `json:"records"`
+	Chain      *CNAMEChain    `json:"cnameChain,omitempty"`
+	Wildcard   *Wildcard      `json:"wildcard,omitempty"`
+	Authority  []Record       `json:"authority,omitempty"`
+	Additional []Record       `json:"additional,omitempty"`
+}
+
+// CNAMEChain is the chain of aliases from the queried name to its canonical
+// name. Status is one of ChainOK, ChainLoop, ChainTooLong, ChainDangling or
+// ChainFailed, and Records holds the records of the final target.
+type CNAMEChain struct {
+	Hops    []CNAMEHop `json:"hops"`
+	Target  string     `json:"target"`
+	Rcode   string     `json:"rcode"`
+	Status  string     `json:"status"`
+	Records []Record   `json:"records"`
+	Error   string     `json:"error,omitempty"`
+}
+
+// Wildcard reports whether a wildcard answers for names under Zone, as found
+// by querying the random labels in Probes, and the records it answers with.
+type Wildcard struct {
+	Zone     string   `json:"zone"`
+	Detected bool     `json:"detected"`
+	Probes   []string `json:"probes"`
+	Records  []Record `json:"records"`
+}
+
+type CNAMEHop struct {
+	Name   string `json:"name"`
+	Target string `json:"target"`
+	TTL    uint32 `json:"ttl"`
+}
+
+// QueryAttempt records a single try of a query against one nameserver.
+type QueryAttempt struct {
+	Server    string        `json:"server"`
+	Rcode     string        `json:"rcode,omitempty"`
+	QueryTime time.Duration `json:"queryTime"`
+	Error     string        `json:"error,omitempty"`
+}
+
+type Flags struct {
+	Response           bool `json:"response"`
+	Authoritative      bool `json:"authoritative"`
+	Truncated          bool `json:"truncated"`
+	RecursionDesired   bool `json:"recursionDesired"`
+	RecursionAvailable bool `json:"recursionAvailable"`
+	AuthenticatedData  bool `json:"authenticatedData"`
+	CheckingDisabled   bool `json:"checkingDisabled"`
+}
+
+type EDNSInfo struct {
+	Version       uint8         `json:"version"`
+	UDPSize       uint16        `json:"udpSize"`
+	ExtendedRcode int           `json:"extendedRcode"`
+	DNSSECOK      bool          `json:"dnssecOk"`
+	ClientSubnet  *ClientSubnet `json:"clientSubnet,omitempty"`
+	Options       []EDNSOption  `json:"options,omitempty"`
+}
+
+// ClientSubnet is the EDNS Client Subnet option returned by the server. The
+// scope prefix is the part of the source prefix the answer applies to.
+type ClientSubnet struct {
+	Address      string `json:"address"`
+	SourcePrefix uint8  `json:"sourcePrefix"`
+	ScopePrefix  uint8  `json:"scopePrefix"`
+}
+
+type EDNSOption struct {
+	Code  uint16 `json:"code"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type HTTPInfo struct {
+	Method      string `json:"method"`
+	StatusCode  int    `json:"statusCode"`
+	Status      string `json:"status"`
+	Proto       string `json:"proto"`
+	ContentType string `json:"contentType"`
+}
+
+type TLSInfo struct {
+	Version       string        `json:"version"`
+	CipherSuite   string        `json:"cipherSuite"`
+	ServerName    string        `json:"serverName"`
+	Verified      bool          `json:"verified"`
+	Subject       string        `json:"subject,omitempty"`
+	Issuer        string        `json:"issuer,omitempty"`
+	NotAfter      time.Time     `json:"notAfter"`
+	SPKIPin       string        `json:"spkiPin,omitempty"`
+	MatchedPin    string        `json:"matchedPin,omitempty"`
+	HandshakeTime time.Duration `json:"handshakeTime"`
+}
+
+type Record struct {
+	Name  string `json:"name,omitempty"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+	TTL   uint32 `json:"ttl"`
+	// Data holds the typed fields of the record, such as *MXData or *SOAData,
+	// for the types whose value has more than one field.
+	Data interface{} `json:"data,omitempty"`
+	// Wildcard is set when the record matches the answer of a wildcard
+	// covering its name, so it may not exist on its own.
+	Wildcard bool `json:"wildcard,omitempty"`
+}
+
+type MXData struct {
+	Preference uint16 `json:"preference"`
+	Exchange   string `json:"exchange"`
+}
+
+type TXTData struct {
+	Strings []string `json:"strings"`
+}
+
+type SOAData struct {
+	Mname   string `json:"mname"`
+	Rname   string `json:"rname"`
+	Serial  uint32 `json:"serial"`
+	Refresh uint32 `json:"refresh"`
+	Retry   uint32 `json:"retry"`
+	Expire  uint32 `json:"expire"`
+	Minimum uint32 `json:"minimum"`
+}
+
+type SRVData struct {
+	Priority uint16 `json:"priority"`
+	Weight   uint16 `json:"weight"`
+	Port     uint16 `json:"port"`
+	Target   string `json:"target"`
+}
+
+type CAAData struct {
+	Flag  uint8  `json:"flag"`
+	Tag   string `json:"tag"`
+	Value string `json:"value"`
+}
+
+type SVCBData struct {
+	Priority uint16      `json:"priority"`
+	Target   string      `json:"target"`
+	Params   []SVCBParam `json:"params"`
+}
+
+type SVCBParam struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type TLSAData struct {
+	Usage        uint8  `json:"usage"`
+	Selector     uint8  `json:"selector"`
+	MatchingType uint8  `json:"matchingType"`
+	Certificate  string `json:"certificate"`
+}
+
+type SSHFPData struct {
+	Algorithm   uint8  `json:"algorithm"`
+	Type        uint8  `json:"type"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+type NAPTRData struct {
+	Order       uint16 `json:"order"`
+	Preference  uint16 `json:"preference"`
+	Flags       string `json:"flags"`
+	Service     string `json:"service"`
+	Regexp      string `json:"regexp"`
+	Replacement string `json:"replacement"`
+}
+
+type URIData struct {
+	Priority uint16 `json:"priority"`
+	Weight   uint16 `json:"weight"`
+	Target   string `json:"target"`
+}
+
+// LOCData holds a decoded LOC record: coordinates in degrees, and altitude,
+// size and precisions in meters.
+type LOCData struct {
+	Latitude            float64 `json:"latitude"`
+	Longitude           float64 `json:"longitude"`
+	Altitude            float64 `json:"altitude"`
+	Size                float64 `json:"size"`
+	HorizontalPrecision float64 `json:"horizontalPrecision"`
+	VerticalPrecision   float64 `json:"verticalPrecision"`
+}
+
+type DSData struct {
+	KeyTag     uint16 `json:"keyTag"`
+	Algorithm  uint8  `json:"algorithm"`
+	DigestType uint8  `json:"digestType"`
+	Digest     string `json:"digest"`
+}
+
+type DNSKEYData struct {
+	Flags     uint16 `json:"flags"`
+	Protocol  uint8  `json:"protocol"`
+	Algorithm uint8  `json:"algorithm"`
+	KeyTag    uint16 `json:"keyTag"`
+	PublicKey string `json:"publicKey"`
+}
+
+type RRSIGData struct {
+	TypeCovered string    `json:"typeCovered"`
+	Algorithm   uint8     `json:"algorithm"`
+	Labels      uint8     `json:"labels"`
+	OriginalTTL uint32    `json:"originalTtl"`
+	Expiration  time.Time `json:"expiration"`
+	Inception   time.Time `json:"inception"`
+	KeyTag      uint16    `json:"keyTag"`
+	SignerName  string    `json:"signerName"`
+	Signature   string    `json:"signature"`
+}
+
+type MultiResponse struct {
 	Domain     string        `json:"domain"`
+	IDN        *idn.Name     `json:"idn,omitempty"`
+	Nameserver string        `json:"nameserver"`
+	QueryTime  time.Duration `json:"queryTime"`
+	Results    []TypeResult  `json:"results"`
+}
+
+type TypeResult struct {
+	RecordType string        `json:"recordType"`
+	QueryTime  time.Duration `json:"queryTime"`
+	Rcode      string        `json:"rcode,omitempty"`
+	Records    []Record      `json:"records"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// EnumResponse holds the names found by Enumerate. Found counts the names that
+// exist, Filtered the names only answered by the zone's wildcard, and Failed
+// the queries that got no answer; Results lists the found and failed names.
+type EnumResponse struct {
+	Zone       string        `json:"zone"`
+	IDN        *idn.Name     `json:"idn,omitempty"`
 	RecordType string        `json:"recordType"`
 	Nameserver string        `json:"nameserver"`
 	QueryTime  time.Duration `json:"queryTime"`
+	Candidates int           `json:"candidates"`
+	Found      int           `json:"found"`
+	Filtered   int           `json:"filtered"`
+	Failed     int           `json:"failed"`
+	Wildcard   *Wildcard     `json:"wildcard"`
+	Results    []EnumResult  `json:"results"`
+}
+
+type EnumResult struct {
+	Name    string   `json:"name"`
+	Rcode   string   `json:"rcode,omitempty"`
+	Records []Record `json:"records,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+type BenchResponse struct {
+	Domain      string        `json:"domain"`
+	IDN         *idn.Name     `json:"idn,omitempty"`
+	RecordType  string        `json:"recordType"`
+	Count       int           `json:"count"`
+	Concurrency int           `json:"concurrency"`
+	QueryTime   time.Duration `json:"queryTime"`
+	Results     []BenchResult `json:"results"`
+}
+
+type BenchResult struct {
+	Nameserver string     `json:"nameserver"`
+	Cached     BenchStats `json:"cached"`
+	Uncached   BenchStats `json:"uncached"`
+}
+
+// BenchStats summarizes the latency of the answered queries. ErrorRate is the
+// share of queries that timed out or failed, including SERVFAIL and other
+// error rcodes; NXDOMAIN answers count as successful.
+type BenchStats struct {
+	Queries   int           `json:"queries"`
+	Errors    int           `json:"errors"`
+	Timeouts  int           `json:"timeouts"`
+	ErrorRate float64       `json:"errorRate"`
+	Min       time.Duration `json:"min"`
+	Median    time.Duration `json:"median"`
+	P95       time.Duration `json:"p95"`
+	P99       time.Duration `json:"p99"`
+	Max       time.Duration `json:"max"`
+}
+
+type DNSSECResponse struct {
+	Domain     string        `json:"domain"`
+	IDN        *idn.Name     `json:"idn,omitempty"`
+	RecordType string        `json:"recordType"`
+	Nameserver string        `json:"nameserver"`
+	QueryTime  time.Duration `json:"queryTime"`
+	Status     string        `json:"status"`
+	Links      []DNSSECLink  `json:"links"`
 	Records    []Record      `json:"records"`
 }
 
-type Record struct {
-	Type  string `json:"type"`
-	Value string `json:"value"`
-	TTL   uint32 `json:"ttl"`
+type DNSSECLink struct {
+	Zone    string   `json:"zone"`
+	Type    string   `json:"type"`
+	Status  string   `json:"status"`
+	Reason  string   `json:"reason"`
+	KeyTags []uint16 `json:"keyTags,omitempty"`
+}
+
+type TraceResponse struct {
+	Domain     string        `json:"domain"`
+	IDN        *idn.Name     `json:"idn,omitempty"`
+	RecordType string        `json:"recordType"`
+	QueryTime  time.Duration `json:"queryTime"`
+	Hops       []TraceHop    `json:"hops"`
+	Records    []Record      `json:"records"`
+	Error      string        `json:"error,omitempty"`
+}
+
+type TraceHop struct {
+	Name          string        `json:"name"`
+	Zone          string        `json:"zone"`
+	Server        string        `json:"server"`
+	Address       string        `json:"address"`
+	QueryTime     time.Duration `json:"queryTime"`
+	Rcode         string        `json:"rcode,omitempty"`
+	Authoritative bool          `json:"authoritative"`
+	Answer        []Record      `json:"answer,omitempty"`
+	Referral      string        `json:"referral,omitempty"`
+	Nameservers   []string      `json:"nameservers,omitempty"`
+	Glue          []Record      `json:"glue,omitempty"`
+	Failures      []string      `json:"failures,omitempty"`
+	Error         string        `json:"error,omitempty"`
+}
+
+// FCrDNSResponse is the result of a forward-confirmed reverse DNS check.
+type FCrDNSResponse struct {
+	IP          string           `json:"ip"`
+	ReverseName string           `json:"reverseName"`
+	Nameserver  string           `json:"nameserver"`
+	QueryTime   time.Duration    `json:"queryTime"`
+	Rcode       string           `json:"rcode"`
+	PTR         []Record         `json:"ptr"`
+	Hostnames   []FCrDNSHostname `json:"hostnames"`
+	Confirmed   bool             `json:"confirmed"`
+}
+
+// FCrDNSHostname holds the forward lookup of one of the PTR hostnames.
+type FCrDNSHostname struct {
+	Hostname  string   `json:"hostname"`
+	Addresses []string `json:"addresses"`
+	Matched   bool     `json:"matched"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// CompareResponse holds the answers several nameservers returned for the same
+// query.
+type CompareResponse struct {
+	Domain     string          `json:"domain"`
+	IDN        *idn.Name       `json:"idn,omitempty"`
+	RecordType string          `json:"recordType"`
+	QueryTime  time.Duration   `json:"queryTime"`
+	Consistent bool            `json:"consistent"`
+	Results    []CompareResult `json:"results"`
+	Answers    []CompareAnswer `json:"answers"`
+}
+
+type CompareResult struct {
+	Nameserver string        `json:"nameserver"`
+	Rcode      string        `json:"rcode,omitempty"`
+	Records    []Record      `json:"records"`
+	QueryTime  time.Duration `json:"queryTime"`
+	Agrees     bool          `json:"agrees"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// CompareAnswer is a distinct answer and the nameservers that returned it.
+type CompareAnswer struct {
+	Rcode       string   `json:"rcode"`
+	Values      []string `json:"values"`
+	Nameservers []string `json:"nameservers"`
+}
+
+// NSAuditResponse is the result of auditing the authoritative nameservers of
+// a zone.
+type NSAuditResponse struct {
+	Zone        string          `json:"zone"`
+	IDN         *idn.Name       `json:"idn,omitempty"`
+	RecordType  string          `json:"recordType"`
+	Nameserver  string          `json:"nameserver"`
+	QueryTime   time.Duration   `json:"queryTime"`
+	Nameservers []string        `json:"nameservers"`
+	Serial      uint32          `json:"serial"`
+	Consistent  bool            `json:"consistent"`
+	Servers     []NSAuditServer `json:"servers"`
+}
+
+// NSAuditServer holds what a single address of an authoritative nameserver
+// returned. Status is one of AuditOK, AuditOutOfSync, AuditLame or
+// AuditUnreachable.
+type NSAuditServer struct {
+	Nameserver    string        `json:"nameserver"`
+	Address       string        `json:"address,omitempty"`
+	Family        string        `json:"family,omitempty"`
+	Status        string        `json:"status"`
+	Rcode         string        `json:"rcode,omitempty"`
+	Authoritative bool          `json:"authoritative"`
+	Serial        uint32        `json:"serial"`
+	NS            []string      `json:"ns"`
+	Records       []Record      `json:"records"`
+	QueryTime     time.Duration `json:"queryTime"`
+	Issues        []string      `json:"issues,omitempty"`
+	Error         string        `json:"error,omitempty"`
+}
+
+// DelegationResponse compares the delegation of a domain at its parent zone
+// with the NS set served by the child zone.
+type DelegationResponse struct {
+	Domain        string                 `json:"domain"`
+	IDN           *idn.Name              `json:"idn,omitempty"`
+	ParentZone    string                 `json:"parentZone"`
+	ParentServer  string                 `json:"parentServer"`
+	ParentAddress string                 `json:"parentAddress"`
+	ChildServer   string                 `json:"childServer,omitempty"`
+	Nameserver    string                 `json:"nameserver"`
+	QueryTime     time.Duration          `json:"queryTime"`
+	ParentNS      []string               `json:"parentNS"`
+	ChildNS       []string               `json:"childNS"`
+	RegistryNS    []string               `json:"registryNS,omitempty"`
+	Nameservers   []DelegationNameserver `json:"nameservers"`
+	Consistent    bool                   `json:"consistent"`
+	Issues        []string               `json:"issues"`
+}
+
+type DelegationNameserver struct {
+	Name        string   `json:"name"`
+	InParent    bool     `json:"inParent"`
+	InChild     bool     `json:"inChild"`
+	InRegistry  bool     `json:"inRegistry"`
+	InBailiwick bool     `json:"inBailiwick"`
+	Glue        []string `json:"glue"`
+	Addresses   []string `json:"addresses"`
+	Issues      []string `json:"issues,omitempty"`
+}
+
+// TransferResponse holds the outcome of a zone transfer request sent to every
+// authoritative server of a zone.
+type TransferResponse struct {
+	Zone        string        `json:"zone"`
+	IDN         *idn.Name     `json:"idn,omitempty"`
+	Type        string        `json:"type"`
+	Serial      uint32        `json:"serial,omitempty"`
+	Nameserver  string        `json:"nameserver"`
+	TSIGKey     string        `json:"tsigKey,omitempty"`
+	QueryTime   time.Duration `json:"queryTime"`
+	Nameservers []string      `json:"nameservers"`
+	Transferred int           `json:"transferred"`
+	// Source is the address of the server whose records were streamed.
+	Source  string           `json:"source,omitempty"`
+	Servers []TransferServer `json:"servers"`
+}
+
+// TransferServer holds the outcome of a zone transfer from a single address
+// of an authoritative nameserver. Status is one of TransferOK,
+// TransferRefused or TransferFailed.
+type TransferServer struct {
+	Nameserver string        `json:"nameserver"`
+	Address    string        `json:"address,omitempty"`
+	Family     string        `json:"family,omitempty"`
+	Status     string        `json:"status"`
+	Rcode      string        `json:"rcode,omitempty"`
+	Serial     uint32        `json:"serial,omitempty"`
+	QueryTime  time.Duration `json:"queryTime"`
+	// RecordCount is the number of records sent by the server.
+	RecordCount int    `json:"recordCount"`
+	Error       string `json:"error,omitempty"`
 }
@@ -0,0 +1,173 @@
+package dns
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	mdns "github.com/miekg/dns"
+)
+
+// wildcardProbes is the number of random labels queried to detect a wildcard.
+const wildcardProbes = 3
+
+// DetectWildcard queries random labels under zone for recordType. Names that
+// do not exist only get an answer when a wildcard covers them, so any probe
+// answered with NOERROR reveals one. The records of every probe are collected,
+// as wildcards pointing at load balancers may answer differently each time.
+func (c *Client) DetectWildcard(ctx context.Context, zone string, recordType string) (*Wildcard, error) {
+	zone = mdns.CanonicalName(zone)
+	wildcard := &Wildcard{
+		Zone:    zone,
+		Probes:  make([]string, 0, wildcardProbes),
+		Records: make([]Record, 0),
+	}
+
+	for i := 0; i < wildcardProbes; i++ {
+		probe := strings.ToLower(rand.Text()) + "." + zone
+		if zone == "." {
+			probe = strings.ToLower(rand.Text()) + "."
+		}
+		wildcard.Probes = append(wildcard.Probes, probe)
+
+		slog.Debug("probing for wildcard", "name", probe, "type", recordType, "nameserver", c.nameserver)
+
+		resp, err := c.Query(ctx, probe, recordType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to probe %s: %w", probe, err)
+		}
+		if resp.Rcode != rcodeString(mdns.RcodeSuccess) {
+			continue
+		}
+
+		wildcard.Detected = true
+		records := resp.Records
+		if resp.Chain != nil {
+			records = append(records, resp.Chain.Records...)
+		}
+		for _, record := range records {
+			if !wildcard.Matches(record) {
+				wildcard.Records = append(wildcard.Records, record)
+			}
+		}
+	}
+
+	return wildcard, nil
+}
+
+// Matches reports whether record has the type and value of one of the records
+// the wildcard answers with.
+func (w *Wildcard) Matches(record Record) bool {
+	for _, answer := range w.Records {
+		if answer.Type == record.Type && strings.EqualFold(answer.Value, record.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Mark sets resp.Wildcard and flags the records of resp that match the
+// wildcard answer. Records of the zone apex are never flagged, as a wildcard
+// does not cover the name it is published under.
+func (w *Wildcard) Mark(resp *Response) {
+	resp.Wildcard = w
+	if !w.Detected || mdns.CanonicalName(resp.Domain) == w.Zone {
+		return
+	}
+
+	for i := range resp.Records {
+		resp.Records[i].Wildcard = w.Matches(resp.Records[i])
+	}
+	if resp.Chain != nil {
+		for i := range resp.Chain.Records {
+			resp.Chain.Records[i].Wildcard = w.Matches(resp.Chain.Records[i])
+		}
+	}
+}
+
+// QueryWildcard queries domain like Query, then probes for a wildcard under
+// the parent of the name and marks the records matching its answer. The
+// wildcard that would synthesize a name sits at its closest encloser, so the
+// probes go under the parent, or under the closest ancestor that exists when
+// the parent does not, without leaving the zone of the name.
+func (c *Client) QueryWildcard(ctx context.Context, domain string, recordType string) (*Response, error) {
+	resp, err := c.Query(ctx, domain, recordType)
+	if err != nil {
+		return nil, err
+	}
+
+	encloser, err := c.findEncloser(ctx, resp.Domain)
+	if err != nil {
+		return nil, err
+	}
+
+	wildcard, err := c.DetectWildcard(ctx, encloser, recordType)
+	if err != nil {
+		return nil, err
+	}
+	wildcard.Mark(resp)
+
+	return resp, nil
+}
+
+// findEncloser returns the name a wildcard covering name is published under:
+// its parent, walking up past the ancestors that do not exist, and stopping at
+// the apex of the zone of name. The apex itself is returned for the apex.
+func (c *Client) findEncloser(ctx context.Context, name string) (string, error) {
+	name = mdns.CanonicalName(name)
+
+	zone, err := c.findZone(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if name == zone || !mdns.IsSubDomain(zone, name) {
+		return zone, nil
+	}
+
+	encloser := parentName(name)
+	for encloser != zone {
+		resp, err := c.query(ctx, encloser, mdns.TypeSOA, "SOA")
+		if err != nil {
+			return "", fmt.Errorf("failed to find the closest encloser of %s: %w", name, err)
+		}
+		if resp.Rcode != rcodeString(mdns.RcodeNameError) {
+			break
+		}
+		encloser = parentName(encloser)
+	}
+
+	return encloser, nil
+}
+
+// findZone returns the zone name belongs to, from the SOA record answered for
+// it or returned in the authority section. Names that are aliases, or whose
+// SOA is not returned, are assumed to belong to their parent.
+func (c *Client) findZone(ctx context.Context, name string) (string, error) {
+	name = mdns.CanonicalName(name)
+
+	resp, err := c.query(ctx, name, mdns.TypeSOA, "SOA")
+	if err != nil {
+		return "", fmt.Errorf("failed to find the zone of %s: %w", name, err)
+	}
+
+	if _, _, ok := findCNAME(resp.Records, name); !ok {
+		for _, record := range append(resp.Records, resp.Authority...) {
+			if record.Type == "SOA" {
+				return mdns.CanonicalName(record.Name), nil
+			}
+		}
+	}
+
+	return parentName(name), nil
+}
+
+// parentName returns the name one label above name, or the root for the root
+// and top-level names.
+func parentName(name string) string {
+	if off, end := mdns.NextLabel(name, 0); !end {
+		return name[off:]
+	}
+	return "."
+}
@@ -0,0 +1,174 @@
+package dns
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+var wildcardZone = []string{
+	"example.com. 3600 IN SOA ns1.example.com. hostmaster.example.com. 1 7200 3600 1209600 300",
+	"example.com. 300 IN A 192.0.2.1",
+	"www.example.com. 300 IN A 192.0.2.10",
+	"mail.example.com. 300 IN MX 10 mx.example.com.",
+	"*.example.com. 300 IN A 192.0.2.99",
+}
+
+func TestClient_DetectWildcard(t *testing.T) {
+	addr := startTestServer(t, wildcardZoneHandler(t, wildcardZone...))
+
+	client := NewClient(time.Second, addr)
+
+	wildcard, err := client.DetectWildcard(context.Background(), "example.com", "A")
+	if err != nil {
+		t.Fatalf("DetectWildcard failed: %v", err)
+	}
+
+	if !wildcard.Detected || wildcard.Zone != "example.com." {
+		t.Fatalf("expected a wildcard under example.com., got %+v", wildcard)
+	}
+
+	if len(wildcard.Probes) != wildcardProbes {
+		t.Errorf("expected %d probes, got %v", wildcardProbes, wildcard.Probes)
+	}
+	for _, probe := range wildcard.Probes {
+		if !strings.HasSuffix(probe, ".example.com.") {
+			t.Errorf("expected probes under example.com., got %s", probe)
+		}
+	}
+
+	if len(wildcard.Records) != 1 || wildcard.Records[0].Value != "192.0.2.99" {
+		t.Errorf("expected the wildcard answer once, got %+v", wildcard.Records)
+	}
+
+	// The wildcard exists, but has no MX records.
+	wildcard, err = client.DetectWildcard(context.Background(), "example.com", "MX")
+	if err != nil {
+		t.Fatalf("DetectWildcard failed: %v", err)
+	}
+	if !wildcard.Detected || len(wildcard.Records) != 0 {
+		t.Errorf("expected a wildcard without MX records, got %+v", wildcard)
+	}
+}
+
+func TestClient_DetectWildcard_None(t *testing.T) {
+	addr := startTestServer(t, wildcardZoneHandler(t, wildcardZone[:4]...))
+
+	client := NewClient(time.Second, addr)
+
+	wildcard, err := client.DetectWildcard(context.Background(), "example.com", "A")
+	if err != nil {
+		t.Fatalf("DetectWildcard failed: %v", err)
+	}
+
+	if wildcard.Detected || len(wildcard.Records) != 0 {
+		t.Errorf("expected no wildcard, got %+v", wildcard)
+	}
+}
+
+func TestClient_QueryWildcard(t *testing.T) {
+	addr := startTestServer(t, wildcardZoneHandler(t, wildcardZone...))
+
+	client := NewClient(time.Second, addr)
+
+	resp, err := client.QueryWildcard(context.Background(), "typo.example.com", "A")
+	if err != nil {
+		t.Fatalf("QueryWildcard failed: %v", err)
+	}
+
+	if resp.Wildcard == nil || !resp.Wildcard.Detected || resp.Wildcard.Zone != "example.com." {
+		t.Fatalf("expected the wildcard of example.com., got %+v", resp.Wildcard)
+	}
+	if len(resp.Records) != 1 || !resp.Records[0].Wildcard {
+		t.Errorf("expected the synthesized record to be marked, got %+v", resp.Records)
+	}
+
+	resp, err = client.QueryWildcard(context.Background(), "www.example.com", "A")
+	if err != nil {
+		t.Fatalf("QueryWildcard failed: %v", err)
+	}
+	if len(resp.Records) != 1 || resp.Records[0].Wildcard {
+		t.Errorf("expected the real record not to be marked, got %+v", resp.Records)
+	}
+
+	// A wildcard does not cover the apex it is published under.
+	resp, err = client.QueryWildcard(context.Background(), "example.com", "A")
+	if err != nil {
+		t.Fatalf("QueryWildcard failed: %v", err)
+	}
+	if resp.Wildcard == nil || resp.Wildcard.Zone != "example.com." {
+		t.Fatalf("expected probes under the queried zone, got %+v", resp.Wildcard)
+	}
+	if len(resp.Records) != 1 || resp.Records[0].Wildcard {
+		t.Errorf("expected the apex record not to be marked, got %+v", resp.Records)
+	}
+}
+
+func TestClient_QueryWildcard_Intermediate(t *testing.T) {
+	addr := startTestServer(t, wildcardZoneHandler(t,
+		"example.com. 3600 IN SOA ns1.example.com. hostmaster.example.com. 1 7200 3600 1209600 300",
+		"*.example.com. 300 IN A 192.0.2.99",
+		"*.shop.example.com. 300 IN A 192.0.2.50",
+		"www.blog.example.com. 300 IN A 192.0.2.99",
+	))
+
+	client := NewClient(time.Second, addr)
+
+	// The name is synthesized by the wildcard of shop.example.com., not the
+	// one of the apex.
+	resp, err := client.QueryWildcard(context.Background(), "x.shop.example.com", "A")
+	if err != nil {
+		t.Fatalf("QueryWildcard failed: %v", err)
+	}
+	if resp.Wildcard == nil || !resp.Wildcard.Detected || resp.Wildcard.Zone != "shop.example.com." {
+		t.Fatalf("expected the wildcard of shop.example.com., got %+v", resp.Wildcard)
+	}
+	if len(resp.Records) != 1 || !resp.Records[0].Wildcard {
+		t.Errorf("expected the synthesized record to be marked, got %+v", resp.Records)
+	}
+
+	// blog.example.com. exists, so the wildcard of the apex does not cover
+	// names under it, even when they have the same address.
+	resp, err = client.QueryWildcard(context.Background(), "www.blog.example.com", "A")
+	if err != nil {
+		t.Fatalf("QueryWildcard failed: %v", err)
+	}
+	if resp.Wildcard == nil || resp.Wildcard.Detected || resp.Wildcard.Zone != "blog.example.com." {
+		t.Fatalf("expected no wildcard under blog.example.com., got %+v", resp.Wildcard)
+	}
+	if len(resp.Records) != 1 || resp.Records[0].Wildcard {
+		t.Errorf("expected the real record not to be marked, got %+v", resp.Records)
+	}
+}
+
+func TestWildcard_Mark(t *testing.T) {
+	wildcard := &Wildcard{
+		Zone:     "example.com.",
+		Detected: true,
+		Records: []Record{
+			{Type: "CNAME", Value: "lb.example.net."},
+			{Type: "A", Value: "192.0.2.99"},
+		},
+	}
+
+	resp := &Response{
+		Domain: "shop.example.com.",
+		Records: []Record{
+			{Name: "shop.example.com.", Type: "CNAME", Value: "LB.example.net."},
+			{Name: "lb.example.net.", Type: "A", Value: "192.0.2.99"},
+			{Name: "lb.example.net.", Type: "A", Value: "192.0.2.100"},
+		},
+	}
+
+	wildcard.Mark(resp)
+
+	if resp.Wildcard != wildcard {
+		t.Error("expected the wildcard to be attached to the response")
+	}
+	for i, expected := range []bool{true, true, false} {
+		if resp.Records[i].Wildcard != expected {
+			t.Errorf("record %d: expected wildcard %v, got %+v", i, expected, resp.Records[i])
+		}
+	}
+}
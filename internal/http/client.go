@@ -19,9 +19,20 @@ type Client struct {
 	httpClient      *http.Client
 	redirectChain   []string
 	redirectMu      sync.Mutex
+	maxBodySize     int64
 }
 
-func NewClient(timeout time.Duration, followRedirects bool, showTimings bool) *Client {
+// Option configures optional behaviour of a Client.
+type Option func(*Client)
+
+// WithBody keeps up to maxSize bytes of the response body in Response.Body.
+func WithBody(maxSize int64) Option {
+	return func(c *Client) {
+		c.maxBodySize = maxSize
+	}
+}
+
+func NewClient(timeout time.Duration, followRedirects bool, showTimings bool, opts ...Option) *Client {
 	client := &Client{
 		timeout:         timeout,
 		followRedirects: followRedirects,
@@ -29,6 +40,10 @@ func NewClient(timeout time.Duration, followRedirects bool, showTimings bool) *C
 		redirectChain:   make([]string, 0),
 	}
 
+	for _, opt := range opts {
+		opt(client)
+	}
+
 	httpClient := &http.Client{
 		Timeout: timeout,
 		Transport: &http.Transport{
@@ -120,7 +135,14 @@ func (c *Client) Fetch(ctx context.Context, url string) (*Response, error) {
 		contentTransferStart = time.Now()
 	}
 
-	_, readErr := io.Copy(io.Discard, resp.Body)
+	var body []byte
+	var readErr error
+	if c.maxBodySize > 0 {
+		body, readErr = io.ReadAll(io.LimitReader(resp.Body, c.maxBodySize))
+	}
+	if readErr == nil {
+		_, readErr = io.Copy(io.Discard, resp.Body)
+	}
 	_ = resp.Body.Close()
 
 	duration := time.Since(start)
@@ -159,6 +181,7 @@ func (c *Client) Fetch(ctx context.Context, url string) (*Response, error) {
 		Duration:         duration,
 		Timings:          timings,
 		RedirectChain:    redirectChainCopy,
+		Body:             string(body),
 	}
 
 	for key, values := range resp.Header {
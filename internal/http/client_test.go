@@ -78,6 +78,31 @@ func TestClient_Fetch_Success(t *testing.T) {
 	}
 }
 
+func TestClient_Fetch_WithBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := w.Write([]byte("NoSuchBucket: The specified bucket does not exist")); err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	resp, err := NewClient(5*time.Second, false, false).Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if resp.Body != "" {
+		t.Errorf("expected the body not to be kept by default, got %q", resp.Body)
+	}
+
+	resp, err = NewClient(5*time.Second, false, false, WithBody(12)).Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if resp.Body != "NoSuchBucket" {
+		t.Errorf("expected the body to be truncated to 12 bytes, got %q", resp.Body)
+	}
+}
+
 func TestClient_Fetch_Redirect(t *testing.T) {
 	finalServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -25,4 +25,6 @@ type Response struct {
 	TLSVersion       string            `json:"tlsVersion,omitempty"`
 	TLSCipherSuite   string            `json:"tlsCipherSuite,omitempty"`
 	RedirectChain    []string          `json:"redirectChain,omitempty"`
+	// Body is only kept when the client was created WithBody.
+	Body string `json:"body,omitempty"`
 }
@@ -0,0 +1,92 @@
+package idn
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// profile converts names following UTS #46 with the IDNA2008 validity rules
+// (non-transitional processing, Bidi and ContextJ rules). Underscores are
+// allowed in ASCII labels so service names like _dmarc keep working.
+var profile = idna.New(
+	idna.MapForLookup(),
+	idna.BidiRule(),
+	idna.Transitional(false),
+	idna.StrictDomainName(false),
+)
+
+// Name is a domain name in its A-label (ASCII) and U-label (Unicode) forms.
+// Warnings flag labels mixing scripts or made of characters confusable with
+// Latin letters.
+type Name struct {
+	ASCII    string   `json:"ascii"`
+	Unicode  string   `json:"unicode"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// IsIDN reports whether the name has internationalized labels.
+func (n *Name) IsIDN() bool {
+	return n.ASCII != n.Unicode
+}
+
+// ToASCII converts name to its A-label form. ASCII names are returned as they
+// are, so only names typed in Unicode are mapped and validated.
+func ToASCII(name string) (string, error) {
+	if isASCII(name) {
+		return name, nil
+	}
+
+	ascii, err := profile.ToASCII(name)
+	if err != nil {
+		return "", fmt.Errorf("invalid internationalized domain name %q: %w", name, err)
+	}
+	return ascii, nil
+}
+
+// Parse converts name, given in either form, to both forms and checks its
+// labels for mixed scripts and confusable characters. IP addresses are
+// returned as they are.
+func Parse(name string) (*Name, error) {
+	if net.ParseIP(name) != nil {
+		return &Name{ASCII: name, Unicode: name}, nil
+	}
+
+	ascii, err := ToASCII(name)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Name{ASCII: ascii, Unicode: ascii}
+	if !strings.Contains(strings.ToLower(ascii), "xn--") {
+		return result, nil
+	}
+
+	unicode, err := profile.ToUnicode(strings.ToLower(ascii))
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("not a valid IDNA2008 name: %v", err))
+		return result, nil
+	}
+	result.Unicode = unicode
+
+	for _, label := range strings.Split(unicode, ".") {
+		if isASCII(label) {
+			continue
+		}
+		result.Warnings = append(result.Warnings, checkLabel(label)...)
+	}
+
+	return result, nil
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,111 @@
+package idn
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToASCII(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"bücher.de", "xn--bcher-kva.de"},
+		{"BÜCHER.de.", "xn--bcher-kva.de."},
+		{"faß.de", "xn--fa-hia.de"},
+		{"例え.jp", "xn--r8jz45g.jp"},
+		{"example.com", "example.com"},
+		{"_dmarc.Example.com", "_dmarc.Example.com"},
+	}
+
+	for _, tt := range tests {
+		ascii, err := ToASCII(tt.input)
+		if err != nil {
+			t.Errorf("ToASCII(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if ascii != tt.expected {
+			t.Errorf("ToASCII(%q) = %q, want %q", tt.input, ascii, tt.expected)
+		}
+	}
+}
+
+func TestToASCII_Invalid(t *testing.T) {
+	for _, input := range []string{"bü\u200dcher.de", "-bücher.de", "אa.com"} {
+		if _, err := ToASCII(input); err == nil {
+			t.Errorf("expected ToASCII(%q) to fail", input)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, input := range []string{"bücher.de", "xn--bcher-kva.de", "XN--BCHER-KVA.DE"} {
+		name, err := Parse(input)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", input, err)
+		}
+		if !strings.EqualFold(name.ASCII, "xn--bcher-kva.de") || name.Unicode != "bücher.de" {
+			t.Errorf("Parse(%q) = %+v", input, name)
+		}
+		if !name.IsIDN() || len(name.Warnings) != 0 {
+			t.Errorf("expected an IDN without warnings, got %+v", name)
+		}
+	}
+
+	name, err := Parse("example.com")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if name.ASCII != "example.com" || name.Unicode != "example.com" || name.IsIDN() {
+		t.Errorf("unexpected name: %+v", name)
+	}
+
+	name, err = Parse("2001:db8::1")
+	if err != nil || name.ASCII != "2001:db8::1" {
+		t.Errorf("expected IP addresses to be kept, got %+v (%v)", name, err)
+	}
+}
+
+func TestParse_InvalidALabel(t *testing.T) {
+	name, err := Parse("xn--a.example")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if name.Unicode != "xn--a.example" || len(name.Warnings) != 1 {
+		t.Errorf("expected a warning for an invalid A-label, got %+v", name)
+	}
+}
+
+func TestParse_Warnings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		// Latin "p" followed by Cyrillic "аураl".
+		{"pаypal.com", "mixes scripts: Cyrillic, Latin"},
+		// All Cyrillic, looks like "apple".
+		{"аррӏе.com", `confusable with the Latin "apple"`},
+		// All Greek, looks like "ok".
+		{"οκ.example", `confusable with the Latin "ok"`},
+	}
+
+	for _, tt := range tests {
+		name, err := Parse(tt.input)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", tt.input, err)
+		}
+		if len(name.Warnings) != 1 || !strings.Contains(name.Warnings[0], tt.expected) {
+			t.Errorf("Parse(%q): expected a warning containing %q, got %v", tt.input, tt.expected, name.Warnings)
+		}
+	}
+
+	for _, input := range []string{"bücher.de", "東京タワー.jp", "ソニーsony.jp", "москва.рф", "한국어.kr"} {
+		name, err := Parse(input)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", input, err)
+		}
+		if len(name.Warnings) != 0 {
+			t.Errorf("Parse(%q): expected no warnings, got %v", input, name.Warnings)
+		}
+	}
+}
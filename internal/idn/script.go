@@ -0,0 +1,114 @@
+package idn
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// allowedScripts lists the script combinations UTS #39 accepts in a single
+// label at the "highly restrictive" level: Latin mixed with the scripts
+// written together in Japanese, Chinese and Korean.
+var allowedScripts = [][]string{
+	{"Latin", "Han", "Hiragana", "Katakana"},
+	{"Latin", "Han", "Bopomofo"},
+	{"Latin", "Han", "Hangul"},
+}
+
+// latinConfusables maps Cyrillic and Greek letters to the Latin letters they
+// are commonly mistaken for.
+var latinConfusables = map[rune]rune{
+	// Cyrillic
+	'а': 'a', 'с': 'c', 'ԁ': 'd', 'е': 'e', 'һ': 'h', 'і': 'i', 'ј': 'j',
+	'ӏ': 'l', 'о': 'o', 'р': 'p', 'ԛ': 'q', 'ѕ': 's', 'ԝ': 'w', 'х': 'x',
+	'у': 'y',
+	// Greek
+	'α': 'a', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o', 'ρ': 'p', 'υ': 'u',
+}
+
+// checkLabel flags a Unicode label that mixes scripts, or that is written in
+// another script using only letters that look like Latin ones.
+func checkLabel(label string) []string {
+	scripts := labelScripts(label)
+
+	if len(scripts) > 1 && !scriptsAllowed(scripts) {
+		return []string{fmt.Sprintf("label %q mixes scripts: %s", label, strings.Join(scripts, ", "))}
+	}
+
+	if len(scripts) == 1 && scripts[0] != "Latin" {
+		if skeleton, ok := latinSkeleton(label); ok {
+			return []string{fmt.Sprintf("label %q is written in %s but is confusable with the Latin %q", label, scripts[0], skeleton)}
+		}
+	}
+
+	return nil
+}
+
+// labelScripts returns the sorted scripts of the characters of label, leaving
+// out the characters shared by every script, like digits and the hyphen.
+func labelScripts(label string) []string {
+	seen := make(map[string]bool)
+	for _, r := range label {
+		if unicode.In(r, unicode.Common, unicode.Inherited) {
+			continue
+		}
+		for name, table := range unicode.Scripts {
+			if unicode.Is(table, r) {
+				seen[name] = true
+				break
+			}
+		}
+	}
+
+	scripts := make([]string, 0, len(seen))
+	for name := range seen {
+		scripts = append(scripts, name)
+	}
+	sort.Strings(scripts)
+
+	return scripts
+}
+
+func scriptsAllowed(scripts []string) bool {
+	for _, allowed := range allowedScripts {
+		ok := true
+		for _, script := range scripts {
+			if !containsString(allowed, script) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// latinSkeleton returns the Latin label a label looks like, when every one of
+// its letters is confusable with a Latin letter.
+func latinSkeleton(label string) (string, bool) {
+	var b strings.Builder
+	for _, r := range label {
+		if unicode.In(r, unicode.Common, unicode.Inherited) {
+			b.WriteRune(r)
+			continue
+		}
+		latin, ok := latinConfusables[r]
+		if !ok {
+			return "", false
+		}
+		b.WriteRune(latin)
+	}
+	return b.String(), true
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
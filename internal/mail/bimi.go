@@ -0,0 +1,65 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// checkBIMI validates the default BIMI record of domain. BIMI is only honored
+// by mailbox providers when DMARC is enforced, so the DMARC result is taken
+// into account.
+func (c *Client) checkBIMI(ctx context.Context, domain string, dmarc *DMARCResult) *BIMIResult {
+	result := &BIMIResult{Status: StatusPass}
+
+	warn := func(format string, args ...interface{}) {
+		result.Status = worst(result.Status, StatusWarn)
+		result.Issues = append(result.Issues, fmt.Sprintf(format, args...))
+	}
+	fail := func(format string, args ...interface{}) {
+		result.Status = StatusFail
+		result.Issues = append(result.Issues, fmt.Sprintf(format, args...))
+	}
+
+	records, err := c.lookupVersioned(ctx, "default._bimi."+domain, "v=BIMI1")
+	if err != nil {
+		fail("%v", err)
+		return result
+	}
+
+	switch len(records) {
+	case 0:
+		warn("no BIMI record found")
+		return result
+	case 1:
+		result.Record = records[0]
+	default:
+		fail("%d BIMI records found, only one is allowed", len(records))
+		return result
+	}
+
+	tags := parseTags(result.Record)
+	result.Logo = tags["l"]
+	result.Certificate = tags["a"]
+
+	if result.Logo == "" && result.Certificate == "" {
+		warn("record declines to publish a logo")
+		return result
+	}
+
+	if !strings.HasPrefix(strings.ToLower(result.Logo), "https://") {
+		fail("logo URL %q must use https", result.Logo)
+	}
+
+	if result.Certificate == "" {
+		warn("no verified mark certificate (a=), some mailbox providers require one")
+	} else if !strings.HasPrefix(strings.ToLower(result.Certificate), "https://") {
+		fail("certificate URL %q must use https", result.Certificate)
+	}
+
+	if dmarc == nil || (dmarc.Policy != "quarantine" && dmarc.Policy != "reject") || dmarc.Percent < 100 {
+		warn("DMARC must be enforced (p=quarantine or p=reject, pct=100) for BIMI to be displayed")
+	}
+
+	return result
+}
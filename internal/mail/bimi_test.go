@@ -0,0 +1,74 @@
+package mail
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestClient_CheckBIMI(t *testing.T) {
+	enforced := &DMARCResult{Policy: "reject", Percent: 100}
+	monitoring := &DMARCResult{Policy: "none", Percent: 100}
+
+	tests := []struct {
+		name   string
+		record string
+		dmarc  *DMARCResult
+		status string
+		issue  string
+	}{
+		{
+			name:   "valid",
+			record: `default._bimi.example.com. 300 IN TXT "v=BIMI1; l=https://example.com/logo.svg; a=https://example.com/vmc.pem"`,
+			dmarc:  enforced,
+			status: StatusPass,
+		},
+		{
+			name:   "missing",
+			dmarc:  enforced,
+			status: StatusWarn,
+			issue:  "no BIMI record found",
+		},
+		{
+			name:   "no certificate",
+			record: `default._bimi.example.com. 300 IN TXT "v=BIMI1; l=https://example.com/logo.svg"`,
+			dmarc:  enforced,
+			status: StatusWarn,
+			issue:  "no verified mark certificate",
+		},
+		{
+			name:   "insecure logo",
+			record: `default._bimi.example.com. 300 IN TXT "v=BIMI1; l=http://example.com/logo.svg; a=https://example.com/vmc.pem"`,
+			dmarc:  enforced,
+			status: StatusFail,
+			issue:  "must use https",
+		},
+		{
+			name:   "dmarc not enforced",
+			record: `default._bimi.example.com. 300 IN TXT "v=BIMI1; l=https://example.com/logo.svg; a=https://example.com/vmc.pem"`,
+			dmarc:  monitoring,
+			status: StatusWarn,
+			issue:  "DMARC must be enforced",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := []string{}
+			if tt.record != "" {
+				records = append(records, tt.record)
+			}
+			client := newTestClient(t, records...)
+
+			result := client.checkBIMI(context.Background(), "example.com", tt.dmarc)
+
+			if result.Status != tt.status {
+				t.Errorf("expected %s, got %s: %v", tt.status, result.Status, result.Issues)
+			}
+
+			if tt.issue != "" && !strings.Contains(strings.Join(result.Issues, "\n"), tt.issue) {
+				t.Errorf("expected an issue containing %q, got %v", tt.issue, result.Issues)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	dnsinfo "watchr/internal/dns"
+	"watchr/internal/idn"
+)
+
+type Client struct {
+	timeout    time.Duration
+	dnsClient  *dnsinfo.Client
+	httpClient *http.Client
+	// policyURL returns the URL of the MTA-STS policy of a domain.
+	policyURL func(domain string) string
+}
+
+func NewClient(timeout time.Duration, dnsClient *dnsinfo.Client) *Client {
+	return &Client{
+		timeout:   timeout,
+		dnsClient: dnsClient,
+		httpClient: &http.Client{
+			Timeout: timeout,
+			// RFC 8461 section 3.3: redirects must not be followed when
+			// fetching the policy.
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		policyURL: func(domain string) string {
+			return "https://mta-sts." + domain + "/.well-known/mta-sts.txt"
+		},
+	}
+}
+
+// Audit checks the email authentication records published by domain: SPF,
+// DMARC, DKIM (for the given selectors, or a list of common ones when none is
+// given), MTA-STS, TLS-RPT and BIMI.
+func (c *Client) Audit(ctx context.Context, domain string, selectors []string) (*Response, error) {
+	domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	if domain == "" {
+		return nil, fmt.Errorf("domain is required")
+	}
+
+	name, err := idn.Parse(domain)
+	if err != nil {
+		return nil, err
+	}
+	domain = name.ASCII
+
+	slog.Debug("auditing email authentication", "domain", domain)
+	start := time.Now()
+
+	response := &Response{Domain: domain, IDN: name}
+
+	var wg sync.WaitGroup
+	wg.Add(4)
+	go func() {
+		defer wg.Done()
+		response.SPF = c.checkSPF(ctx, domain)
+	}()
+	go func() {
+		defer wg.Done()
+		response.DKIM = c.checkDKIM(ctx, domain, selectors)
+	}()
+	go func() {
+		defer wg.Done()
+		response.MTASTS = c.checkMTASTS(ctx, domain)
+	}()
+	go func() {
+		defer wg.Done()
+		response.TLSRPT = c.checkTLSRPT(ctx, domain)
+	}()
+
+	response.DMARC = c.checkDMARC(ctx, domain)
+	response.BIMI = c.checkBIMI(ctx, domain, response.DMARC)
+	wg.Wait()
+
+	response.Status = worst(
+		response.SPF.Status,
+		response.DMARC.Status,
+		response.DKIM.Status,
+		response.MTASTS.Status,
+		response.TLSRPT.Status,
+		response.BIMI.Status,
+	)
+	response.QueryTime = time.Since(start)
+
+	return response, nil
+}
+
+// lookupTXT returns the TXT records published at name, with the strings of
+// each record concatenated as required by RFC 7208 section 3.3. A name that
+// does not exist has no records and is not an error.
+func (c *Client) lookupTXT(ctx context.Context, name string) ([]string, error) {
+	resp, err := c.dnsClient.Query(ctx, name, "TXT")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Rcode != "" && resp.Rcode != "NOERROR" && resp.Rcode != "NXDOMAIN" {
+		return nil, fmt.Errorf("TXT lookup for %s failed: %s", name, resp.Rcode)
+	}
+
+	records := make([]string, 0, len(resp.Records))
+	for _, record := range resp.Records {
+		if record.Type != "TXT" {
+			continue
+		}
+		if data, ok := record.Data.(*dnsinfo.TXTData); ok {
+			records = append(records, strings.Join(data.Strings, ""))
+		} else {
+			records = append(records, record.Value)
+		}
+	}
+
+	return records, nil
+}
+
+// lookupVersioned returns the records at name starting with the version tag,
+// e.g. "v=DMARC1", compared case-insensitively.
+func (c *Client) lookupVersioned(ctx context.Context, name string, version string) ([]string, error) {
+	records, err := c.lookupTXT(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	matching := make([]string, 0, len(records))
+	for _, record := range records {
+		tag, _, _ := strings.Cut(strings.TrimSpace(record), ";")
+		if strings.EqualFold(strings.Join(strings.Fields(tag), ""), version) {
+			matching = append(matching, record)
+		}
+	}
+
+	return matching, nil
+}
+
+// parseTags parses a "tag=value; tag=value" list, as used by DMARC, DKIM,
+// MTA-STS, TLS-RPT and BIMI records. Tag names are lower-cased.
+func parseTags(record string) map[string]string {
+	tags := make(map[string]string)
+	for _, part := range strings.Split(record, ";") {
+		name, value, found := strings.Cut(part, "=")
+		if !found {
+			continue
+		}
+		tags[strings.ToLower(strings.TrimSpace(name))] = strings.TrimSpace(value)
+	}
+	return tags
+}
+
+// splitList splits a comma-separated tag value, dropping empty entries.
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// worst returns the worst of the given statuses.
+func worst(statuses ...string) string {
+	result := StatusPass
+	for _, status := range statuses {
+		switch status {
+		case StatusFail:
+			return StatusFail
+		case StatusWarn:
+			result = StatusWarn
+		}
+	}
+	return result
+}
@@ -0,0 +1,88 @@
+package mail
+
+import (
+	"context"
+	"testing"
+)
+
+func TestClient_Audit(t *testing.T) {
+	client := newTestClient(t,
+		`example.com. 300 IN TXT "v=spf1 mx -all"`,
+		`_dmarc.example.com. 300 IN TXT "v=DMARC1; p=reject; rua=mailto:dmarc@example.com"`,
+		`_smtp._tls.example.com. 300 IN TXT "v=TLSRPTv1; rua=mailto:tlsrpt@example.com"`,
+	)
+
+	resp, err := client.Audit(context.Background(), "Example.COM.", []string{"missing"})
+	if err != nil {
+		t.Fatalf("Audit failed: %v", err)
+	}
+
+	if resp.Domain != "example.com" {
+		t.Errorf("expected normalized domain, got %s", resp.Domain)
+	}
+
+	if resp.SPF.Status != StatusPass || resp.DMARC.Status != StatusPass || resp.TLSRPT.Status != StatusPass {
+		t.Errorf("unexpected statuses: spf=%s dmarc=%s tlsrpt=%s", resp.SPF.Status, resp.DMARC.Status, resp.TLSRPT.Status)
+	}
+
+	if resp.MTASTS.Status != StatusWarn || resp.BIMI.Status != StatusWarn {
+		t.Errorf("expected warnings for the missing MTA-STS and BIMI records, got %s and %s", resp.MTASTS.Status, resp.BIMI.Status)
+	}
+
+	// The explicitly requested DKIM selector is missing.
+	if resp.DKIM.Status != StatusFail || resp.Status != StatusFail {
+		t.Errorf("expected the missing DKIM selector to fail the audit, got %s and %s", resp.DKIM.Status, resp.Status)
+	}
+}
+
+func TestClient_Audit_IDN(t *testing.T) {
+	client := newTestClient(t,
+		`xn--bcher-kva.de. 300 IN TXT "v=spf1 -all"`,
+	)
+
+	resp, err := client.Audit(context.Background(), "bücher.de", nil)
+	if err != nil {
+		t.Fatalf("Audit failed: %v", err)
+	}
+
+	if resp.Domain != "xn--bcher-kva.de" || resp.SPF.Status != StatusPass {
+		t.Errorf("expected the A-label to be audited, got %s with SPF %s", resp.Domain, resp.SPF.Status)
+	}
+	if resp.IDN == nil || resp.IDN.Unicode != "bücher.de" {
+		t.Errorf("expected the U-label in the response, got %+v", resp.IDN)
+	}
+}
+
+func TestClient_Audit_EmptyDomain(t *testing.T) {
+	client := newTestClient(t)
+
+	if _, err := client.Audit(context.Background(), " ", nil); err == nil {
+		t.Error("expected error for an empty domain")
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	tags := parseTags("v=DMARC1; P=reject ;rua = mailto:a@example.com; invalid;")
+
+	if tags["v"] != "DMARC1" || tags["p"] != "reject" || tags["rua"] != "mailto:a@example.com" {
+		t.Errorf("unexpected tags: %v", tags)
+	}
+
+	if _, ok := tags["invalid"]; ok {
+		t.Error("expected tags without a value to be ignored")
+	}
+}
+
+func TestWorst(t *testing.T) {
+	if got := worst(StatusPass, StatusWarn, StatusPass); got != StatusWarn {
+		t.Errorf("expected warn, got %s", got)
+	}
+
+	if got := worst(StatusWarn, StatusFail, StatusPass); got != StatusFail {
+		t.Errorf("expected fail, got %s", got)
+	}
+
+	if got := worst(); got != StatusPass {
+		t.Errorf("expected pass, got %s", got)
+	}
+}
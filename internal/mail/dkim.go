@@ -0,0 +1,183 @@
+package mail
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// CommonDKIMSelectors are the selectors probed when none is given.
+var CommonDKIMSelectors = []string{
+	"default", "dkim", "mail", "email", "selector1", "selector2", "google",
+	"k1", "k2", "k3", "s1", "s2", "smtp", "mx", "pm", "mandrill", "mailjet",
+	"zendesk1", "zendesk2", "protonmail", "fm1", "fm2", "fm3",
+}
+
+// checkDKIM looks up the DKIM keys of the given selectors, or of the common
+// selectors when none is given, and validates them. Missing common selectors
+// are not reported.
+func (c *Client) checkDKIM(ctx context.Context, domain string, selectors []string) *DKIMResult {
+	explicit := len(selectors) > 0
+	if !explicit {
+		selectors = CommonDKIMSelectors
+	}
+
+	found := make([]*DKIMSelector, len(selectors))
+
+	var wg sync.WaitGroup
+	for i, selector := range selectors {
+		wg.Add(1)
+		go func(i int, selector string) {
+			defer wg.Done()
+			found[i] = c.checkDKIMSelector(ctx, domain, selector, explicit)
+		}(i, selector)
+	}
+	wg.Wait()
+
+	result := &DKIMResult{
+		Status:    StatusPass,
+		Selectors: make([]DKIMSelector, 0),
+	}
+	for _, selector := range found {
+		if selector == nil {
+			continue
+		}
+		result.Selectors = append(result.Selectors, *selector)
+		result.Status = worst(result.Status, selector.Status)
+	}
+
+	if len(result.Selectors) == 0 {
+		result.Status = StatusWarn
+		result.Issues = append(result.Issues, "no DKIM key found for the common selectors, use --dkim-selector to check specific ones")
+	}
+
+	return result
+}
+
+// checkDKIMSelector validates the key published for selector. It returns nil
+// when the selector has no record and reportMissing is not set.
+func (c *Client) checkDKIMSelector(ctx context.Context, domain string, selector string, reportMissing bool) *DKIMSelector {
+	result := &DKIMSelector{
+		Selector: selector,
+		Status:   StatusPass,
+	}
+
+	records, err := c.lookupTXT(ctx, selector+"._domainkey."+domain)
+	if err != nil {
+		if !reportMissing {
+			return nil
+		}
+		result.Status = StatusFail
+		result.Issues = append(result.Issues, err.Error())
+		return result
+	}
+
+	keys := make([]string, 0, 1)
+	for _, record := range records {
+		if _, ok := parseTags(record)["p"]; ok {
+			keys = append(keys, record)
+		}
+	}
+
+	if len(keys) == 0 {
+		if !reportMissing {
+			return nil
+		}
+		result.Status = StatusFail
+		result.Issues = append(result.Issues, "no DKIM key found")
+		return result
+	}
+
+	result.Record = keys[0]
+	if len(keys) > 1 {
+		result.Status = StatusFail
+		result.Issues = append(result.Issues, fmt.Sprintf("%d DKIM keys found, only one is allowed", len(keys)))
+	}
+
+	tags := parseTags(result.Record)
+
+	if version, ok := tags["v"]; ok && version != "DKIM1" {
+		result.Status = StatusFail
+		result.Issues = append(result.Issues, fmt.Sprintf("invalid version %q", version))
+	}
+
+	if strings.Contains(strings.ToLower(tags["t"]), "y") {
+		result.Status = worst(result.Status, StatusWarn)
+		result.Issues = append(result.Issues, "key is in testing mode (t=y)")
+	}
+
+	result.KeyType = strings.ToLower(tags["k"])
+	if result.KeyType == "" {
+		result.KeyType = "rsa"
+	}
+
+	// The key may contain whitespace where the record was folded.
+	encoded := strings.Join(strings.Fields(tags["p"]), "")
+	if encoded == "" {
+		result.Status = StatusFail
+		result.Issues = append(result.Issues, "key has been revoked (empty p tag)")
+		return result
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		result.Status = StatusFail
+		result.Issues = append(result.Issues, fmt.Sprintf("invalid key encoding: %v", err))
+		return result
+	}
+
+	switch result.KeyType {
+	case "rsa":
+		key, err := parseRSAKey(raw)
+		if err != nil {
+			result.Status = StatusFail
+			result.Issues = append(result.Issues, err.Error())
+			return result
+		}
+
+		result.KeyBits = key.N.BitLen()
+		switch {
+		case result.KeyBits < 1024:
+			result.Status = StatusFail
+			result.Issues = append(result.Issues, fmt.Sprintf("%d-bit RSA key is too weak", result.KeyBits))
+		case result.KeyBits < 2048:
+			result.Status = worst(result.Status, StatusWarn)
+			result.Issues = append(result.Issues, fmt.Sprintf("%d-bit RSA key, 2048 bits are recommended", result.KeyBits))
+		}
+	case "ed25519":
+		if len(raw) != ed25519.PublicKeySize {
+			result.Status = StatusFail
+			result.Issues = append(result.Issues, fmt.Sprintf("invalid Ed25519 key length %d", len(raw)))
+			return result
+		}
+		result.KeyBits = ed25519.PublicKeySize * 8
+	default:
+		result.Status = StatusFail
+		result.Issues = append(result.Issues, fmt.Sprintf("unknown key type %q", result.KeyType))
+	}
+
+	return result
+}
+
+// parseRSAKey parses a DKIM RSA key, which is usually a SubjectPublicKeyInfo
+// structure but is sometimes published as a bare PKCS #1 key.
+func parseRSAKey(raw []byte) (*rsa.PublicKey, error) {
+	if key, err := x509.ParsePKIXPublicKey(raw); err == nil {
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("key is not an RSA key")
+		}
+		return rsaKey, nil
+	}
+
+	key, err := x509.ParsePKCS1PublicKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RSA key: %v", err)
+	}
+	return key, nil
+}
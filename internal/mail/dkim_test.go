@@ -0,0 +1,124 @@
+package mail
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// dkimRecord returns a TXT record publishing key for selector, with the key
+// split across several strings as large keys are.
+func dkimRecord(t *testing.T, selector string, keyType string, key interface{}) string {
+	t.Helper()
+
+	var raw []byte
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		raw = k
+	default:
+		der, err := x509.MarshalPKIXPublicKey(key)
+		if err != nil {
+			t.Fatalf("failed to marshal key: %v", err)
+		}
+		raw = der
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(raw)
+	parts := make([]string, 0)
+	for len(encoded) > 200 {
+		parts = append(parts, encoded[:200])
+		encoded = encoded[200:]
+	}
+	parts = append(parts, encoded)
+
+	return fmt.Sprintf(`%s._domainkey.example.com. 300 IN TXT "v=DKIM1; k=%s; p=%s"`, selector, keyType, strings.Join(parts, `" "`))
+}
+
+func TestClient_CheckDKIM(t *testing.T) {
+	strong, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	edKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	client := newTestClient(t,
+		dkimRecord(t, "selector1", "rsa", &strong.PublicKey),
+		dkimRecord(t, "k1", "rsa", &weak.PublicKey),
+		dkimRecord(t, "ed", "ed25519", edKey),
+		`revoked._domainkey.example.com. 300 IN TXT "v=DKIM1; p="`,
+	)
+
+	result := client.checkDKIM(context.Background(), "example.com", []string{"selector1", "k1", "ed", "revoked", "missing"})
+
+	if result.Status != StatusFail {
+		t.Errorf("expected fail, got %s", result.Status)
+	}
+
+	if len(result.Selectors) != 5 {
+		t.Fatalf("expected 5 selectors, got %d", len(result.Selectors))
+	}
+
+	expected := []struct {
+		status string
+		bits   int
+		issue  string
+	}{
+		{StatusPass, 2048, ""},
+		{StatusWarn, 1024, "2048 bits are recommended"},
+		{StatusPass, 256, ""},
+		{StatusFail, 0, "revoked"},
+		{StatusFail, 0, "no DKIM key found"},
+	}
+
+	for i, want := range expected {
+		selector := result.Selectors[i]
+		if selector.Status != want.status || selector.KeyBits != want.bits {
+			t.Errorf("%s: expected %s with %d bits, got %s with %d bits: %v", selector.Selector, want.status, want.bits, selector.Status, selector.KeyBits, selector.Issues)
+		}
+		if want.issue != "" && !strings.Contains(strings.Join(selector.Issues, "\n"), want.issue) {
+			t.Errorf("%s: expected an issue containing %q, got %v", selector.Selector, want.issue, selector.Issues)
+		}
+	}
+}
+
+func TestClient_CheckDKIM_CommonSelectors(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	client := newTestClient(t, dkimRecord(t, "google", "rsa", &key.PublicKey))
+
+	result := client.checkDKIM(context.Background(), "example.com", nil)
+
+	if result.Status != StatusPass {
+		t.Errorf("expected pass, got %s: %v", result.Status, result.Issues)
+	}
+
+	if len(result.Selectors) != 1 || result.Selectors[0].Selector != "google" {
+		t.Errorf("expected only the google selector, got %+v", result.Selectors)
+	}
+}
+
+func TestClient_CheckDKIM_NoneFound(t *testing.T) {
+	client := newTestClient(t)
+
+	result := client.checkDKIM(context.Background(), "example.com", nil)
+
+	if result.Status != StatusWarn || len(result.Selectors) != 0 {
+		t.Errorf("expected a warning without selectors, got %+v", result)
+	}
+}
@@ -0,0 +1,140 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// checkDMARC validates the DMARC record of domain (RFC 7489), and that the
+// external domains receiving its reports have authorized them.
+func (c *Client) checkDMARC(ctx context.Context, domain string) *DMARCResult {
+	result := &DMARCResult{
+		Status:  StatusPass,
+		Percent: 100,
+		ADKIM:   "r",
+		ASPF:    "r",
+	}
+
+	records, err := c.lookupVersioned(ctx, "_dmarc."+domain, "v=DMARC1")
+	if err != nil {
+		result.Status = StatusFail
+		result.Issues = append(result.Issues, err.Error())
+		return result
+	}
+
+	switch len(records) {
+	case 0:
+		result.Status = StatusFail
+		result.Issues = append(result.Issues, "no DMARC record found")
+		return result
+	case 1:
+		result.Record = records[0]
+	default:
+		result.Status = StatusFail
+		result.Issues = append(result.Issues, fmt.Sprintf("%d DMARC records found, only one is allowed", len(records)))
+		return result
+	}
+
+	fail := func(format string, args ...interface{}) {
+		result.Status = StatusFail
+		result.Issues = append(result.Issues, fmt.Sprintf(format, args...))
+	}
+	warn := func(format string, args ...interface{}) {
+		result.Status = worst(result.Status, StatusWarn)
+		result.Issues = append(result.Issues, fmt.Sprintf(format, args...))
+	}
+
+	tags := parseTags(result.Record)
+
+	result.Policy = strings.ToLower(tags["p"])
+	switch result.Policy {
+	case "":
+		fail("missing required \"p\" tag")
+	case "none":
+		warn("policy \"none\" only monitors, failing messages are still delivered")
+	case "quarantine", "reject":
+	default:
+		fail("invalid policy %q", result.Policy)
+	}
+
+	if sp, ok := tags["sp"]; ok {
+		result.SubdomainPolicy = strings.ToLower(sp)
+		switch result.SubdomainPolicy {
+		case "none":
+			warn("subdomain policy \"none\" only monitors")
+		case "quarantine", "reject":
+		default:
+			fail("invalid subdomain policy %q", sp)
+		}
+	}
+
+	if pct, ok := tags["pct"]; ok {
+		percent, err := strconv.Atoi(pct)
+		if err != nil || percent < 0 || percent > 100 {
+			fail("invalid percentage %q", pct)
+		} else {
+			result.Percent = percent
+			if percent < 100 {
+				warn("policy only applies to %d%% of failing messages", percent)
+			}
+		}
+	}
+
+	for tag, value := range map[string]*string{"adkim": &result.ADKIM, "aspf": &result.ASPF} {
+		if mode, ok := tags[tag]; ok {
+			*value = strings.ToLower(mode)
+			if *value != "r" && *value != "s" {
+				fail("invalid %s alignment mode %q", tag, mode)
+			}
+		}
+	}
+
+	result.RUA = reportAddresses(tags["rua"])
+	result.RUF = reportAddresses(tags["ruf"])
+
+	if len(result.RUA) == 0 {
+		warn("no aggregate report address (rua), DMARC failures go unnoticed")
+	}
+
+	for _, address := range append(append([]string{}, result.RUA...), result.RUF...) {
+		if !strings.HasPrefix(strings.ToLower(address), "mailto:") {
+			fail("report address %q is not a mailto: URI", address)
+			continue
+		}
+
+		_, reportDomain, found := strings.Cut(address, "@")
+		if !found {
+			fail("report address %q has no domain", address)
+			continue
+		}
+
+		reportDomain = strings.TrimSuffix(strings.ToLower(reportDomain), ".")
+		if reportDomain == domain || strings.HasSuffix(reportDomain, "."+domain) || strings.HasSuffix(domain, "."+reportDomain) {
+			continue
+		}
+
+		// RFC 7489 section 7.1: external destinations must publish a record
+		// authorizing reports for the domain.
+		authorization, err := c.lookupVersioned(ctx, domain+"._report._dmarc."+reportDomain, "v=DMARC1")
+		if err != nil || len(authorization) == 0 {
+			warn("%s has not authorized receiving reports for %s", reportDomain, domain)
+		}
+	}
+
+	return result
+}
+
+// reportAddresses splits a DMARC report URI list, dropping the optional size
+// limits.
+func reportAddresses(value string) []string {
+	addresses := make([]string, 0)
+	for _, uri := range splitList(value) {
+		if i := strings.LastIndex(uri, "!"); i > 0 {
+			uri = uri[:i]
+		}
+		addresses = append(addresses, uri)
+	}
+	return addresses
+}
@@ -0,0 +1,113 @@
+package mail
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestClient_CheckDMARC(t *testing.T) {
+	client := newTestClient(t,
+		`_dmarc.example.com. 300 IN TXT "v=DMARC1; p=reject; sp=quarantine; adkim=s; rua=mailto:dmarc@example.com,mailto:reports@dmarc.example.net!10m; ruf=mailto:forensic@example.com"`,
+		`example.com._report._dmarc.dmarc.example.net. 300 IN TXT "v=DMARC1"`,
+	)
+
+	result := client.checkDMARC(context.Background(), "example.com")
+
+	if result.Status != StatusPass {
+		t.Errorf("expected pass, got %s: %v", result.Status, result.Issues)
+	}
+
+	if result.Policy != "reject" || result.SubdomainPolicy != "quarantine" {
+		t.Errorf("unexpected policies: %s %s", result.Policy, result.SubdomainPolicy)
+	}
+
+	if result.ADKIM != "s" || result.ASPF != "r" || result.Percent != 100 {
+		t.Errorf("unexpected alignment or percentage: %+v", result)
+	}
+
+	if strings.Join(result.RUA, ",") != "mailto:dmarc@example.com,mailto:reports@dmarc.example.net" {
+		t.Errorf("unexpected aggregate report addresses: %v", result.RUA)
+	}
+
+	if len(result.RUF) != 1 {
+		t.Errorf("unexpected forensic report addresses: %v", result.RUF)
+	}
+}
+
+func TestClient_CheckDMARC_Issues(t *testing.T) {
+	tests := []struct {
+		name   string
+		record string
+		status string
+		issue  string
+	}{
+		{
+			name:   "missing",
+			status: StatusFail,
+			issue:  "no DMARC record found",
+		},
+		{
+			name:   "monitoring only",
+			record: `_dmarc.example.com. 300 IN TXT "v=DMARC1; p=none; rua=mailto:dmarc@example.com"`,
+			status: StatusWarn,
+			issue:  "policy \"none\"",
+		},
+		{
+			name:   "missing policy",
+			record: `_dmarc.example.com. 300 IN TXT "v=DMARC1; rua=mailto:dmarc@example.com"`,
+			status: StatusFail,
+			issue:  "missing required \"p\" tag",
+		},
+		{
+			name:   "partial",
+			record: `_dmarc.example.com. 300 IN TXT "v=DMARC1; p=quarantine; pct=50; rua=mailto:dmarc@example.com"`,
+			status: StatusWarn,
+			issue:  "50% of failing messages",
+		},
+		{
+			name:   "no reports",
+			record: `_dmarc.example.com. 300 IN TXT "v=DMARC1; p=reject"`,
+			status: StatusWarn,
+			issue:  "no aggregate report address",
+		},
+		{
+			name:   "unauthorized external reports",
+			record: `_dmarc.example.com. 300 IN TXT "v=DMARC1; p=reject; rua=mailto:dmarc@example.net"`,
+			status: StatusWarn,
+			issue:  "example.net has not authorized receiving reports",
+		},
+		{
+			name:   "invalid report address",
+			record: `_dmarc.example.com. 300 IN TXT "v=DMARC1; p=reject; rua=https://example.com/dmarc"`,
+			status: StatusFail,
+			issue:  "is not a mailto: URI",
+		},
+		{
+			name:   "invalid alignment",
+			record: `_dmarc.example.com. 300 IN TXT "v=DMARC1; p=reject; aspf=x; rua=mailto:dmarc@example.com"`,
+			status: StatusFail,
+			issue:  "invalid aspf alignment mode",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := []string{}
+			if tt.record != "" {
+				records = append(records, tt.record)
+			}
+			client := newTestClient(t, records...)
+
+			result := client.checkDMARC(context.Background(), "example.com")
+
+			if result.Status != tt.status {
+				t.Errorf("expected %s, got %s: %v", tt.status, result.Status, result.Issues)
+			}
+
+			if !strings.Contains(strings.Join(result.Issues, "\n"), tt.issue) {
+				t.Errorf("expected an issue containing %q, got %v", tt.issue, result.Issues)
+			}
+		})
+	}
+}
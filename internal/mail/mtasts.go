@@ -0,0 +1,248 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	dnsinfo "watchr/internal/dns"
+)
+
+const (
+	// maxPolicySize limits the size of the MTA-STS policy read, as suggested
+	// by RFC 8461 section 3.3.
+	maxPolicySize = 64 * 1024
+	// maxPolicyAge is the largest max_age allowed by RFC 8461 section 3.2.
+	maxPolicyAge = 31557600
+)
+
+var mtaSTSID = regexp.MustCompile(`^[A-Za-z0-9]{1,32}$`)
+
+// checkMTASTS validates the MTA-STS record of domain, fetches its policy over
+// HTTPS and checks that it covers the domain's MX hosts (RFC 8461).
+func (c *Client) checkMTASTS(ctx context.Context, domain string) *MTASTSResult {
+	result := &MTASTSResult{Status: StatusPass}
+
+	fail := func(format string, args ...interface{}) {
+		result.Status = StatusFail
+		result.Issues = append(result.Issues, fmt.Sprintf(format, args...))
+	}
+	warn := func(format string, args ...interface{}) {
+		result.Status = worst(result.Status, StatusWarn)
+		result.Issues = append(result.Issues, fmt.Sprintf(format, args...))
+	}
+
+	records, err := c.lookupVersioned(ctx, "_mta-sts."+domain, "v=STSv1")
+	if err != nil {
+		fail("%v", err)
+		return result
+	}
+
+	switch len(records) {
+	case 0:
+		warn("no MTA-STS record found, SMTP TLS is not enforced")
+		return result
+	case 1:
+		result.Record = records[0]
+	default:
+		fail("%d MTA-STS records found, only one is allowed", len(records))
+		return result
+	}
+
+	result.ID = parseTags(result.Record)["id"]
+	if !mtaSTSID.MatchString(result.ID) {
+		fail("invalid policy id %q", result.ID)
+	}
+
+	result.PolicyURL = c.policyURL(domain)
+	policy, err := c.fetchPolicy(ctx, result.PolicyURL)
+	if err != nil {
+		fail("failed to fetch policy: %v", err)
+		return result
+	}
+	result.Policy = policy
+
+	fields := parsePolicy(policy)
+	if version := first(fields["version"]); version != "STSv1" {
+		fail("invalid policy version %q", version)
+	}
+
+	result.Mode = first(fields["mode"])
+	switch result.Mode {
+	case "enforce":
+	case "testing":
+		warn("policy is in testing mode, failures are only reported")
+	case "none":
+		warn("policy mode is \"none\", MTA-STS is disabled")
+	default:
+		fail("invalid policy mode %q", result.Mode)
+	}
+
+	maxAge, err := strconv.Atoi(first(fields["max_age"]))
+	if err != nil || maxAge < 0 || maxAge > maxPolicyAge {
+		fail("invalid max_age %q", first(fields["max_age"]))
+	} else {
+		result.MaxAge = maxAge
+		if maxAge < 86400 {
+			warn("max_age of %d seconds is shorter than a day", maxAge)
+		}
+	}
+
+	result.MX = fields["mx"]
+	if len(result.MX) == 0 && result.Mode != "none" {
+		fail("policy has no mx entries")
+	}
+
+	if result.Mode == "none" || len(result.MX) == 0 {
+		return result
+	}
+
+	resp, err := c.dnsClient.Query(ctx, domain, "MX")
+	if err != nil {
+		fail("MX lookup failed: %v", err)
+		return result
+	}
+
+	for _, record := range resp.Records {
+		mx, ok := record.Data.(*dnsinfo.MXData)
+		if !ok {
+			continue
+		}
+
+		host := strings.TrimSuffix(strings.ToLower(mx.Exchange), ".")
+		if !policyMatches(result.MX, host) {
+			if result.Mode == "enforce" {
+				fail("MX host %s is not covered by the policy", host)
+			} else {
+				warn("MX host %s is not covered by the policy", host)
+			}
+		}
+	}
+
+	return result
+}
+
+func (c *Client) fetchPolicy(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", "watchr/1.0")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err != nil || mediaType != "text/plain" {
+		return "", fmt.Errorf("unexpected content type %q", resp.Header.Get("Content-Type"))
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPolicySize+1))
+	if err != nil {
+		return "", err
+	}
+	if len(body) > maxPolicySize {
+		return "", fmt.Errorf("policy exceeds %d bytes", maxPolicySize)
+	}
+
+	return string(body), nil
+}
+
+// parsePolicy parses the "key: value" lines of an MTA-STS policy. The mx key
+// may be repeated.
+func parsePolicy(policy string) map[string][]string {
+	fields := make(map[string][]string)
+
+	scanner := bufio.NewScanner(strings.NewReader(policy))
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), ":")
+		if !found {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		fields[key] = append(fields[key], strings.TrimSpace(value))
+	}
+
+	return fields
+}
+
+// policyMatches reports whether host matches one of the policy mx patterns,
+// where a leading "*." matches exactly one label.
+func policyMatches(patterns []string, host string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(strings.ToLower(pattern), ".")
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			label, rest, found := strings.Cut(host, ".")
+			if found && label != "" && rest == suffix {
+				return true
+			}
+			continue
+		}
+		if pattern == host {
+			return true
+		}
+	}
+	return false
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// checkTLSRPT validates the SMTP TLS reporting record of domain (RFC 8460).
+func (c *Client) checkTLSRPT(ctx context.Context, domain string) *TLSRPTResult {
+	result := &TLSRPTResult{Status: StatusPass}
+
+	records, err := c.lookupVersioned(ctx, "_smtp._tls."+domain, "v=TLSRPTv1")
+	if err != nil {
+		result.Status = StatusFail
+		result.Issues = append(result.Issues, err.Error())
+		return result
+	}
+
+	switch len(records) {
+	case 0:
+		result.Status = StatusWarn
+		result.Issues = append(result.Issues, "no TLS-RPT record found, SMTP TLS failures are not reported")
+		return result
+	case 1:
+		result.Record = records[0]
+	default:
+		result.Status = StatusFail
+		result.Issues = append(result.Issues, fmt.Sprintf("%d TLS-RPT records found, only one is allowed", len(records)))
+		return result
+	}
+
+	result.RUA = splitList(parseTags(result.Record)["rua"])
+	if len(result.RUA) == 0 {
+		result.Status = StatusFail
+		result.Issues = append(result.Issues, "missing required \"rua\" tag")
+	}
+
+	for _, uri := range result.RUA {
+		lower := strings.ToLower(uri)
+		if !strings.HasPrefix(lower, "mailto:") && !strings.HasPrefix(lower, "https://") {
+			result.Status = StatusFail
+			result.Issues = append(result.Issues, fmt.Sprintf("report URI %q must use mailto: or https:", uri))
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,172 @@
+package mail
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// servePolicy serves policy as the MTA-STS policy of every domain queried by
+// client.
+func servePolicy(t *testing.T, client *Client, contentType string, policy string) {
+	t.Helper()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.well-known/mta-sts.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		_, _ = w.Write([]byte(policy))
+	}))
+	t.Cleanup(server.Close)
+
+	client.httpClient = server.Client()
+	client.policyURL = func(domain string) string {
+		return server.URL + "/.well-known/mta-sts.txt"
+	}
+}
+
+func TestClient_CheckMTASTS(t *testing.T) {
+	client := newTestClient(t,
+		`_mta-sts.example.com. 300 IN TXT "v=STSv1; id=20240101T000000"`,
+		`example.com. 300 IN MX 10 mx1.mail.example.com.`,
+		`example.com. 300 IN MX 20 backup.example.net.`,
+	)
+	servePolicy(t, client, "text/plain; charset=utf-8", "version: STSv1\r\nmode: enforce\r\nmx: *.mail.example.com\r\nmx: backup.example.net\r\nmax_age: 604800\r\n")
+
+	result := client.checkMTASTS(context.Background(), "example.com")
+
+	if result.Status != StatusPass {
+		t.Errorf("expected pass, got %s: %v", result.Status, result.Issues)
+	}
+
+	if result.ID != "20240101T000000" || result.Mode != "enforce" || result.MaxAge != 604800 {
+		t.Errorf("unexpected policy: %+v", result)
+	}
+
+	if len(result.MX) != 2 {
+		t.Errorf("expected 2 mx entries, got %v", result.MX)
+	}
+}
+
+func TestClient_CheckMTASTS_Issues(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		policy      string
+		status      string
+		issue       string
+	}{
+		{
+			name:        "uncovered mx",
+			contentType: "text/plain",
+			policy:      "version: STSv1\nmode: enforce\nmx: other.example.com\nmax_age: 604800\n",
+			status:      StatusFail,
+			issue:       "MX host mx1.mail.example.com is not covered",
+		},
+		{
+			name:        "testing",
+			contentType: "text/plain",
+			policy:      "version: STSv1\nmode: testing\nmx: *.mail.example.com\nmax_age: 604800\n",
+			status:      StatusWarn,
+			issue:       "testing mode",
+		},
+		{
+			name:        "invalid max age",
+			contentType: "text/plain",
+			policy:      "version: STSv1\nmode: enforce\nmx: *.mail.example.com\nmax_age: 99999999\n",
+			status:      StatusFail,
+			issue:       "invalid max_age",
+		},
+		{
+			name:        "content type",
+			contentType: "text/html",
+			policy:      "version: STSv1\nmode: enforce\nmx: *.mail.example.com\nmax_age: 604800\n",
+			status:      StatusFail,
+			issue:       "unexpected content type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t,
+				`_mta-sts.example.com. 300 IN TXT "v=STSv1; id=1"`,
+				`example.com. 300 IN MX 10 mx1.mail.example.com.`,
+			)
+			servePolicy(t, client, tt.contentType, tt.policy)
+
+			result := client.checkMTASTS(context.Background(), "example.com")
+
+			if result.Status != tt.status {
+				t.Errorf("expected %s, got %s: %v", tt.status, result.Status, result.Issues)
+			}
+
+			if !strings.Contains(strings.Join(result.Issues, "\n"), tt.issue) {
+				t.Errorf("expected an issue containing %q, got %v", tt.issue, result.Issues)
+			}
+		})
+	}
+}
+
+func TestClient_CheckMTASTS_NotDeployed(t *testing.T) {
+	client := newTestClient(t)
+
+	result := client.checkMTASTS(context.Background(), "example.com")
+
+	if result.Status != StatusWarn {
+		t.Errorf("expected warn, got %s", result.Status)
+	}
+}
+
+func TestPolicyMatches(t *testing.T) {
+	patterns := []string{"*.mail.example.com", "mx.example.net."}
+
+	tests := []struct {
+		host     string
+		expected bool
+	}{
+		{"mx1.mail.example.com", true},
+		{"mail.example.com", false},
+		{"a.b.mail.example.com", false},
+		{"mx.example.net", true},
+		{"mx2.example.net", false},
+	}
+
+	for _, tt := range tests {
+		if got := policyMatches(patterns, tt.host); got != tt.expected {
+			t.Errorf("policyMatches(%q) = %t, want %t", tt.host, got, tt.expected)
+		}
+	}
+}
+
+func TestClient_CheckTLSRPT(t *testing.T) {
+	tests := []struct {
+		name   string
+		record string
+		status string
+	}{
+		{"valid", `_smtp._tls.example.com. 300 IN TXT "v=TLSRPTv1; rua=mailto:tlsrpt@example.com,https://reports.example.com/tlsrpt"`, StatusPass},
+		{"missing", "", StatusWarn},
+		{"no rua", `_smtp._tls.example.com. 300 IN TXT "v=TLSRPTv1;"`, StatusFail},
+		{"invalid rua", `_smtp._tls.example.com. 300 IN TXT "v=TLSRPTv1; rua=http://example.com"`, StatusFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := []string{}
+			if tt.record != "" {
+				records = append(records, tt.record)
+			}
+			client := newTestClient(t, records...)
+
+			result := client.checkTLSRPT(context.Background(), "example.com")
+
+			if result.Status != tt.status {
+				t.Errorf("expected %s, got %s: %v", tt.status, result.Status, result.Issues)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package mail
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	mdns "github.com/miekg/dns"
+
+	dnsinfo "watchr/internal/dns"
+)
+
+// newTestClient runs a local DNS server answering from the given records and
+// returns a client using it.
+func newTestClient(t *testing.T, records ...string) *Client {
+	t.Helper()
+
+	rrs := make([]mdns.RR, 0, len(records))
+	for _, record := range records {
+		rr, err := mdns.NewRR(record)
+		if err != nil {
+			t.Fatalf("failed to parse %q: %v", record, err)
+		}
+		rrs = append(rrs, rr)
+	}
+
+	handler := func(w mdns.ResponseWriter, r *mdns.Msg) {
+		m := new(mdns.Msg)
+		m.SetReply(r)
+
+		q := r.Question[0]
+		for _, rr := range rrs {
+			header := rr.Header()
+			if mdns.CanonicalName(header.Name) == mdns.CanonicalName(q.Name) && header.Rrtype == q.Qtype {
+				m.Answer = append(m.Answer, rr)
+			}
+		}
+
+		_ = w.WriteMsg(m)
+	}
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen on UDP: %v", err)
+	}
+
+	server := &mdns.Server{PacketConn: pc, Handler: mdns.HandlerFunc(handler)}
+	go func() {
+		_ = server.ActivateAndServe()
+	}()
+	t.Cleanup(func() {
+		_ = server.Shutdown()
+	})
+
+	return NewClient(5*time.Second, dnsinfo.NewClient(5*time.Second, pc.LocalAddr().String()))
+}
@@ -0,0 +1,191 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// maxSPFLookups is the limit on DNS lookups when evaluating an SPF record, as
+// defined in RFC 7208 section 4.6.4.
+const maxSPFLookups = 10
+
+// checkSPF validates the SPF record of domain, expanding its includes and
+// redirects and counting the DNS lookups they need.
+func (c *Client) checkSPF(ctx context.Context, domain string) *SPFResult {
+	result := c.evaluateSPF(ctx, domain, map[string]bool{domain: true})
+
+	if result.Record == "" {
+		return &result
+	}
+
+	if result.Lookups > maxSPFLookups {
+		result.Status = StatusFail
+		result.Issues = append(result.Issues, fmt.Sprintf("too many DNS lookups: %d (limit %d)", result.Lookups, maxSPFLookups))
+	}
+
+	all, redirect := "", false
+	for _, term := range result.Terms {
+		switch term.Name {
+		case "all":
+			all = term.Qualifier
+		case "redirect":
+			redirect = true
+		}
+	}
+
+	switch {
+	case all == "" && !redirect:
+		result.Status = worst(result.Status, StatusWarn)
+		result.Issues = append(result.Issues, "no \"all\" mechanism, unmatched senders get a neutral result")
+	case all == "+":
+		result.Status = StatusFail
+		result.Issues = append(result.Issues, "\"+all\" allows any host to send mail for the domain")
+	case all == "?":
+		result.Status = worst(result.Status, StatusWarn)
+		result.Issues = append(result.Issues, "\"?all\" gives unmatched senders a neutral result")
+	}
+
+	return &result
+}
+
+// evaluateSPF parses the SPF record of domain and, recursively, the records
+// of its includes and redirect. visited holds the domains already being
+// evaluated, to detect loops.
+func (c *Client) evaluateSPF(ctx context.Context, domain string, visited map[string]bool) SPFResult {
+	result := SPFResult{
+		Status: StatusPass,
+		Domain: domain,
+	}
+
+	records, err := c.lookupTXT(ctx, domain)
+	if err != nil {
+		result.Status = StatusFail
+		result.Issues = append(result.Issues, err.Error())
+		return result
+	}
+
+	spf := make([]string, 0, 1)
+	for _, record := range records {
+		if version, _, _ := strings.Cut(record, " "); strings.EqualFold(version, "v=spf1") {
+			spf = append(spf, record)
+		}
+	}
+
+	switch len(spf) {
+	case 0:
+		result.Status = StatusFail
+		result.Issues = append(result.Issues, "no SPF record found")
+		return result
+	case 1:
+		result.Record = spf[0]
+	default:
+		result.Status = StatusFail
+		result.Issues = append(result.Issues, fmt.Sprintf("%d SPF records found, only one is allowed", len(spf)))
+		return result
+	}
+
+	for _, field := range strings.Fields(result.Record)[1:] {
+		term := parseSPFTerm(field)
+		result.Terms = append(result.Terms, term)
+
+		switch term.Name {
+		case "include", "redirect":
+			result.Lookups++
+			if term.Value == "" {
+				result.Status = StatusFail
+				result.Issues = append(result.Issues, fmt.Sprintf("%q is missing a domain", field))
+				continue
+			}
+
+			target := strings.TrimSuffix(strings.ToLower(term.Value), ".")
+			if strings.Contains(target, "%") {
+				// Macros are expanded when evaluating a message.
+				continue
+			}
+			if visited[target] {
+				result.Status = StatusFail
+				result.Issues = append(result.Issues, fmt.Sprintf("%q creates a loop", field))
+				continue
+			}
+
+			visited[target] = true
+			nested := c.evaluateSPF(ctx, target, visited)
+			delete(visited, target)
+
+			result.Lookups += nested.Lookups
+			result.Includes = append(result.Includes, nested)
+			if nested.Status == StatusFail {
+				result.Status = StatusFail
+				result.Issues = append(result.Issues, fmt.Sprintf("%q is invalid", field))
+			}
+		case "a", "mx", "exists":
+			result.Lookups++
+		case "ptr":
+			result.Lookups++
+			result.Status = worst(result.Status, StatusWarn)
+			result.Issues = append(result.Issues, "the \"ptr\" mechanism is deprecated (RFC 7208 section 5.5)")
+		case "ip4", "ip6":
+			if !validSPFNetwork(term) {
+				result.Status = StatusFail
+				result.Issues = append(result.Issues, fmt.Sprintf("%q is not a valid network", field))
+			}
+		case "all", "exp":
+		default:
+			if !strings.Contains(field, "=") {
+				result.Status = StatusFail
+				result.Issues = append(result.Issues, fmt.Sprintf("unknown mechanism %q", field))
+			}
+		}
+	}
+
+	return result
+}
+
+// parseSPFTerm splits a mechanism into its qualifier, name and value, or a
+// modifier into its name and value.
+func parseSPFTerm(field string) SPFTerm {
+	if name, value, found := strings.Cut(field, "="); found && !strings.ContainsAny(name, ":/") {
+		return SPFTerm{Name: strings.ToLower(name), Value: value}
+	}
+
+	term := SPFTerm{}
+	if strings.ContainsAny(field[:1], "+-~?") {
+		term.Qualifier = field[:1]
+		field = field[1:]
+	}
+
+	name, value := field, ""
+	if i := strings.IndexAny(field, ":/"); i >= 0 {
+		name = field[:i]
+		value = strings.TrimPrefix(field[i:], ":")
+	}
+	term.Name = strings.ToLower(name)
+	term.Value = value
+
+	if term.Qualifier == "" && term.Name == "all" {
+		term.Qualifier = "+"
+	}
+
+	return term
+}
+
+func validSPFNetwork(term SPFTerm) bool {
+	address, _, _ := strings.Cut(term.Value, "/")
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+
+	if strings.Contains(term.Value, "/") {
+		if _, _, err := net.ParseCIDR(term.Value); err != nil {
+			return false
+		}
+	}
+
+	if term.Name == "ip4" {
+		return ip.To4() != nil
+	}
+	return ip.To4() == nil
+}
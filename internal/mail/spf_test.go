@@ -0,0 +1,176 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestClient_CheckSPF(t *testing.T) {
+	client := newTestClient(t,
+		`example.com. 300 IN TXT "v=spf1 ip4:192.0.2.0/24 include:_spf.example.net " "mx -all"`,
+		`example.com. 300 IN TXT "google-site-verification=abc"`,
+		`_spf.example.net. 300 IN TXT "v=spf1 a include:_spf2.example.net ~all"`,
+		`_spf2.example.net. 300 IN TXT "v=spf1 ip6:2001:db8::/32 ~all"`,
+	)
+
+	result := client.checkSPF(context.Background(), "example.com")
+
+	if result.Status != StatusPass {
+		t.Errorf("expected pass, got %s: %v", result.Status, result.Issues)
+	}
+
+	if result.Record != "v=spf1 ip4:192.0.2.0/24 include:_spf.example.net mx -all" {
+		t.Errorf("expected the record strings to be concatenated, got %q", result.Record)
+	}
+
+	// include:_spf.example.net, mx, a and include:_spf2.example.net
+	if result.Lookups != 4 {
+		t.Errorf("expected 4 lookups, got %d", result.Lookups)
+	}
+
+	if len(result.Includes) != 1 || len(result.Includes[0].Includes) != 1 {
+		t.Errorf("expected nested includes, got %+v", result.Includes)
+	}
+}
+
+func TestClient_CheckSPF_TooManyLookups(t *testing.T) {
+	records := []string{`example.com. 300 IN TXT "v=spf1 include:a.example.net include:b.example.net -all"`}
+	for _, name := range []string{"a", "b"} {
+		records = append(records, fmt.Sprintf(`%s.example.net. 300 IN TXT "v=spf1 a mx exists:x.example.net a:one.example.net mx:two.example.net -all"`, name))
+	}
+
+	client := newTestClient(t, records...)
+
+	result := client.checkSPF(context.Background(), "example.com")
+
+	if result.Lookups != 12 {
+		t.Errorf("expected 12 lookups, got %d", result.Lookups)
+	}
+
+	if result.Status != StatusFail || !strings.Contains(strings.Join(result.Issues, "\n"), "too many DNS lookups: 12") {
+		t.Errorf("expected the lookup limit to fail, got %s: %v", result.Status, result.Issues)
+	}
+}
+
+func TestClient_CheckSPF_Failures(t *testing.T) {
+	tests := []struct {
+		name    string
+		records []string
+		status  string
+		issue   string
+	}{
+		{
+			name:   "missing",
+			status: StatusFail,
+			issue:  "no SPF record found",
+		},
+		{
+			name: "multiple",
+			records: []string{
+				`example.com. 300 IN TXT "v=spf1 -all"`,
+				`example.com. 300 IN TXT "v=spf1 mx -all"`,
+			},
+			status: StatusFail,
+			issue:  "2 SPF records found",
+		},
+		{
+			name:    "pass all",
+			records: []string{`example.com. 300 IN TXT "v=spf1 mx +all"`},
+			status:  StatusFail,
+			issue:   "\"+all\" allows any host",
+		},
+		{
+			name:    "neutral all",
+			records: []string{`example.com. 300 IN TXT "v=spf1 mx ?all"`},
+			status:  StatusWarn,
+			issue:   "\"?all\"",
+		},
+		{
+			name:    "no all",
+			records: []string{`example.com. 300 IN TXT "v=spf1 mx"`},
+			status:  StatusWarn,
+			issue:   "no \"all\" mechanism",
+		},
+		{
+			name:    "ptr",
+			records: []string{`example.com. 300 IN TXT "v=spf1 ptr -all"`},
+			status:  StatusWarn,
+			issue:   "deprecated",
+		},
+		{
+			name:    "invalid network",
+			records: []string{`example.com. 300 IN TXT "v=spf1 ip4:2001:db8::1 -all"`},
+			status:  StatusFail,
+			issue:   "not a valid network",
+		},
+		{
+			name:    "unknown mechanism",
+			records: []string{`example.com. 300 IN TXT "v=spf1 foo -all"`},
+			status:  StatusFail,
+			issue:   "unknown mechanism",
+		},
+		{
+			name:    "missing include",
+			records: []string{`example.com. 300 IN TXT "v=spf1 include:missing.example.net -all"`},
+			status:  StatusFail,
+			issue:   "\"include:missing.example.net\" is invalid",
+		},
+		{
+			name: "loop",
+			records: []string{
+				`example.com. 300 IN TXT "v=spf1 include:loop.example.net -all"`,
+				`loop.example.net. 300 IN TXT "v=spf1 include:example.com -all"`,
+			},
+			status: StatusFail,
+			issue:  "creates a loop",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, tt.records...)
+
+			result := client.checkSPF(context.Background(), "example.com")
+
+			if result.Status != tt.status {
+				t.Errorf("expected %s, got %s: %v", tt.status, result.Status, result.Issues)
+			}
+
+			if !strings.Contains(strings.Join(result.Issues, "\n")+"\n"+issuesOf(result.Includes), tt.issue) {
+				t.Errorf("expected an issue containing %q, got %v", tt.issue, result.Issues)
+			}
+		})
+	}
+}
+
+func issuesOf(results []SPFResult) string {
+	issues := make([]string, 0)
+	for _, result := range results {
+		issues = append(issues, result.Issues...)
+		issues = append(issues, issuesOf(result.Includes))
+	}
+	return strings.Join(issues, "\n")
+}
+
+func TestParseSPFTerm(t *testing.T) {
+	tests := []struct {
+		field    string
+		expected SPFTerm
+	}{
+		{"-all", SPFTerm{Qualifier: "-", Name: "all"}},
+		{"all", SPFTerm{Qualifier: "+", Name: "all"}},
+		{"include:_spf.google.com", SPFTerm{Name: "include", Value: "_spf.google.com"}},
+		{"~ip4:192.0.2.0/24", SPFTerm{Qualifier: "~", Name: "ip4", Value: "192.0.2.0/24"}},
+		{"a/24", SPFTerm{Name: "a", Value: "/24"}},
+		{"redirect=_spf.example.com", SPFTerm{Name: "redirect", Value: "_spf.example.com"}},
+		{"MX", SPFTerm{Name: "mx"}},
+	}
+
+	for _, tt := range tests {
+		if term := parseSPFTerm(tt.field); term != tt.expected {
+			t.Errorf("parseSPFTerm(%q) = %+v, want %+v", tt.field, term, tt.expected)
+		}
+	}
+}
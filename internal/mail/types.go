@@ -0,0 +1,101 @@
+package mail
+
+import (
+	"time"
+
+	"watchr/internal/idn"
+)
+
+// Check statuses, from best to worst.
+const (
+	StatusPass = "pass"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+)
+
+type Response struct {
+	Domain    string        `json:"domain"`
+	IDN       *idn.Name     `json:"idn,omitempty"`
+	QueryTime time.Duration `json:"queryTime"`
+	// Status is the worst status of all the checks.
+	Status string        `json:"status"`
+	SPF    *SPFResult    `json:"spf"`
+	DMARC  *DMARCResult  `json:"dmarc"`
+	DKIM   *DKIMResult   `json:"dkim"`
+	MTASTS *MTASTSResult `json:"mtaSts"`
+	TLSRPT *TLSRPTResult `json:"tlsRpt"`
+	BIMI   *BIMIResult   `json:"bimi"`
+}
+
+type SPFResult struct {
+	Status   string      `json:"status"`
+	Domain   string      `json:"domain"`
+	Record   string      `json:"record,omitempty"`
+	Terms    []SPFTerm   `json:"terms,omitempty"`
+	Includes []SPFResult `json:"includes,omitempty"`
+	// Lookups is the number of DNS lookups needed to evaluate the record,
+	// including the ones of nested includes and redirects.
+	Lookups int      `json:"lookups"`
+	Issues  []string `json:"issues,omitempty"`
+}
+
+type SPFTerm struct {
+	Qualifier string `json:"qualifier,omitempty"`
+	Name      string `json:"name"`
+	Value     string `json:"value,omitempty"`
+}
+
+type DMARCResult struct {
+	Status          string   `json:"status"`
+	Record          string   `json:"record,omitempty"`
+	Policy          string   `json:"policy,omitempty"`
+	SubdomainPolicy string   `json:"subdomainPolicy,omitempty"`
+	Percent         int      `json:"percent"`
+	ADKIM           string   `json:"adkim,omitempty"`
+	ASPF            string   `json:"aspf,omitempty"`
+	RUA             []string `json:"rua,omitempty"`
+	RUF             []string `json:"ruf,omitempty"`
+	Issues          []string `json:"issues,omitempty"`
+}
+
+type DKIMResult struct {
+	Status    string         `json:"status"`
+	Selectors []DKIMSelector `json:"selectors"`
+	Issues    []string       `json:"issues,omitempty"`
+}
+
+type DKIMSelector struct {
+	Selector string   `json:"selector"`
+	Status   string   `json:"status"`
+	Record   string   `json:"record,omitempty"`
+	KeyType  string   `json:"keyType,omitempty"`
+	KeyBits  int      `json:"keyBits,omitempty"`
+	Issues   []string `json:"issues,omitempty"`
+}
+
+type MTASTSResult struct {
+	Status    string   `json:"status"`
+	Record    string   `json:"record,omitempty"`
+	ID        string   `json:"id,omitempty"`
+	PolicyURL string   `json:"policyUrl,omitempty"`
+	Policy    string   `json:"policy,omitempty"`
+	Mode      string   `json:"mode,omitempty"`
+	MX        []string `json:"mx,omitempty"`
+	MaxAge    int      `json:"maxAge,omitempty"`
+	Issues    []string `json:"issues,omitempty"`
+}
+
+type TLSRPTResult struct {
+	Status string   `json:"status"`
+	Record string   `json:"record,omitempty"`
+	RUA    []string `json:"rua,omitempty"`
+	Issues []string `json:"issues,omitempty"`
+}
+
+type BIMIResult struct {
+	Status      string   `json:"status"`
+	Record      string   `json:"record,omitempty"`
+	Logo        string   `json:"logo,omitempty"`
+	Certificate string   `json:"certificate,omitempty"`
+	Issues      []string `json:"issues,omitempty"`
+}
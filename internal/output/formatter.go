@@ -9,10 +9,16 @@ import (
 
 	"github.com/likexian/whois-parser"
 
+	"watchr/internal/caa"
+	"watchr/internal/dane"
 	dnsinfo "watchr/internal/dns"
 	httpinfo "watchr/internal/http"
+	"watchr/internal/idn"
+	mailinfo "watchr/internal/mail"
 	"watchr/internal/rdap"
+	"watchr/internal/takeover"
 	tlsinfo "watchr/internal/tls"
+	"watchr/internal/whois"
 )
 
 type Formatter struct {
@@ -41,22 +47,23 @@ func (f *Formatter) OutputRDAP(resp *rdap.Response) error {
 	}
 }
 
-func (f *Formatter) OutputWHOIS(data string) error {
-	parsed, err := whoisparser.Parse(data)
+func (f *Formatter) OutputWHOIS(resp *whois.Response) error {
+	parsed, err := whoisparser.Parse(resp.Raw)
 	if err != nil || parsed.Domain == nil {
-		return f.outputWHOISUnparsed(data)
+		return f.outputWHOISUnparsed(resp)
 	}
 
 	if f.format == "json" {
 		payload := map[string]interface{}{
 			"source": "WHOIS",
-			"raw":    data,
+			"idn":    resp.IDN,
+			"raw":    resp.Raw,
 			"parsed": parsed,
 		}
 		return f.outputJSON(payload)
 	}
 
-	return f.outputWHOISTextParsed(parsed, data)
+	return f.outputWHOISTextParsed(parsed, resp)
 }
 
 func (f *Formatter) outputJSON(data interface{}) error {
@@ -69,6 +76,9 @@ func (f *Formatter) outputRDAPText(resp *rdap.Response) error {
 	if err := writeLine(f.writer, "Domain: %s\n", resp.LDHName); err != nil {
 		return err
 	}
+	if err := writeIDN(f.writer, resp.IDN); err != nil {
+		return err
+	}
 	if err := writeLine(f.writer, "Handle: %s\n", resp.Handle); err != nil {
 		return err
 	}
@@ -104,29 +114,45 @@ func (f *Formatter) outputRDAPText(resp *rdap.Response) error {
 	return writeLine(f.writer, "\nSource: RDAP\n")
 }
 
-func (f *Formatter) outputWHOISTextRaw(data string) error {
-	if err := writeLine(f.writer, "%s\n", data); err != nil {
+func (f *Formatter) outputWHOISTextRaw(resp *whois.Response) error {
+	if resp.IDN != nil {
+		if err := writeLine(f.writer, "Domain: %s\n", resp.IDN.ASCII); err != nil {
+			return err
+		}
+		if err := writeIDN(f.writer, resp.IDN); err != nil {
+			return err
+		}
+		if err := writeLine(f.writer, "\n"); err != nil {
+			return err
+		}
+	}
+	if err := writeLine(f.writer, "%s\n", resp.Raw); err != nil {
 		return err
 	}
 	return writeLine(f.writer, "\nSource: WHOIS\n")
 }
 
-func (f *Formatter) outputWHOISUnparsed(data string) error {
+func (f *Formatter) outputWHOISUnparsed(resp *whois.Response) error {
 	if f.format == "json" {
-		payload := map[string]string{
+		payload := map[string]interface{}{
 			"source": "WHOIS",
-			"data":   data,
+			"idn":    resp.IDN,
+			"data":   resp.Raw,
 		}
 		return f.outputJSON(payload)
 	}
-	return f.outputWHOISTextRaw(data)
+	return f.outputWHOISTextRaw(resp)
 }
 
-func (f *Formatter) outputWHOISTextParsed(info whoisparser.WhoisInfo, raw string) error {
+func (f *Formatter) outputWHOISTextParsed(info whoisparser.WhoisInfo, resp *whois.Response) error {
+	raw := resp.Raw
 	if info.Domain != nil {
 		if err := writeLine(f.writer, "Domain: %s\n", info.Domain.Domain); err != nil {
 			return err
 		}
+		if err := writeIDN(f.writer, resp.IDN); err != nil {
+			return err
+		}
 		if info.Domain.WhoisServer != "" {
 			if err := writeLine(f.writer, "WHOIS Server: %s\n", info.Domain.WhoisServer); err != nil {
 				return err
@@ -368,6 +394,9 @@ func (f *Formatter) outputTLSText(resp *tlsinfo.Response) error {
 	if err := writeLine(f.writer, "Host: %s:%s\n", resp.Host, resp.Port); err != nil {
 		return err
 	}
+	if err := writeIDN(f.writer, resp.IDN); err != nil {
+		return err
+	}
 	if err := writeLine(f.writer, "TLS Version: %s\n", resp.TLSVersion); err != nil {
 		return err
 	}
@@ -461,6 +490,152 @@ func (f *Formatter) outputTLSText(resp *tlsinfo.Response) error {
 	return nil
 }
 
+func (f *Formatter) OutputCAA(resp *caa.Response) error {
+	switch f.format {
+	case "json":
+		return f.outputJSON(resp)
+	default:
+		return f.outputCAAText(resp)
+	}
+}
+
+func (f *Formatter) outputCAAText(resp *caa.Response) error {
+	if err := writeLine(f.writer, "Host: %s:%s\n", resp.Host, resp.Port); err != nil {
+		return err
+	}
+	if err := writeIDN(f.writer, resp.IDN); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Issuer: %s\n", resp.Issuer); err != nil {
+		return err
+	}
+	issuerDomains := "unknown"
+	if len(resp.IssuerDomains) > 0 {
+		issuerDomains = strings.Join(resp.IssuerDomains, ", ")
+	}
+	if err := writeLine(f.writer, "Issuer Domains: %s\n", issuerDomains); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Status: %s\n", resp.Status); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Query Time: %v\n", resp.QueryTime); err != nil {
+		return err
+	}
+
+	if err := writeLine(f.writer, "\nNames (%d):\n", len(resp.Names)); err != nil {
+		return err
+	}
+	for _, name := range resp.Names {
+		if err := writeLine(f.writer, "  %-12s  %s\n", "["+name.Status+"]", name.Name); err != nil {
+			return err
+		}
+		if err := writeLine(f.writer, "    %s\n", name.Reason); err != nil {
+			return err
+		}
+		for _, property := range name.Properties {
+			if err := writeLine(f.writer, "    CAA %d %s %q\n", caaFlag(property.Critical), property.Tag, property.Value); err != nil {
+				return err
+			}
+		}
+		if name.Error != "" {
+			if err := writeLine(f.writer, "    error: %s\n", name.Error); err != nil {
+				return err
+			}
+		}
+	}
+
+	if resp.Status == caa.StatusDenied {
+		return writeLine(f.writer, "\nWARNING: renewing the certificate with the same CA will fail until the CAA records authorize it\n")
+	}
+
+	return nil
+}
+
+func caaFlag(critical bool) int {
+	if critical {
+		return 128
+	}
+	return 0
+}
+
+func (f *Formatter) OutputDANE(resp *dane.Response) error {
+	switch f.format {
+	case "json":
+		return f.outputJSON(resp)
+	default:
+		return f.outputDANEText(resp)
+	}
+}
+
+func (f *Formatter) outputDANEText(resp *dane.Response) error {
+	if err := writeLine(f.writer, "Host: %s:%s\n", resp.Host, resp.Port); err != nil {
+		return err
+	}
+	if err := writeIDN(f.writer, resp.IDN); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "TLSA Name: %s\n", resp.TLSAName); err != nil {
+		return err
+	}
+	dnssec := resp.DNSSEC
+	if resp.DNSSECReason != "" {
+		dnssec += " (" + resp.DNSSECReason + ")"
+	}
+	if err := writeLine(f.writer, "DNSSEC: %s\n", dnssec); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Status: %s\n", resp.Status); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Query Time: %v\n", resp.QueryTime); err != nil {
+		return err
+	}
+
+	if err := writeLine(f.writer, "\nChain (%d):\n", len(resp.Chain)); err != nil {
+		return err
+	}
+	for i, cert := range resp.Chain {
+		if err := writeLine(f.writer, "  [%d] %s\n", i, cert.Subject); err != nil {
+			return err
+		}
+		if err := writeLine(f.writer, "      Issuer: %s\n", cert.Issuer); err != nil {
+			return err
+		}
+		if err := writeLine(f.writer, "      SHA-256: %s\n", cert.SHA256); err != nil {
+			return err
+		}
+		if err := writeLine(f.writer, "      SPKI SHA-256: %s\n", cert.SPKISHA256); err != nil {
+			return err
+		}
+	}
+
+	if err := writeLine(f.writer, "\nTLSA Records (%d):\n", len(resp.Records)); err != nil {
+		return err
+	}
+	for _, record := range resp.Records {
+		status := "[no match]"
+		if record.Matched {
+			status = "[match]"
+		}
+		if err := writeLine(f.writer, "  %-10s  %d %d %d %s\n", status, record.Usage, record.Selector, record.MatchingType, record.Data); err != nil {
+			return err
+		}
+		if err := writeLine(f.writer, "      %s: %s\n", record.Description, record.Reason); err != nil {
+			return err
+		}
+	}
+
+	switch resp.Status {
+	case dane.StatusUnusable:
+		return writeLine(f.writer, "\nWARNING: the TLSA records are not DNSSEC-secure, DANE clients ignore them\n")
+	case dane.StatusNoMatch:
+		return writeLine(f.writer, "\nWARNING: no TLSA record matches the served chain, DANE clients will refuse to connect\n")
+	}
+
+	return nil
+}
+
 func (f *Formatter) OutputTLSScan(result *tlsinfo.TestResult) error {
 	switch f.format {
 	case "json":
@@ -548,22 +723,108 @@ func (f *Formatter) outputDNSText(resp *dnsinfo.Response) error {
 	if err := writeLine(f.writer, "Domain: %s\n", resp.Domain); err != nil {
 		return err
 	}
+	if err := writeIDN(f.writer, resp.IDN); err != nil {
+		return err
+	}
 	if err := writeLine(f.writer, "Record Type: %s\n", resp.RecordType); err != nil {
 		return err
 	}
 	if err := writeLine(f.writer, "Nameserver: %s\n", resp.Nameserver); err != nil {
 		return err
 	}
+	if resp.Transport != "" {
+		if err := writeLine(f.writer, "Transport: %s\n", resp.Transport); err != nil {
+			return err
+		}
+	}
 	if err := writeLine(f.writer, "Query Time: %v\n", resp.QueryTime); err != nil {
 		return err
 	}
+	if len(resp.Searched) > 1 {
+		if err := writeLine(f.writer, "Searched: %s\n", strings.Join(resp.Searched, ", ")); err != nil {
+			return err
+		}
+	}
+	if len(resp.Attempts) > 1 {
+		if err := writeLine(f.writer, "Attempts (%d):\n", len(resp.Attempts)); err != nil {
+			return err
+		}
+		for _, attempt := range resp.Attempts {
+			if attempt.Error != "" {
+				if err := writeLine(f.writer, "  %s  error: %s\n", attempt.Server, attempt.Error); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := writeLine(f.writer, "  %s  %s  %v\n", attempt.Server, attempt.Rcode, attempt.QueryTime); err != nil {
+				return err
+			}
+		}
+	}
+	if resp.Rcode != "" {
+		if err := writeLine(f.writer, "Status: %s\n", resp.Rcode); err != nil {
+			return err
+		}
+		if err := writeLine(f.writer, "Flags: %s\n", dnsFlagsString(resp.Flags)); err != nil {
+			return err
+		}
+	}
+
+	if resp.EDNS != nil {
+		dnssecOK := ""
+		if resp.EDNS.DNSSECOK {
+			dnssecOK = ", do"
+		}
+		if err := writeLine(f.writer, "EDNS: version %d, udp %d, extended rcode %d%s\n", resp.EDNS.Version, resp.EDNS.UDPSize, resp.EDNS.ExtendedRcode, dnssecOK); err != nil {
+			return err
+		}
+		for _, option := range resp.EDNS.Options {
+			if err := writeLine(f.writer, "  %s: %s\n", option.Name, option.Value); err != nil {
+				return err
+			}
+		}
+	}
+
+	if resp.HTTP != nil {
+		if err := writeLine(f.writer, "\nHTTP Information:\n"); err != nil {
+			return err
+		}
+		if err := writeLine(f.writer, "  Method: %s\n", resp.HTTP.Method); err != nil {
+			return err
+		}
+		if err := writeLine(f.writer, "  Status: %s\n", resp.HTTP.Status); err != nil {
+			return err
+		}
+		if err := writeLine(f.writer, "  Protocol: %s\n", resp.HTTP.Proto); err != nil {
+			return err
+		}
+		if err := writeLine(f.writer, "  Content Type: %s\n", resp.HTTP.ContentType); err != nil {
+			return err
+		}
+	}
+
+	if resp.TLS != nil {
+		if err := writeDNSTLSInfo(f.writer, resp.TLS); err != nil {
+			return err
+		}
+	}
+
+	if resp.Chain != nil {
+		if err := writeCNAMEChain(f.writer, resp.Chain); err != nil {
+			return err
+		}
+	}
 
 	if len(resp.Records) > 0 {
 		if err := writeLine(f.writer, "\nRecords (%d):\n", len(resp.Records)); err != nil {
 			return err
 		}
 		for _, record := range resp.Records {
-			if err := writeLine(f.writer, "  %-6s  %-40s  TTL: %d\n", record.Type, record.Value, record.TTL); err != nil {
+			wildcard := ""
+			if record.Wildcard {
+				wildcard = "  (wildcard)"
+			}
+			if err := writeLine(f.writer, "  %-6s  %-40s  TTL: %d%s\n", record.Type, record.Value, record.TTL, wildcard); err != nil {
 				return err
 			}
 		}
@@ -573,58 +834,106 @@ func (f *Formatter) outputDNSText(resp *dnsinfo.Response) error {
 		}
 	}
 
-	return nil
+	if resp.Wildcard != nil {
+		if err := writeWildcard(f.writer, resp.Wildcard, resp.Records); err != nil {
+			return err
+		}
+	}
+
+	if err := writeDNSSection(f.writer, "Authority Section", resp.Authority); err != nil {
+		return err
+	}
+
+	return writeDNSSection(f.writer, "Additional Section", resp.Additional)
 }
 
-func contactDisplayName(contact *whoisparser.Contact) string {
-	if contact == nil {
-		return ""
+// writeIDN prints the Unicode form of an internationalized name, and the
+// warnings about its labels.
+func writeIDN(writer io.Writer, name *idn.Name) error {
+	if name == nil {
+		return nil
 	}
 
-	if contact.Organization != "" {
-		return contact.Organization
+	if name.IsIDN() {
+		if err := writeLine(writer, "Unicode: %s\n", name.Unicode); err != nil {
+			return err
+		}
+	}
+	for _, warning := range name.Warnings {
+		if err := writeLine(writer, "WARNING: %s\n", warning); err != nil {
+			return err
+		}
 	}
 
-	return contact.Name
+	return nil
 }
 
-func writeContactSection(writer io.Writer, title string, contact *whoisparser.Contact) error {
-	if contact == nil {
+func writeCNAMEChain(writer io.Writer, chain *dnsinfo.CNAMEChain) error {
+	if err := writeLine(writer, "\nCNAME Chain (%d hops): %s\n", len(chain.Hops), chain.Status); err != nil {
+		return err
+	}
+	for _, hop := range chain.Hops {
+		if err := writeLine(writer, "  %s -> %s  TTL: %d\n", hop.Name, hop.Target, hop.TTL); err != nil {
+			return err
+		}
+	}
+
+	values := make([]string, 0, len(chain.Records))
+	for _, record := range chain.Records {
+		values = append(values, record.Value)
+	}
+	if err := writeLine(writer, "  Target: %s (%s)  %s\n", chain.Target, chain.Rcode, strings.Join(values, ", ")); err != nil {
+		return err
+	}
+
+	switch chain.Status {
+	case dnsinfo.ChainDangling:
+		return writeLine(writer, "  WARNING: %s; a dangling CNAME is a subdomain takeover risk\n", chain.Error)
+	case dnsinfo.ChainOK:
 		return nil
+	default:
+		return writeLine(writer, "  WARNING: %s\n", chain.Error)
 	}
+}
 
-	lines := make([]string, 0)
+func writeWildcard(writer io.Writer, wildcard *dnsinfo.Wildcard, records []dnsinfo.Record) error {
+	if !wildcard.Detected {
+		return writeLine(writer, "\nWildcard: none under %s\n", wildcard.Zone)
+	}
 
-	if contact.Name != "" {
-		lines = append(lines, fmt.Sprintf("Name: %s", contact.Name))
+	values := make([]string, 0, len(wildcard.Records))
+	for _, record := range wildcard.Records {
+		values = append(values, record.Type+" "+record.Value)
 	}
-	if contact.Organization != "" {
-		lines = append(lines, fmt.Sprintf("Organization: %s", contact.Organization))
+	if len(values) == 0 {
+		values = append(values, "no records of this type")
 	}
-	if contact.Email != "" {
-		lines = append(lines, fmt.Sprintf("Email: %s", contact.Email))
+	if err := writeLine(writer, "\nWildcard: *.%s answers with %s\n", wildcard.Zone, strings.Join(values, ", ")); err != nil {
+		return err
 	}
-	location := strings.TrimSpace(strings.Join(filterEmpty([]string{
-		contact.City,
-		contact.Province,
-		contact.Country,
-	}), ", "))
-	if location != "" {
-		lines = append(lines, fmt.Sprintf("Location: %s", location))
+
+	matched := 0
+	for _, record := range records {
+		if record.Wildcard {
+			matched++
+		}
 	}
-	if contact.Phone != "" {
-		lines = append(lines, fmt.Sprintf("Phone: %s", contact.Phone))
+	if matched == 0 {
+		return nil
 	}
+	return writeLine(writer, "  WARNING: %d of %d records match the wildcard answer and may not exist on their own\n", matched, len(records))
+}
 
-	if len(lines) == 0 {
+func writeDNSSection(writer io.Writer, title string, records []dnsinfo.Record) error {
+	if len(records) == 0 {
 		return nil
 	}
 
-	if err := writeLine(writer, "\n%s:\n", title); err != nil {
+	if err := writeLine(writer, "\n%s (%d):\n", title, len(records)); err != nil {
 		return err
 	}
-	for _, line := range lines {
-		if err := writeLine(writer, "  %s\n", line); err != nil {
+	for _, record := range records {
+		if err := writeLine(writer, "  %-30s  %-6s  %-40s  TTL: %d\n", record.Name, record.Type, record.Value, record.TTL); err != nil {
 			return err
 		}
 	}
@@ -632,12 +941,1158 @@ func writeContactSection(writer io.Writer, title string, contact *whoisparser.Co
 	return nil
 }
 
-func filterEmpty(values []string) []string {
-	result := make([]string, 0, len(values))
-	for _, v := range values {
-		if strings.TrimSpace(v) != "" {
-			result = append(result, v)
-		}
+func dnsFlagsString(flags dnsinfo.Flags) string {
+	names := make([]string, 0, 7)
+	if flags.Response {
+		names = append(names, "qr")
 	}
-	return result
+	if flags.Authoritative {
+		names = append(names, "aa")
+	}
+	if flags.Truncated {
+		names = append(names, "tc")
+	}
+	if flags.RecursionDesired {
+		names = append(names, "rd")
+	}
+	if flags.RecursionAvailable {
+		names = append(names, "ra")
+	}
+	if flags.AuthenticatedData {
+		names = append(names, "ad")
+	}
+	if flags.CheckingDisabled {
+		names = append(names, "cd")
+	}
+
+	if len(names) == 0 {
+		return "none"
+	}
+
+	return strings.Join(names, " ")
+}
+
+func (f *Formatter) OutputDNSMulti(resp *dnsinfo.MultiResponse) error {
+	switch f.format {
+	case "json":
+		return f.outputJSON(resp)
+	default:
+		return f.outputDNSMultiText(resp)
+	}
+}
+
+func (f *Formatter) outputDNSMultiText(resp *dnsinfo.MultiResponse) error {
+	if err := writeLine(f.writer, "Domain: %s\n", resp.Domain); err != nil {
+		return err
+	}
+	if err := writeIDN(f.writer, resp.IDN); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Nameserver: %s\n", resp.Nameserver); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Query Time: %v\n", resp.QueryTime); err != nil {
+		return err
+	}
+
+	for _, result := range resp.Results {
+		if result.Error != "" {
+			if err := writeLine(f.writer, "\n%s: error: %s\n", result.RecordType, result.Error); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if len(result.Records) == 0 {
+			status := ""
+			if result.Rcode != "" && result.Rcode != "NOERROR" {
+				status = " (" + result.Rcode + ")"
+			}
+			if err := writeLine(f.writer, "\n%s: no records found%s\n", result.RecordType, status); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := writeLine(f.writer, "\n%s Records (%d, %v):\n", result.RecordType, len(result.Records), result.QueryTime); err != nil {
+			return err
+		}
+		for _, record := range result.Records {
+			if err := writeLine(f.writer, "  %-6s  %-40s  TTL: %d\n", record.Type, record.Value, record.TTL); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (f *Formatter) OutputDNSEnum(resp *dnsinfo.EnumResponse) error {
+	switch f.format {
+	case "json":
+		return f.outputJSON(resp)
+	case "ndjson":
+		return f.outputDNSEnumNDJSON(resp)
+	default:
+		return f.outputDNSEnumText(resp)
+	}
+}
+
+// outputDNSEnumNDJSON writes one compact JSON object per name, so results can
+// be streamed into line-oriented tools.
+func (f *Formatter) outputDNSEnumNDJSON(resp *dnsinfo.EnumResponse) error {
+	encoder := json.NewEncoder(f.writer)
+	for _, result := range resp.Results {
+		if err := encoder.Encode(result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *Formatter) outputDNSEnumText(resp *dnsinfo.EnumResponse) error {
+	if err := writeLine(f.writer, "Zone: %s\n", resp.Zone); err != nil {
+		return err
+	}
+	if err := writeIDN(f.writer, resp.IDN); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Record Type: %s\n", resp.RecordType); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Nameserver: %s\n", resp.Nameserver); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Query Time: %v\n", resp.QueryTime); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Candidates: %d, found: %d, wildcard matches: %d, failed: %d\n", resp.Candidates, resp.Found, resp.Filtered, resp.Failed); err != nil {
+		return err
+	}
+
+	if resp.Wildcard != nil {
+		if err := writeWildcard(f.writer, resp.Wildcard, nil); err != nil {
+			return err
+		}
+	}
+
+	if len(resp.Results) == 0 {
+		return writeLine(f.writer, "\nNo names found\n")
+	}
+
+	if err := writeLine(f.writer, "\n%-40s  %-8s  %s\n", "NAME", "STATUS", "RECORDS"); err != nil {
+		return err
+	}
+	for _, result := range resp.Results {
+		if result.Error != "" {
+			if err := writeLine(f.writer, "%-40s  %-8s  %s\n", result.Name, "ERROR", result.Error); err != nil {
+				return err
+			}
+			continue
+		}
+
+		values := make([]string, 0, len(result.Records))
+		for _, record := range result.Records {
+			values = append(values, record.Value)
+		}
+		if err := writeLine(f.writer, "%-40s  %-8s  %s\n", result.Name, result.Rcode, strings.Join(values, ", ")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *Formatter) OutputDNSBench(resp *dnsinfo.BenchResponse) error {
+	switch f.format {
+	case "json":
+		return f.outputJSON(resp)
+	default:
+		return f.outputDNSBenchText(resp)
+	}
+}
+
+func (f *Formatter) outputDNSBenchText(resp *dnsinfo.BenchResponse) error {
+	if err := writeLine(f.writer, "Domain: %s\n", resp.Domain); err != nil {
+		return err
+	}
+	if err := writeIDN(f.writer, resp.IDN); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Record Type: %s\n", resp.RecordType); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Queries: %d cached and %d uncached per resolver, concurrency %d\n", resp.Count, resp.Count, resp.Concurrency); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Query Time: %v\n", resp.QueryTime); err != nil {
+		return err
+	}
+
+	for _, mode := range []string{"Cached", "Uncached"} {
+		if err := writeLine(f.writer, "\n%s:\n", mode); err != nil {
+			return err
+		}
+		if err := writeLine(f.writer, "  %-36s  %10s  %10s  %10s  %10s  %10s  %s\n", "NAMESERVER", "MIN", "MEDIAN", "P95", "P99", "MAX", "ERRORS"); err != nil {
+			return err
+		}
+		for _, result := range resp.Results {
+			stats := result.Cached
+			if mode == "Uncached" {
+				stats = result.Uncached
+			}
+			if err := writeLine(f.writer, "  %-36s  %10v  %10v  %10v  %10v  %10v  %.1f%% (%d timeouts, %d errors)\n",
+				result.Nameserver,
+				stats.Min.Round(time.Microsecond),
+				stats.Median.Round(time.Microsecond),
+				stats.P95.Round(time.Microsecond),
+				stats.P99.Round(time.Microsecond),
+				stats.Max.Round(time.Microsecond),
+				stats.ErrorRate*100, stats.Timeouts, stats.Errors,
+			); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (f *Formatter) OutputDNSSEC(resp *dnsinfo.DNSSECResponse) error {
+	switch f.format {
+	case "json":
+		return f.outputJSON(resp)
+	default:
+		return f.outputDNSSECText(resp)
+	}
+}
+
+func (f *Formatter) outputDNSSECText(resp *dnsinfo.DNSSECResponse) error {
+	if err := writeLine(f.writer, "Domain: %s\n", resp.Domain); err != nil {
+		return err
+	}
+	if err := writeIDN(f.writer, resp.IDN); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Record Type: %s\n", resp.RecordType); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Nameserver: %s\n", resp.Nameserver); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Query Time: %v\n", resp.QueryTime); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "DNSSEC Status: %s\n", resp.Status); err != nil {
+		return err
+	}
+
+	if len(resp.Links) > 0 {
+		if err := writeLine(f.writer, "\nChain of Trust:\n"); err != nil {
+			return err
+		}
+		for _, link := range resp.Links {
+			if err := writeLine(f.writer, "  %-15s  %-20s  %-6s  %s\n", "["+link.Status+"]", link.Zone, link.Type, link.Reason); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(resp.Records) > 0 {
+		if err := writeLine(f.writer, "\nRecords (%d):\n", len(resp.Records)); err != nil {
+			return err
+		}
+		for _, record := range resp.Records {
+			if err := writeLine(f.writer, "  %-6s  %-40s  TTL: %d\n", record.Type, record.Value, record.TTL); err != nil {
+				return err
+			}
+		}
+	} else {
+		if err := writeLine(f.writer, "\nNo records found\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *Formatter) OutputFCrDNS(resp *dnsinfo.FCrDNSResponse) error {
+	switch f.format {
+	case "json":
+		return f.outputJSON(resp)
+	default:
+		return f.outputFCrDNSText(resp)
+	}
+}
+
+func (f *Formatter) outputFCrDNSText(resp *dnsinfo.FCrDNSResponse) error {
+	if err := writeLine(f.writer, "IP Address: %s\n", resp.IP); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Reverse Name: %s\n", resp.ReverseName); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Nameserver: %s\n", resp.Nameserver); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Query Time: %v\n", resp.QueryTime); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Status: %s\n", resp.Rcode); err != nil {
+		return err
+	}
+
+	if len(resp.Hostnames) == 0 {
+		if err := writeLine(f.writer, "\nNo PTR records found\n"); err != nil {
+			return err
+		}
+	} else {
+		if err := writeLine(f.writer, "\nForward Confirmation (%d):\n", len(resp.Hostnames)); err != nil {
+			return err
+		}
+		for _, hostname := range resp.Hostnames {
+			result := "[no match]"
+			if hostname.Matched {
+				result = "[match]"
+			}
+
+			addresses := strings.Join(hostname.Addresses, ", ")
+			if hostname.Error != "" {
+				addresses = "error: " + hostname.Error
+			}
+
+			if err := writeLine(f.writer, "  %-12s  %s -> %s\n", result, hostname.Hostname, addresses); err != nil {
+				return err
+			}
+		}
+	}
+
+	confirmed := "not confirmed"
+	if resp.Confirmed {
+		confirmed = "confirmed"
+	}
+
+	return writeLine(f.writer, "\nFCrDNS: %s\n", confirmed)
+}
+
+func (f *Formatter) OutputDNSCompare(resp *dnsinfo.CompareResponse) error {
+	switch f.format {
+	case "json":
+		return f.outputJSON(resp)
+	default:
+		return f.outputDNSCompareText(resp)
+	}
+}
+
+func (f *Formatter) outputDNSCompareText(resp *dnsinfo.CompareResponse) error {
+	if err := writeLine(f.writer, "Domain: %s\n", resp.Domain); err != nil {
+		return err
+	}
+	if err := writeIDN(f.writer, resp.IDN); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Record Type: %s\n", resp.RecordType); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Query Time: %v\n", resp.QueryTime); err != nil {
+		return err
+	}
+
+	consistency := "consistent"
+	if !resp.Consistent {
+		consistency = fmt.Sprintf("INCONSISTENT (%d distinct answers)", len(resp.Answers))
+	}
+	if err := writeLine(f.writer, "Consistency: %s\n", consistency); err != nil {
+		return err
+	}
+
+	if err := writeLine(f.writer, "\nResolvers (%d):\n", len(resp.Results)); err != nil {
+		return err
+	}
+	for _, result := range resp.Results {
+		// Resolvers that disagree with the majority answer are marked.
+		marker := " "
+		if !result.Agrees {
+			marker = "!"
+		}
+
+		if result.Error != "" {
+			if err := writeLine(f.writer, "%s %-36s  error: %s\n", marker, result.Nameserver, result.Error); err != nil {
+				return err
+			}
+			continue
+		}
+
+		values := make([]string, 0, len(result.Records))
+		var ttl uint32
+		for i, record := range result.Records {
+			values = append(values, record.Value)
+			if i == 0 || record.TTL < ttl {
+				ttl = record.TTL
+			}
+		}
+
+		answer := strings.Join(values, ", ")
+		if answer == "" {
+			answer = "(no records)"
+		}
+
+		if err := writeLine(f.writer, "%s %-36s  %-8s  %-10v  TTL: %-6d  %s\n", marker, result.Nameserver, result.Rcode, result.QueryTime, ttl, answer); err != nil {
+			return err
+		}
+	}
+
+	if len(resp.Answers) > 1 {
+		if err := writeLine(f.writer, "\nDistinct Answers (%d):\n", len(resp.Answers)); err != nil {
+			return err
+		}
+		for _, answer := range resp.Answers {
+			values := strings.Join(answer.Values, ", ")
+			if values == "" {
+				values = "(no records)"
+			}
+			if err := writeLine(f.writer, "  %s: %s\n", answer.Rcode, values); err != nil {
+				return err
+			}
+			if err := writeLine(f.writer, "    served by %s\n", strings.Join(answer.Nameservers, ", ")); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (f *Formatter) OutputNSAudit(resp *dnsinfo.NSAuditResponse) error {
+	switch f.format {
+	case "json":
+		return f.outputJSON(resp)
+	default:
+		return f.outputNSAuditText(resp)
+	}
+}
+
+func (f *Formatter) outputNSAuditText(resp *dnsinfo.NSAuditResponse) error {
+	if err := writeLine(f.writer, "Zone: %s\n", resp.Zone); err != nil {
+		return err
+	}
+	if err := writeIDN(f.writer, resp.IDN); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Record Type: %s\n", resp.RecordType); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Nameserver: %s\n", resp.Nameserver); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Query Time: %v\n", resp.QueryTime); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "SOA Serial: %d\n", resp.Serial); err != nil {
+		return err
+	}
+
+	consistency := "consistent"
+	if !resp.Consistent {
+		consistency = "INCONSISTENT"
+	}
+	if err := writeLine(f.writer, "Consistency: %s\n", consistency); err != nil {
+		return err
+	}
+
+	if err := writeLine(f.writer, "\nAuthoritative Servers (%d):\n", len(resp.Servers)); err != nil {
+		return err
+	}
+	for _, server := range resp.Servers {
+		status := "[" + server.Status + "]"
+
+		if server.Address == "" {
+			if err := writeLine(f.writer, "  %-14s  %s  error: %s\n", status, server.Nameserver, server.Error); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if server.Error != "" {
+			if err := writeLine(f.writer, "  %-14s  %s  %s (%s)  error: %s\n", status, server.Nameserver, server.Address, server.Family, server.Error); err != nil {
+				return err
+			}
+			continue
+		}
+
+		values := make([]string, 0, len(server.Records))
+		for _, record := range server.Records {
+			values = append(values, record.Value)
+		}
+
+		if err := writeLine(f.writer, "  %-14s  %s  %s (%s)  serial %d  %v  %s\n", status, server.Nameserver, server.Address, server.Family, server.Serial, server.QueryTime, strings.Join(values, ", ")); err != nil {
+			return err
+		}
+		for _, issue := range server.Issues {
+			if err := writeLine(f.writer, "    - %s\n", issue); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (f *Formatter) OutputDelegation(resp *dnsinfo.DelegationResponse) error {
+	switch f.format {
+	case "json":
+		return f.outputJSON(resp)
+	default:
+		return f.outputDelegationText(resp)
+	}
+}
+
+func (f *Formatter) outputDelegationText(resp *dnsinfo.DelegationResponse) error {
+	if err := writeLine(f.writer, "Domain: %s\n", resp.Domain); err != nil {
+		return err
+	}
+	if err := writeIDN(f.writer, resp.IDN); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Parent Zone: %s\n", resp.ParentZone); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Parent Server: %s (%s)\n", resp.ParentServer, resp.ParentAddress); err != nil {
+		return err
+	}
+	if resp.ChildServer != "" {
+		if err := writeLine(f.writer, "Child Server: %s\n", resp.ChildServer); err != nil {
+			return err
+		}
+	}
+	if err := writeLine(f.writer, "Query Time: %v\n", resp.QueryTime); err != nil {
+		return err
+	}
+
+	consistency := "consistent"
+	if !resp.Consistent {
+		consistency = fmt.Sprintf("INCONSISTENT (%d issues)", len(resp.Issues))
+	}
+	if err := writeLine(f.writer, "Delegation: %s\n", consistency); err != nil {
+		return err
+	}
+
+	if err := writeLine(f.writer, "\nNameservers (%d):\n", len(resp.Nameservers)); err != nil {
+		return err
+	}
+	for _, ns := range resp.Nameservers {
+		sources := make([]string, 0, 3)
+		if ns.InParent {
+			sources = append(sources, "parent")
+		}
+		if ns.InChild {
+			sources = append(sources, "child")
+		}
+		if ns.InRegistry {
+			sources = append(sources, "registry")
+		}
+
+		if err := writeLine(f.writer, "  %s [%s]\n", ns.Name, strings.Join(sources, ", ")); err != nil {
+			return err
+		}
+		if len(ns.Glue) > 0 {
+			if err := writeLine(f.writer, "    Glue: %s\n", strings.Join(ns.Glue, ", ")); err != nil {
+				return err
+			}
+		}
+		if len(ns.Addresses) > 0 {
+			if err := writeLine(f.writer, "    Addresses: %s\n", strings.Join(ns.Addresses, ", ")); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(resp.Issues) > 0 {
+		if err := writeLine(f.writer, "\nIssues (%d):\n", len(resp.Issues)); err != nil {
+			return err
+		}
+		for _, issue := range resp.Issues {
+			if err := writeLine(f.writer, "  - %s\n", issue); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// DNSTransferRecords returns a handler writing the records of a zone transfer
+// as they arrive: in zone-file format, or as one compact JSON object per
+// record for the json format. OutputDNSTransfer writes the summary once the
+// transfer is done.
+func (f *Formatter) DNSTransferRecords() dnsinfo.TransferHandler {
+	started := false
+	encoder := json.NewEncoder(f.writer)
+
+	return func(server *dnsinfo.TransferServer, records []dnsinfo.Record) error {
+		if f.format == "json" {
+			for _, record := range records {
+				if err := encoder.Encode(record); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		if !started {
+			started = true
+			if err := writeLine(f.writer, "; Records from %s (%s):\n", server.Nameserver, server.Address); err != nil {
+				return err
+			}
+		}
+		for _, record := range records {
+			if err := writeLine(f.writer, "%s\t%d\tIN\t%s\t%s\n", record.Name, record.TTL, record.Type, zoneFileValue(record)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func (f *Formatter) OutputDNSTransfer(resp *dnsinfo.TransferResponse) error {
+	switch f.format {
+	case "json":
+		return f.outputJSON(resp)
+	default:
+		return f.outputDNSTransferText(resp)
+	}
+}
+
+// outputDNSTransferText writes the summary of a zone transfer as comments, so
+// the output, following the streamed records, can be loaded as a zone file.
+func (f *Formatter) outputDNSTransferText(resp *dnsinfo.TransferResponse) error {
+	if resp.Source != "" {
+		if err := writeLine(f.writer, ";\n"); err != nil {
+			return err
+		}
+	}
+	if err := writeLine(f.writer, "; Zone: %s\n", resp.Zone); err != nil {
+		return err
+	}
+	// The header is made of zone file comments, so the name is not written
+	// with writeIDN.
+	if resp.IDN != nil {
+		if resp.IDN.IsIDN() {
+			if err := writeLine(f.writer, "; Unicode: %s\n", resp.IDN.Unicode); err != nil {
+				return err
+			}
+		}
+		for _, warning := range resp.IDN.Warnings {
+			if err := writeLine(f.writer, "; WARNING: %s\n", warning); err != nil {
+				return err
+			}
+		}
+	}
+	transfer := resp.Type
+	if resp.Type == "IXFR" {
+		transfer = fmt.Sprintf("IXFR (from serial %d)", resp.Serial)
+	}
+	if err := writeLine(f.writer, "; Transfer: %s\n", transfer); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "; Nameserver: %s\n", resp.Nameserver); err != nil {
+		return err
+	}
+	if resp.TSIGKey != "" {
+		if err := writeLine(f.writer, "; TSIG Key: %s\n", resp.TSIGKey); err != nil {
+			return err
+		}
+	}
+	if err := writeLine(f.writer, "; Query Time: %v\n", resp.QueryTime); err != nil {
+		return err
+	}
+
+	if err := writeLine(f.writer, ";\n; Authoritative Servers (%d):\n", len(resp.Servers)); err != nil {
+		return err
+	}
+	for _, server := range resp.Servers {
+		status := "[" + server.Status + "]"
+
+		switch {
+		case server.Status == dnsinfo.TransferOK:
+			if err := writeLine(f.writer, ";   %-13s  %s  %s (%s)  serial %d  %d records  %v\n", status, server.Nameserver, server.Address, server.Family, server.Serial, server.RecordCount, server.QueryTime); err != nil {
+				return err
+			}
+		case server.Address == "":
+			if err := writeLine(f.writer, ";   %-13s  %s  error: %s\n", status, server.Nameserver, server.Error); err != nil {
+				return err
+			}
+		default:
+			if err := writeLine(f.writer, ";   %-13s  %s  %s (%s)  error: %s\n", status, server.Nameserver, server.Address, server.Family, server.Error); err != nil {
+				return err
+			}
+		}
+	}
+
+	if resp.Transferred > 0 && resp.TSIGKey == "" {
+		if err := writeLine(f.writer, ";\n; WARNING: %d of %d servers allow zone transfers without authentication\n", resp.Transferred, len(resp.Servers)); err != nil {
+			return err
+		}
+	}
+
+	if resp.Transferred == 0 {
+		return writeLine(f.writer, ";\n; No server allowed the zone transfer\n")
+	}
+
+	return nil
+}
+
+// zoneFileValue returns the record data in zone-file presentation format.
+// TXT strings are quoted, the other values already are in that format.
+func zoneFileValue(record dnsinfo.Record) string {
+	txt, ok := record.Data.(*dnsinfo.TXTData)
+	if !ok {
+		return record.Value
+	}
+
+	quoted := make([]string, 0, len(txt.Strings))
+	for _, s := range txt.Strings {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+		quoted = append(quoted, `"`+strings.ReplaceAll(s, `"`, `\"`)+`"`)
+	}
+	return strings.Join(quoted, " ")
+}
+
+func (f *Formatter) OutputDNSTrace(resp *dnsinfo.TraceResponse) error {
+	switch f.format {
+	case "json":
+		return f.outputJSON(resp)
+	default:
+		return f.outputDNSTraceText(resp)
+	}
+}
+
+func (f *Formatter) outputDNSTraceText(resp *dnsinfo.TraceResponse) error {
+	if err := writeLine(f.writer, "Domain: %s\n", resp.Domain); err != nil {
+		return err
+	}
+	if err := writeIDN(f.writer, resp.IDN); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Record Type: %s\n", resp.RecordType); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Query Time: %v\n", resp.QueryTime); err != nil {
+		return err
+	}
+
+	if err := writeLine(f.writer, "\nResolution Trace:\n"); err != nil {
+		return err
+	}
+
+	depth := 0
+	for i, hop := range resp.Hops {
+		// Resolution restarts from the root when following a CNAME.
+		if i > 0 && hop.Zone == "." {
+			depth = 0
+			if err := writeLine(f.writer, "\n  restarting at the root for %s\n", hop.Name); err != nil {
+				return err
+			}
+		}
+
+		indent := "  " + strings.Repeat("    ", depth)
+		authoritative := ""
+		if hop.Authoritative {
+			authoritative = " [authoritative]"
+		}
+		if err := writeLine(f.writer, "%s%s via %s (%s) %v%s\n", indent, hop.Zone, hop.Server, hop.Address, hop.QueryTime, authoritative); err != nil {
+			return err
+		}
+
+		for _, failure := range hop.Failures {
+			if err := writeLine(f.writer, "%s│   failed: %s\n", indent, failure); err != nil {
+				return err
+			}
+		}
+
+		switch {
+		case hop.Error != "":
+			if err := writeLine(f.writer, "%s└── error: %s\n", indent, hop.Error); err != nil {
+				return err
+			}
+		case len(hop.Answer) > 0:
+			for j, record := range hop.Answer {
+				branch := "├──"
+				if j == len(hop.Answer)-1 {
+					branch = "└──"
+				}
+				if err := writeLine(f.writer, "%s%s answer: %s %s %s TTL: %d\n", indent, branch, record.Name, record.Type, record.Value, record.TTL); err != nil {
+					return err
+				}
+			}
+		case hop.Referral != "":
+			if err := writeLine(f.writer, "%s└── referral to %s [%s]\n", indent, hop.Referral, strings.Join(hop.Nameservers, ", ")); err != nil {
+				return err
+			}
+			for _, glue := range hop.Glue {
+				if err := writeLine(f.writer, "%s    glue: %s %s %s\n", indent, glue.Name, glue.Type, glue.Value); err != nil {
+					return err
+				}
+			}
+			depth++
+		default:
+			if err := writeLine(f.writer, "%s└── %s, no answer\n", indent, hop.Rcode); err != nil {
+				return err
+			}
+		}
+	}
+
+	if resp.Error != "" {
+		if err := writeLine(f.writer, "\nError: %s\n", resp.Error); err != nil {
+			return err
+		}
+	}
+
+	if len(resp.Records) > 0 {
+		if err := writeLine(f.writer, "\nRecords (%d):\n", len(resp.Records)); err != nil {
+			return err
+		}
+		for _, record := range resp.Records {
+			if err := writeLine(f.writer, "  %-6s  %-40s  TTL: %d\n", record.Type, record.Value, record.TTL); err != nil {
+				return err
+			}
+		}
+	} else {
+		if err := writeLine(f.writer, "\nNo records found\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeDNSTLSInfo(writer io.Writer, info *dnsinfo.TLSInfo) error {
+	if err := writeLine(writer, "\nTLS Information:\n"); err != nil {
+		return err
+	}
+	if err := writeLine(writer, "  Version: %s\n", info.Version); err != nil {
+		return err
+	}
+	if err := writeLine(writer, "  Cipher Suite: %s\n", info.CipherSuite); err != nil {
+		return err
+	}
+	if err := writeLine(writer, "  Server Name: %s\n", info.ServerName); err != nil {
+		return err
+	}
+
+	verified := "No"
+	if info.Verified {
+		verified = "Yes"
+	}
+	if err := writeLine(writer, "  Verified: %s\n", verified); err != nil {
+		return err
+	}
+
+	if info.Subject != "" {
+		if err := writeLine(writer, "  Subject: %s\n", info.Subject); err != nil {
+			return err
+		}
+		if err := writeLine(writer, "  Issuer: %s\n", info.Issuer); err != nil {
+			return err
+		}
+		if err := writeLine(writer, "  Not After: %s\n", info.NotAfter.Format(time.RFC3339)); err != nil {
+			return err
+		}
+		if err := writeLine(writer, "  SPKI Pin: %s\n", info.SPKIPin); err != nil {
+			return err
+		}
+	}
+
+	if info.MatchedPin != "" {
+		if err := writeLine(writer, "  Matched Pin: %s\n", info.MatchedPin); err != nil {
+			return err
+		}
+	}
+
+	return writeLine(writer, "  Handshake Time: %v\n", info.HandshakeTime)
+}
+
+func contactDisplayName(contact *whoisparser.Contact) string {
+	if contact == nil {
+		return ""
+	}
+
+	if contact.Organization != "" {
+		return contact.Organization
+	}
+
+	return contact.Name
+}
+
+func writeContactSection(writer io.Writer, title string, contact *whoisparser.Contact) error {
+	if contact == nil {
+		return nil
+	}
+
+	lines := make([]string, 0)
+
+	if contact.Name != "" {
+		lines = append(lines, fmt.Sprintf("Name: %s", contact.Name))
+	}
+	if contact.Organization != "" {
+		lines = append(lines, fmt.Sprintf("Organization: %s", contact.Organization))
+	}
+	if contact.Email != "" {
+		lines = append(lines, fmt.Sprintf("Email: %s", contact.Email))
+	}
+	location := strings.TrimSpace(strings.Join(filterEmpty([]string{
+		contact.City,
+		contact.Province,
+		contact.Country,
+	}), ", "))
+	if location != "" {
+		lines = append(lines, fmt.Sprintf("Location: %s", location))
+	}
+	if contact.Phone != "" {
+		lines = append(lines, fmt.Sprintf("Phone: %s", contact.Phone))
+	}
+
+	if len(lines) == 0 {
+		return nil
+	}
+
+	if err := writeLine(writer, "\n%s:\n", title); err != nil {
+		return err
+	}
+	for _, line := range lines {
+		if err := writeLine(writer, "  %s\n", line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func filterEmpty(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func (f *Formatter) OutputMail(resp *mailinfo.Response) error {
+	switch f.format {
+	case "json":
+		return f.outputJSON(resp)
+	default:
+		return f.outputMailText(resp)
+	}
+}
+
+func (f *Formatter) outputMailText(resp *mailinfo.Response) error {
+	if err := writeLine(f.writer, "Domain: %s\n", resp.Domain); err != nil {
+		return err
+	}
+	if err := writeIDN(f.writer, resp.IDN); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Query Time: %v\n", resp.QueryTime); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Status: %s\n", resp.Status); err != nil {
+		return err
+	}
+
+	if spf := resp.SPF; spf != nil {
+		if err := writeMailCheck(f.writer, "SPF", spf.Status, spf.Record); err != nil {
+			return err
+		}
+		if spf.Record != "" {
+			if err := writeLine(f.writer, "  DNS Lookups: %d/10\n", spf.Lookups); err != nil {
+				return err
+			}
+		}
+		if len(spf.Includes) > 0 {
+			includes := make([]string, 0, len(spf.Includes))
+			for _, include := range spf.Includes {
+				includes = append(includes, fmt.Sprintf("%s (%s)", include.Domain, include.Status))
+			}
+			if err := writeLine(f.writer, "  Includes: %s\n", strings.Join(includes, ", ")); err != nil {
+				return err
+			}
+		}
+		if err := writeMailIssues(f.writer, "  ", spf.Issues); err != nil {
+			return err
+		}
+	}
+
+	if dmarc := resp.DMARC; dmarc != nil {
+		if err := writeMailCheck(f.writer, "DMARC", dmarc.Status, dmarc.Record); err != nil {
+			return err
+		}
+		if dmarc.Policy != "" {
+			policy := dmarc.Policy
+			if dmarc.SubdomainPolicy != "" {
+				policy += ", subdomains: " + dmarc.SubdomainPolicy
+			}
+			if err := writeLine(f.writer, "  Policy: %s (pct %d, adkim %s, aspf %s)\n", policy, dmarc.Percent, dmarc.ADKIM, dmarc.ASPF); err != nil {
+				return err
+			}
+		}
+		if len(dmarc.RUA) > 0 {
+			if err := writeLine(f.writer, "  Aggregate Reports: %s\n", strings.Join(dmarc.RUA, ", ")); err != nil {
+				return err
+			}
+		}
+		if len(dmarc.RUF) > 0 {
+			if err := writeLine(f.writer, "  Failure Reports: %s\n", strings.Join(dmarc.RUF, ", ")); err != nil {
+				return err
+			}
+		}
+		if err := writeMailIssues(f.writer, "  ", dmarc.Issues); err != nil {
+			return err
+		}
+	}
+
+	if dkim := resp.DKIM; dkim != nil {
+		if err := writeMailCheck(f.writer, "DKIM", dkim.Status, ""); err != nil {
+			return err
+		}
+		for _, selector := range dkim.Selectors {
+			key := selector.KeyType
+			if selector.KeyBits > 0 {
+				key = fmt.Sprintf("%s, %d bits", selector.KeyType, selector.KeyBits)
+			}
+			if key == "" {
+				key = "no key"
+			}
+			if err := writeLine(f.writer, "  %-8s %s (%s)\n", "["+selector.Status+"]", selector.Selector, key); err != nil {
+				return err
+			}
+			if err := writeMailIssues(f.writer, "    ", selector.Issues); err != nil {
+				return err
+			}
+		}
+		if err := writeMailIssues(f.writer, "  ", dkim.Issues); err != nil {
+			return err
+		}
+	}
+
+	if sts := resp.MTASTS; sts != nil {
+		if err := writeMailCheck(f.writer, "MTA-STS", sts.Status, sts.Record); err != nil {
+			return err
+		}
+		if sts.Mode != "" {
+			if err := writeLine(f.writer, "  Policy: %s (max_age %d, mx %s)\n", sts.Mode, sts.MaxAge, strings.Join(sts.MX, ", ")); err != nil {
+				return err
+			}
+		}
+		if err := writeMailIssues(f.writer, "  ", sts.Issues); err != nil {
+			return err
+		}
+	}
+
+	if rpt := resp.TLSRPT; rpt != nil {
+		if err := writeMailCheck(f.writer, "TLS-RPT", rpt.Status, rpt.Record); err != nil {
+			return err
+		}
+		if err := writeMailIssues(f.writer, "  ", rpt.Issues); err != nil {
+			return err
+		}
+	}
+
+	if bimi := resp.BIMI; bimi != nil {
+		if err := writeMailCheck(f.writer, "BIMI", bimi.Status, bimi.Record); err != nil {
+			return err
+		}
+		if err := writeMailIssues(f.writer, "  ", bimi.Issues); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeMailCheck(w io.Writer, name string, status string, record string) error {
+	if err := writeLine(w, "\n%s [%s]\n", name, status); err != nil {
+		return err
+	}
+	if record != "" {
+		return writeLine(w, "  Record: %s\n", record)
+	}
+	return nil
+}
+
+func writeMailIssues(w io.Writer, indent string, issues []string) error {
+	for _, issue := range issues {
+		if err := writeLine(w, "%s- %s\n", indent, issue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *Formatter) OutputTakeover(resp *takeover.Response) error {
+	switch f.format {
+	case "json":
+		return f.outputJSON(resp)
+	default:
+		return f.outputTakeoverText(resp)
+	}
+}
+
+func (f *Formatter) outputTakeoverText(resp *takeover.Response) error {
+	if err := writeLine(f.writer, "Checked: %d\n", resp.Checked); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Vulnerable: %d\n", resp.Vulnerable); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Dangling: %d\n", resp.Dangling); err != nil {
+		return err
+	}
+	if err := writeLine(f.writer, "Query Time: %v\n", resp.QueryTime); err != nil {
+		return err
+	}
+
+	if err := writeLine(f.writer, "\nResults (%d):\n", len(resp.Results)); err != nil {
+		return err
+	}
+	for _, result := range resp.Results {
+		status := "[" + result.Status + "]"
+
+		target := ""
+		if len(result.CNAMEs) > 0 {
+			target = "  -> " + strings.Join(result.CNAMEs, " -> ")
+		}
+		if result.Service != "" {
+			target += " (" + result.Service + ")"
+		}
+
+		if err := writeLine(f.writer, "  %-12s  %s%s\n", status, result.Name, target); err != nil {
+			return err
+		}
+		if result.IDN != nil {
+			if result.IDN.IsIDN() {
+				if err := writeLine(f.writer, "    unicode: %s\n", result.IDN.Unicode); err != nil {
+					return err
+				}
+			}
+			for _, warning := range result.IDN.Warnings {
+				if err := writeLine(f.writer, "    WARNING: %s\n", warning); err != nil {
+					return err
+				}
+			}
+		}
+		if result.Evidence != "" {
+			if err := writeLine(f.writer, "    %s\n", result.Evidence); err != nil {
+				return err
+			}
+		}
+		if result.Error != "" {
+			if err := writeLine(f.writer, "    error: %s\n", result.Error); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
 }
@@ -7,10 +7,16 @@ import (
 	"testing"
 	"time"
 
+	"watchr/internal/caa"
+	"watchr/internal/dane"
 	dnsinfo "watchr/internal/dns"
 	httpinfo "watchr/internal/http"
+	"watchr/internal/idn"
+	mailinfo "watchr/internal/mail"
 	"watchr/internal/rdap"
+	"watchr/internal/takeover"
 	tlsinfo "watchr/internal/tls"
+	"watchr/internal/whois"
 )
 
 func TestNewFormatter(t *testing.T) {
@@ -104,7 +110,7 @@ func TestFormatter_OutputWHOIS_Text(t *testing.T) {
 
 	whoisData := sampleWHOISRecord()
 
-	err := f.OutputWHOIS(whoisData)
+	err := f.OutputWHOIS(&whois.Response{Raw: whoisData})
 	if err != nil {
 		t.Fatalf("OutputWHOIS failed: %v", err)
 	}
@@ -138,7 +144,7 @@ func TestFormatter_OutputWHOIS_JSON(t *testing.T) {
 
 	whoisData := sampleWHOISRecord()
 
-	err := f.OutputWHOIS(whoisData)
+	err := f.OutputWHOIS(&whois.Response{Raw: whoisData})
 	if err != nil {
 		t.Fatalf("OutputWHOIS failed: %v", err)
 	}
@@ -177,7 +183,7 @@ func TestFormatter_OutputWHOIS_Text_FallbackOnParseError(t *testing.T) {
 
 	whoisData := "INVALID WHOIS RESPONSE"
 
-	err := f.OutputWHOIS(whoisData)
+	err := f.OutputWHOIS(&whois.Response{Raw: whoisData})
 	if err != nil {
 		t.Fatalf("OutputWHOIS failed: %v", err)
 	}
@@ -431,157 +437,1678 @@ func TestFormatter_OutputDNS_JSON(t *testing.T) {
 	}
 }
 
-func TestFormatter_OutputTLSScan_Text(t *testing.T) {
+func TestFormatter_OutputDNS_JSONRecordData(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("json", buf)
+
+	resp := &dnsinfo.Response{
+		Domain:     "example.com.",
+		RecordType: "MX",
+		Records: []dnsinfo.Record{
+			{
+				Type:  "MX",
+				Value: "10 mail.example.com.",
+				TTL:   3600,
+				Data:  &dnsinfo.MXData{Preference: 10, Exchange: "mail.example.com."},
+			},
+		},
+	}
+
+	if err := f.OutputDNS(resp); err != nil {
+		t.Fatalf("OutputDNS failed: %v", err)
+	}
+
+	var result struct {
+		Records []struct {
+			Value string `json:"value"`
+			Data  struct {
+				Preference int    `json:"preference"`
+				Exchange   string `json:"exchange"`
+			} `json:"data"`
+		} `json:"records"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse JSON output: %v", err)
+	}
+
+	if len(result.Records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(result.Records))
+	}
+
+	record := result.Records[0]
+	if record.Value != "10 mail.example.com." {
+		t.Errorf("expected flattened value to be kept, got %q", record.Value)
+	}
+
+	if record.Data.Preference != 10 || record.Data.Exchange != "mail.example.com." {
+		t.Errorf("unexpected MX data: %+v", record.Data)
+	}
+}
+
+func TestFormatter_OutputDNS_TextFullResponse(t *testing.T) {
 	buf := new(bytes.Buffer)
 	f := NewFormatter("text", buf)
 
-	result := &tlsinfo.TestResult{
-		Host: "example.com",
-		Port: "443",
-		SupportedVersions: map[string]bool{
-			"TLS 1.3": true,
-			"TLS 1.2": true,
-			"TLS 1.1": false,
-			"TLS 1.0": false,
+	resp := &dnsinfo.Response{
+		Domain:     "missing.example.com.",
+		RecordType: "A",
+		Nameserver: "8.8.8.8:53",
+		Transport:  "tcp",
+		Rcode:      "NXDOMAIN",
+		Flags: dnsinfo.Flags{
+			Response:           true,
+			RecursionDesired:   true,
+			RecursionAvailable: true,
 		},
-		CipherSuites: map[string][]string{
-			"TLS 1.3": {"TLS_AES_256_GCM_SHA384", "TLS_CHACHA20_POLY1305_SHA256"},
-			"TLS 1.2": {"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+		EDNS: &dnsinfo.EDNSInfo{
+			UDPSize: 512,
+			Options: []dnsinfo.EDNSOption{
+				{Code: 15, Name: "EDE", Value: "9 (DNSKEY Missing)"},
+			},
+		},
+		Records: []dnsinfo.Record{},
+		Authority: []dnsinfo.Record{
+			{Name: "example.com.", Type: "SOA", Value: "ns.icann.org. noc.dns.icann.org. 1 7200 3600 1209600 3600", TTL: 3600},
 		},
-		PreferredVersion: "TLS 1.3",
-		PreferredCipher:  "TLS_AES_256_GCM_SHA384",
-		Vulnerabilities:  []string{},
 	}
 
-	err := f.OutputTLSScan(result)
+	err := f.OutputDNS(resp)
 	if err != nil {
-		t.Fatalf("OutputTLSScan failed: %v", err)
+		t.Fatalf("OutputDNS failed: %v", err)
 	}
 
 	output := buf.String()
 
-	if !strings.Contains(output, "example.com") {
-		t.Error("expected output to contain host")
+	if !strings.Contains(output, "Transport: tcp") {
+		t.Error("expected output to contain transport")
 	}
 
-	if !strings.Contains(output, "Supported TLS Versions") {
-		t.Error("expected output to contain 'Supported TLS Versions'")
+	if !strings.Contains(output, "Status: NXDOMAIN") {
+		t.Error("expected output to contain rcode")
 	}
 
-	if !strings.Contains(output, "TLS 1.3: Yes") {
-		t.Error("expected output to show TLS 1.3 as supported")
+	if !strings.Contains(output, "Flags: qr rd ra") {
+		t.Error("expected output to contain header flags")
 	}
 
-	if !strings.Contains(output, "TLS 1.2: Yes") {
-		t.Error("expected output to show TLS 1.2 as supported")
+	if !strings.Contains(output, "EDNS: version 0, udp 512") {
+		t.Error("expected output to contain EDNS information")
 	}
 
-	if !strings.Contains(output, "TLS 1.0: No") {
-		t.Error("expected output to show TLS 1.0 as not supported")
+	if !strings.Contains(output, "EDE: 9 (DNSKEY Missing)") {
+		t.Error("expected output to contain EDNS options")
 	}
 
-	if !strings.Contains(output, "Supported Cipher Suites") {
-		t.Error("expected output to contain 'Supported Cipher Suites'")
+	if !strings.Contains(output, "Authority Section (1):") || !strings.Contains(output, "ns.icann.org.") {
+		t.Error("expected output to contain authority section")
 	}
 
-	if !strings.Contains(output, "TLS_AES_256_GCM_SHA384") {
-		t.Error("expected output to contain cipher suite")
+	if strings.Contains(output, "Additional Section") {
+		t.Error("expected empty additional section to be omitted")
 	}
+}
 
-	if !strings.Contains(output, "Preferred Version: TLS 1.3") {
-		t.Error("expected output to contain preferred version")
+func TestFormatter_OutputDNS_TextWithTLS(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("text", buf)
+
+	resp := &dnsinfo.Response{
+		Domain:     "example.com.",
+		RecordType: "A",
+		Nameserver: "tls://1.1.1.1:853",
+		TLS: &dnsinfo.TLSInfo{
+			Version:     "TLS 1.3",
+			CipherSuite: "TLS_AES_128_GCM_SHA256",
+			ServerName:  "cloudflare-dns.com",
+			Verified:    true,
+			Subject:     "CN=cloudflare-dns.com",
+			SPKIPin:     "GP8Knf7qBae+aIfythytMbYnL+yowaWVeD6MoLHkVRg=",
+		},
+		Records: []dnsinfo.Record{
+			{Type: "A", Value: "93.184.216.34", TTL: 3600},
+		},
 	}
 
-	if !strings.Contains(output, "Preferred Cipher: TLS_AES_256_GCM_SHA384") {
-		t.Error("expected output to contain preferred cipher")
+	err := f.OutputDNS(resp)
+	if err != nil {
+		t.Fatalf("OutputDNS failed: %v", err)
+	}
+
+	output := buf.String()
+
+	if !strings.Contains(output, "TLS Information:") {
+		t.Error("expected output to contain TLS section")
+	}
+
+	if !strings.Contains(output, "Server Name: cloudflare-dns.com") {
+		t.Error("expected output to contain TLS server name")
+	}
+
+	if !strings.Contains(output, "SPKI Pin: GP8Knf7qBae+aIfythytMbYnL+yowaWVeD6MoLHkVRg=") {
+		t.Error("expected output to contain SPKI pin")
 	}
 }
 
-func TestFormatter_OutputTLSScan_JSON(t *testing.T) {
+func TestFormatter_OutputDNS_TextWithHTTP(t *testing.T) {
 	buf := new(bytes.Buffer)
-	f := NewFormatter("json", buf)
+	f := NewFormatter("text", buf)
 
-	result := &tlsinfo.TestResult{
-		Host: "example.com",
-		Port: "443",
-		SupportedVersions: map[string]bool{
-			"TLS 1.3": true,
-			"TLS 1.2": true,
-			"TLS 1.1": false,
-			"TLS 1.0": false,
+	resp := &dnsinfo.Response{
+		Domain:     "example.com.",
+		RecordType: "A",
+		Nameserver: "https://dns.google/dns-query",
+		HTTP: &dnsinfo.HTTPInfo{
+			Method:      "POST",
+			StatusCode:  200,
+			Status:      "200 OK",
+			Proto:       "HTTP/2.0",
+			ContentType: "application/dns-message",
+		},
+		Records: []dnsinfo.Record{
+			{Type: "A", Value: "93.184.216.34", TTL: 3600},
 		},
-		PreferredVersion: "TLS 1.3",
 	}
 
-	err := f.OutputTLSScan(result)
+	err := f.OutputDNS(resp)
 	if err != nil {
-		t.Fatalf("OutputTLSScan failed: %v", err)
+		t.Fatalf("OutputDNS failed: %v", err)
 	}
 
-	var jsonResult map[string]interface{}
-	if err := json.Unmarshal(buf.Bytes(), &jsonResult); err != nil {
-		t.Fatalf("failed to parse JSON output: %v", err)
+	output := buf.String()
+
+	if !strings.Contains(output, "HTTP Information:") {
+		t.Error("expected output to contain HTTP section")
 	}
 
-	if jsonResult["host"] != "example.com" {
-		t.Error("expected host in JSON output")
+	if !strings.Contains(output, "Status: 200 OK") {
+		t.Error("expected output to contain HTTP status")
 	}
 
-	if jsonResult["port"] != "443" {
-		t.Error("expected port in JSON output")
+	if !strings.Contains(output, "Protocol: HTTP/2.0") {
+		t.Error("expected output to contain HTTP version")
 	}
+}
 
-	if jsonResult["preferredVersion"] != "TLS 1.3" {
-		t.Error("expected preferredVersion in JSON output")
+func TestFormatter_OutputDNS_TextWithAttempts(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("text", buf)
+
+	resp := &dnsinfo.Response{
+		Domain:     "www.example.com.",
+		RecordType: "A",
+		Nameserver: "192.0.2.2:53",
+		Rcode:      "NOERROR",
+		Searched:   []string{"www.corp.example.com.", "www.example.com."},
+		Attempts: []dnsinfo.QueryAttempt{
+			{Server: "192.0.2.1:53", Error: "i/o timeout"},
+			{Server: "192.0.2.2:53", Rcode: "NOERROR", QueryTime: 12 * time.Millisecond},
+		},
+		Records: []dnsinfo.Record{
+			{Type: "A", Value: "192.0.2.10", TTL: 300},
+		},
+	}
+
+	if err := f.OutputDNS(resp); err != nil {
+		t.Fatalf("OutputDNS failed: %v", err)
+	}
+
+	output := buf.String()
+
+	for _, expected := range []string{
+		"Searched: www.corp.example.com., www.example.com.",
+		"Attempts (2):",
+		"192.0.2.1:53  error: i/o timeout",
+		"192.0.2.2:53  NOERROR  12ms",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain %q", expected)
+		}
 	}
 }
 
-func TestFormatter_OutputTLSScan_WithVulnerabilities(t *testing.T) {
+func TestFormatter_OutputDNS_TextWithCNAMEChain(t *testing.T) {
 	buf := new(bytes.Buffer)
 	f := NewFormatter("text", buf)
 
-	result := &tlsinfo.TestResult{
-		Host: "example.com",
-		Port: "443",
-		SupportedVersions: map[string]bool{
-			"TLS 1.3": true,
-			"TLS 1.2": true,
-			"TLS 1.1": true,
-			"TLS 1.0": true,
+	resp := &dnsinfo.Response{
+		Domain:     "shop.example.com.",
+		RecordType: "A",
+		Rcode:      "NXDOMAIN",
+		Records: []dnsinfo.Record{
+			{Name: "shop.example.com.", Type: "CNAME", Value: "example-shop.azurewebsites.net.", TTL: 300},
+		},
+		Chain: &dnsinfo.CNAMEChain{
+			Hops:    []dnsinfo.CNAMEHop{{Name: "shop.example.com.", Target: "example-shop.azurewebsites.net.", TTL: 300}},
+			Target:  "example-shop.azurewebsites.net.",
+			Rcode:   "NXDOMAIN",
+			Status:  dnsinfo.ChainDangling,
+			Records: []dnsinfo.Record{},
+			Error:   "CNAME target example-shop.azurewebsites.net. does not exist (NXDOMAIN)",
 		},
-		PreferredVersion: "TLS 1.3",
-		Vulnerabilities:  []string{"Server supports deprecated TLS 1.0", "Server supports deprecated TLS 1.1"},
 	}
 
-	err := f.OutputTLSScan(result)
+	if err := f.OutputDNS(resp); err != nil {
+		t.Fatalf("OutputDNS failed: %v", err)
+	}
+
+	output := buf.String()
+
+	for _, expected := range []string{
+		"CNAME Chain (1 hops): dangling",
+		"shop.example.com. -> example-shop.azurewebsites.net.  TTL: 300",
+		"Target: example-shop.azurewebsites.net. (NXDOMAIN)",
+		"WARNING: CNAME target example-shop.azurewebsites.net. does not exist (NXDOMAIN); a dangling CNAME is a subdomain takeover risk",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain %q", expected)
+		}
+	}
+}
+
+func TestFormatter_OutputDNSMulti_Text(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("text", buf)
+
+	resp := &dnsinfo.MultiResponse{
+		Domain:     "example.com.",
+		Nameserver: "8.8.8.8:53",
+		QueryTime:  45 * time.Millisecond,
+		Results: []dnsinfo.TypeResult{
+			{
+				RecordType: "A",
+				Records:    []dnsinfo.Record{{Type: "A", Value: "93.184.216.34", TTL: 3600}},
+			},
+			{
+				RecordType: "MX",
+				Records:    []dnsinfo.Record{},
+			},
+			{
+				RecordType: "TXT",
+				Error:      "i/o timeout",
+			},
+		},
+	}
+
+	err := f.OutputDNSMulti(resp)
 	if err != nil {
-		t.Fatalf("OutputTLSScan failed: %v", err)
+		t.Fatalf("OutputDNSMulti failed: %v", err)
 	}
 
 	output := buf.String()
 
-	if !strings.Contains(output, "Security Warnings") {
-		t.Error("expected output to contain 'Security Warnings'")
+	if !strings.Contains(output, "A Records (1") {
+		t.Error("expected output to contain A records section")
 	}
 
-	if !strings.Contains(output, "Server supports deprecated TLS 1.0") {
-		t.Error("expected output to contain TLS 1.0 warning")
+	if !strings.Contains(output, "93.184.216.34") {
+		t.Error("expected output to contain IP address")
 	}
 
-	if !strings.Contains(output, "Server supports deprecated TLS 1.1") {
-		t.Error("expected output to contain TLS 1.1 warning")
+	if !strings.Contains(output, "MX: no records found") {
+		t.Error("expected output to report missing MX records")
+	}
+
+	if !strings.Contains(output, "TXT: error: i/o timeout") {
+		t.Error("expected output to contain TXT error")
 	}
 }
 
-func sampleWHOISRecord() string {
-	return strings.TrimSpace(`
-Domain Name: EXAMPLE.COM
-Registrar: Example Registrar, Inc.
-Updated Date: 2024-01-15T12:00:00Z
-Creation Date: 1995-08-04T04:00:00Z
-Registry Expiry Date: 2025-08-03T04:00:00Z
-Name Server: NS1.EXAMPLE.COM
-Name Server: NS2.EXAMPLE.COM
-Domain Status: clientTransferProhibited
-`)
+func TestFormatter_OutputDNSMulti_JSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("json", buf)
+
+	resp := &dnsinfo.MultiResponse{
+		Domain:     "example.com.",
+		Nameserver: "8.8.8.8:53",
+		Results: []dnsinfo.TypeResult{
+			{
+				RecordType: "A",
+				Records:    []dnsinfo.Record{{Type: "A", Value: "93.184.216.34", TTL: 3600}},
+			},
+		},
+	}
+
+	err := f.OutputDNSMulti(resp)
+	if err != nil {
+		t.Fatalf("OutputDNSMulti failed: %v", err)
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse JSON output: %v", err)
+	}
+
+	results, ok := result["results"].([]interface{})
+	if !ok || len(results) != 1 {
+		t.Fatalf("expected 1 result in JSON output, got %v", result["results"])
+	}
+
+	first := results[0].(map[string]interface{})
+	if first["recordType"] != "A" {
+		t.Error("expected recordType in result")
+	}
+}
+
+func TestFormatter_OutputDNSSEC_Text(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("text", buf)
+
+	resp := &dnsinfo.DNSSECResponse{
+		Domain:     "example.com.",
+		RecordType: "A",
+		Nameserver: "8.8.8.8:53",
+		Status:     dnsinfo.DNSSECBogus,
+		Links: []dnsinfo.DNSSECLink{
+			{Zone: ".", Type: "DNSKEY", Status: dnsinfo.DNSSECSecure, Reason: "DNSKEY RRset signed by key 20326 matching a trusted DS record"},
+			{Zone: "com.", Type: "DNSKEY", Status: dnsinfo.DNSSECBogus, Reason: "no DNSKEY matches the DS records (key tags 19718)"},
+		},
+		Records: []dnsinfo.Record{
+			{Type: "A", Value: "93.184.216.34", TTL: 3600},
+		},
+	}
+
+	err := f.OutputDNSSEC(resp)
+	if err != nil {
+		t.Fatalf("OutputDNSSEC failed: %v", err)
+	}
+
+	output := buf.String()
+
+	if !strings.Contains(output, "DNSSEC Status: bogus") {
+		t.Error("expected output to contain overall status")
+	}
+
+	if !strings.Contains(output, "[secure]") || !strings.Contains(output, "[bogus]") {
+		t.Error("expected output to contain link statuses")
+	}
+
+	if !strings.Contains(output, "no DNSKEY matches the DS records") {
+		t.Error("expected output to contain link reason")
+	}
+}
+
+func TestFormatter_OutputDNSSEC_JSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("json", buf)
+
+	resp := &dnsinfo.DNSSECResponse{
+		Domain:     "example.com.",
+		RecordType: "A",
+		Status:     dnsinfo.DNSSECSecure,
+		Links: []dnsinfo.DNSSECLink{
+			{Zone: ".", Type: "DNSKEY", Status: dnsinfo.DNSSECSecure, KeyTags: []uint16{20326}},
+		},
+	}
+
+	err := f.OutputDNSSEC(resp)
+	if err != nil {
+		t.Fatalf("OutputDNSSEC failed: %v", err)
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse JSON output: %v", err)
+	}
+
+	if result["status"] != "secure" {
+		t.Error("expected status in JSON output")
+	}
+
+	links, ok := result["links"].([]interface{})
+	if !ok || len(links) != 1 {
+		t.Fatalf("expected 1 link in JSON output, got %v", result["links"])
+	}
+}
+
+func TestFormatter_OutputFCrDNS_Text(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("text", buf)
+
+	resp := &dnsinfo.FCrDNSResponse{
+		IP:          "192.0.2.10",
+		ReverseName: "10.2.0.192.in-addr.arpa.",
+		Nameserver:  "8.8.8.8:53",
+		Rcode:       "NOERROR",
+		PTR: []dnsinfo.Record{
+			{Type: "PTR", Value: "mail.example.com.", TTL: 300},
+			{Type: "PTR", Value: "old.example.com.", TTL: 300},
+		},
+		Hostnames: []dnsinfo.FCrDNSHostname{
+			{Hostname: "mail.example.com.", Addresses: []string{"192.0.2.10"}, Matched: true},
+			{Hostname: "old.example.com.", Addresses: []string{}, Error: "no A records found (NXDOMAIN)"},
+		},
+		Confirmed: true,
+	}
+
+	if err := f.OutputFCrDNS(resp); err != nil {
+		t.Fatalf("OutputFCrDNS failed: %v", err)
+	}
+
+	output := buf.String()
+
+	if !strings.Contains(output, "Reverse Name: 10.2.0.192.in-addr.arpa.") {
+		t.Error("expected output to contain reverse name")
+	}
+
+	if !strings.Contains(output, "mail.example.com. -> 192.0.2.10") || !strings.Contains(output, "[match]") {
+		t.Error("expected output to contain the matching hostname")
+	}
+
+	if !strings.Contains(output, "[no match]") || !strings.Contains(output, "error: no A records found") {
+		t.Error("expected output to contain the failed hostname")
+	}
+
+	if !strings.Contains(output, "FCrDNS: confirmed") {
+		t.Error("expected output to contain the overall result")
+	}
+}
+
+func TestFormatter_OutputFCrDNS_JSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("json", buf)
+
+	resp := &dnsinfo.FCrDNSResponse{
+		IP:          "192.0.2.10",
+		ReverseName: "10.2.0.192.in-addr.arpa.",
+		PTR:         []dnsinfo.Record{},
+		Hostnames:   []dnsinfo.FCrDNSHostname{},
+	}
+
+	if err := f.OutputFCrDNS(resp); err != nil {
+		t.Fatalf("OutputFCrDNS failed: %v", err)
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse JSON output: %v", err)
+	}
+
+	if result["reverseName"] != "10.2.0.192.in-addr.arpa." {
+		t.Error("expected reverseName in JSON output")
+	}
+
+	if result["confirmed"] != false {
+		t.Error("expected confirmed in JSON output")
+	}
+}
+
+func TestFormatter_OutputDNSCompare_Text(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("text", buf)
+
+	resp := &dnsinfo.CompareResponse{
+		Domain:     "example.com.",
+		RecordType: "A",
+		Consistent: false,
+		Results: []dnsinfo.CompareResult{
+			{Nameserver: "8.8.8.8:53", Rcode: "NOERROR", Records: []dnsinfo.Record{{Type: "A", Value: "192.0.2.2", TTL: 300}}, Agrees: true},
+			{Nameserver: "1.1.1.1:53", Rcode: "NOERROR", Records: []dnsinfo.Record{{Type: "A", Value: "192.0.2.2", TTL: 120}}, Agrees: true},
+			{Nameserver: "9.9.9.9:53", Rcode: "NOERROR", Records: []dnsinfo.Record{{Type: "A", Value: "192.0.2.1", TTL: 3600}}},
+			{Nameserver: "208.67.222.222:53", Records: []dnsinfo.Record{}, Error: "i/o timeout"},
+		},
+		Answers: []dnsinfo.CompareAnswer{
+			{Rcode: "NOERROR", Values: []string{"A 192.0.2.2"}, Nameservers: []string{"8.8.8.8:53", "1.1.1.1:53"}},
+			{Rcode: "NOERROR", Values: []string{"A 192.0.2.1"}, Nameservers: []string{"9.9.9.9:53"}},
+		},
+	}
+
+	if err := f.OutputDNSCompare(resp); err != nil {
+		t.Fatalf("OutputDNSCompare failed: %v", err)
+	}
+
+	output := buf.String()
+
+	if !strings.Contains(output, "Consistency: INCONSISTENT (2 distinct answers)") {
+		t.Error("expected output to contain the consistency verdict")
+	}
+
+	if !strings.Contains(output, "! 9.9.9.9:53") {
+		t.Error("expected the disagreeing resolver to be marked")
+	}
+
+	if strings.Contains(output, "! 8.8.8.8:53") {
+		t.Error("expected the agreeing resolver not to be marked")
+	}
+
+	if !strings.Contains(output, "TTL: 3600") {
+		t.Error("expected output to contain TTLs")
+	}
+
+	if !strings.Contains(output, "error: i/o timeout") {
+		t.Error("expected output to contain the resolver error")
+	}
+
+	if !strings.Contains(output, "served by 8.8.8.8:53, 1.1.1.1:53") {
+		t.Error("expected output to group the resolvers by answer")
+	}
+}
+
+func TestFormatter_OutputDNSCompare_JSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("json", buf)
+
+	resp := &dnsinfo.CompareResponse{
+		Domain:     "example.com.",
+		RecordType: "A",
+		Consistent: true,
+		Results: []dnsinfo.CompareResult{
+			{Nameserver: "8.8.8.8:53", Rcode: "NOERROR", Records: []dnsinfo.Record{}, Agrees: true},
+		},
+	}
+
+	if err := f.OutputDNSCompare(resp); err != nil {
+		t.Fatalf("OutputDNSCompare failed: %v", err)
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse JSON output: %v", err)
+	}
+
+	if result["consistent"] != true {
+		t.Error("expected consistency verdict in JSON output")
+	}
+
+	results, ok := result["results"].([]interface{})
+	if !ok || len(results) != 1 {
+		t.Fatalf("expected 1 result in JSON output, got %v", result["results"])
+	}
+}
+
+func TestFormatter_OutputNSAudit_Text(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("text", buf)
+
+	resp := &dnsinfo.NSAuditResponse{
+		Zone:        "example.com.",
+		RecordType:  "A",
+		Nameserver:  "8.8.8.8:53",
+		Nameservers: []string{"ns1.example.com.", "ns2.example.com.", "ns3.example.com."},
+		Serial:      2024010102,
+		Servers: []dnsinfo.NSAuditServer{
+			{Nameserver: "ns1.example.com.", Address: "192.0.2.53:53", Family: "IPv4", Status: dnsinfo.AuditOK, Serial: 2024010102, Records: []dnsinfo.Record{{Type: "A", Value: "192.0.2.1"}}},
+			{Nameserver: "ns2.example.com.", Address: "[2001:db8::53]:53", Family: "IPv6", Status: dnsinfo.AuditOutOfSync, Serial: 2024010101, Issues: []string{"SOA serial 2024010101 is behind 2024010102"}},
+			{Nameserver: "ns3.example.com.", Address: "192.0.2.54:53", Family: "IPv4", Status: dnsinfo.AuditLame, Error: "not authoritative for example.com. (REFUSED, aa=false)"},
+			{Nameserver: "ns4.example.com.", Status: dnsinfo.AuditUnreachable, Error: "no A or AAAA records found"},
+		},
+	}
+
+	if err := f.OutputNSAudit(resp); err != nil {
+		t.Fatalf("OutputNSAudit failed: %v", err)
+	}
+
+	output := buf.String()
+
+	for _, expected := range []string{
+		"SOA Serial: 2024010102",
+		"Consistency: INCONSISTENT",
+		"[ok]",
+		"[out-of-sync]",
+		"- SOA serial 2024010101 is behind 2024010102",
+		"[lame]",
+		"[unreachable]",
+		"no A or AAAA records found",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain %q", expected)
+		}
+	}
+}
+
+func TestFormatter_OutputNSAudit_JSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("json", buf)
+
+	resp := &dnsinfo.NSAuditResponse{
+		Zone:       "example.com.",
+		Serial:     2024010102,
+		Consistent: true,
+		Servers: []dnsinfo.NSAuditServer{
+			{Nameserver: "ns1.example.com.", Status: dnsinfo.AuditOK},
+		},
+	}
+
+	if err := f.OutputNSAudit(resp); err != nil {
+		t.Fatalf("OutputNSAudit failed: %v", err)
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse JSON output: %v", err)
+	}
+
+	if result["consistent"] != true || result["serial"] != float64(2024010102) {
+		t.Errorf("unexpected JSON output: %v", result)
+	}
+}
+
+func TestFormatter_OutputDNSTransfer_Text(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("text", buf)
+
+	resp := &dnsinfo.TransferResponse{
+		Zone:        "example.com.",
+		Type:        "AXFR",
+		Nameserver:  "8.8.8.8:53",
+		Nameservers: []string{"ns1.example.com.", "ns2.example.com."},
+		Transferred: 1,
+		Servers: []dnsinfo.TransferServer{
+			{
+				Nameserver:  "ns1.example.com.",
+				Address:     "192.0.2.53:53",
+				Family:      "IPv4",
+				Status:      dnsinfo.TransferOK,
+				Serial:      2024010101,
+				RecordCount: 2,
+			},
+			{Nameserver: "ns2.example.com.", Address: "192.0.2.54:53", Family: "IPv4", Status: dnsinfo.TransferRefused, Rcode: "REFUSED", Error: "dns: bad xfr rcode: 5"},
+		},
+		Source: "192.0.2.53:53",
+	}
+
+	handler := f.DNSTransferRecords()
+	for _, records := range [][]dnsinfo.Record{
+		{{Name: "example.com.", Type: "SOA", Value: "ns1.example.com. hostmaster.example.com. 2024010101 7200 3600 1209600 300", TTL: 3600}},
+		{{Name: "example.com.", Type: "TXT", Value: "v=spf1 -all", TTL: 300, Data: &dnsinfo.TXTData{Strings: []string{"v=spf1 -all"}}}},
+	} {
+		if err := handler(&resp.Servers[0], records); err != nil {
+			t.Fatalf("DNSTransferRecords failed: %v", err)
+		}
+	}
+
+	if err := f.OutputDNSTransfer(resp); err != nil {
+		t.Fatalf("OutputDNSTransfer failed: %v", err)
+	}
+
+	output := buf.String()
+
+	for _, expected := range []string{
+		"; Transfer: AXFR",
+		"serial 2024010101  2 records",
+		"[transferred]",
+		"[refused]",
+		"WARNING: 1 of 2 servers allow zone transfers without authentication",
+		"; Records from ns1.example.com. (192.0.2.53:53):",
+		"example.com.\t3600\tIN\tSOA\tns1.example.com. hostmaster.example.com. 2024010101",
+		"example.com.\t300\tIN\tTXT\t\"v=spf1 -all\"",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain %q", expected)
+		}
+	}
+
+	if strings.Count(output, "; Records from") != 1 || strings.Index(output, "; Records from") > strings.Index(output, "; Zone:") {
+		t.Errorf("expected the records to be streamed before the summary, got:\n%s", output)
+	}
+}
+
+func TestFormatter_OutputDNSTransfer_Refused(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("text", buf)
+
+	resp := &dnsinfo.TransferResponse{
+		Zone:    "example.com.",
+		Type:    "IXFR",
+		Serial:  2024010100,
+		TSIGKey: "transfer.key.",
+		Servers: []dnsinfo.TransferServer{
+			{Nameserver: "ns1.example.com.", Address: "192.0.2.53:53", Family: "IPv4", Status: dnsinfo.TransferRefused, Error: "dns: bad xfr rcode: 9"},
+		},
+	}
+
+	if err := f.OutputDNSTransfer(resp); err != nil {
+		t.Fatalf("OutputDNSTransfer failed: %v", err)
+	}
+
+	output := buf.String()
+
+	for _, expected := range []string{
+		"; Transfer: IXFR (from serial 2024010100)",
+		"; TSIG Key: transfer.key.",
+		"No server allowed the zone transfer",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain %q", expected)
+		}
+	}
+
+	if strings.Contains(output, "WARNING") {
+		t.Error("expected no warning when no server allowed the transfer")
+	}
+}
+
+func TestFormatter_OutputDNSTransfer_JSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("json", buf)
+
+	resp := &dnsinfo.TransferResponse{
+		Zone:        "example.com.",
+		Type:        "AXFR",
+		Transferred: 1,
+		Servers: []dnsinfo.TransferServer{
+			{Nameserver: "ns1.example.com.", Status: dnsinfo.TransferOK, RecordCount: 1},
+		},
+	}
+
+	err := f.DNSTransferRecords()(&resp.Servers[0], []dnsinfo.Record{{Name: "www.example.com.", Type: "A", Value: "192.0.2.1"}})
+	if err != nil {
+		t.Fatalf("DNSTransferRecords failed: %v", err)
+	}
+	if err := f.OutputDNSTransfer(resp); err != nil {
+		t.Fatalf("OutputDNSTransfer failed: %v", err)
+	}
+
+	decoder := json.NewDecoder(buf)
+
+	var record map[string]interface{}
+	if err := decoder.Decode(&record); err != nil {
+		t.Fatalf("failed to parse the streamed record: %v", err)
+	}
+	if record["name"] != "www.example.com." {
+		t.Errorf("unexpected record: %v", record)
+	}
+
+	var result map[string]interface{}
+	if err := decoder.Decode(&result); err != nil {
+		t.Fatalf("failed to parse JSON output: %v", err)
+	}
+
+	if result["type"] != "AXFR" || result["transferred"] != float64(1) {
+		t.Errorf("unexpected JSON output: %v", result)
+	}
+}
+
+func TestFormatter_OutputDelegation_Text(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("text", buf)
+
+	resp := &dnsinfo.DelegationResponse{
+		Domain:        "example.com.",
+		ParentZone:    "com.",
+		ParentServer:  "a.gtld-servers.net.",
+		ParentAddress: "192.5.6.30:53",
+		ChildServer:   "192.0.2.53:53",
+		ParentNS:      []string{"ns1.example.com."},
+		ChildNS:       []string{"ns1.example.com."},
+		Nameservers: []dnsinfo.DelegationNameserver{
+			{
+				Name:        "ns1.example.com.",
+				InParent:    true,
+				InChild:     true,
+				InBailiwick: true,
+				Glue:        []string{"192.0.2.99"},
+				Addresses:   []string{"192.0.2.53"},
+			},
+		},
+		Issues: []string{"stale glue for ns1.example.com.: 192.0.2.99 is not served by the child zone"},
+	}
+
+	if err := f.OutputDelegation(resp); err != nil {
+		t.Fatalf("OutputDelegation failed: %v", err)
+	}
+
+	output := buf.String()
+
+	for _, expected := range []string{
+		"Parent Zone: com.",
+		"Delegation: INCONSISTENT (1 issues)",
+		"ns1.example.com. [parent, child]",
+		"Glue: 192.0.2.99",
+		"Addresses: 192.0.2.53",
+		"- stale glue for ns1.example.com.",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain %q", expected)
+		}
+	}
+}
+
+func TestFormatter_OutputDelegation_JSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("json", buf)
+
+	resp := &dnsinfo.DelegationResponse{
+		Domain:     "example.com.",
+		ParentZone: "com.",
+		Consistent: true,
+		Issues:     []string{},
+	}
+
+	if err := f.OutputDelegation(resp); err != nil {
+		t.Fatalf("OutputDelegation failed: %v", err)
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse JSON output: %v", err)
+	}
+
+	if result["parentZone"] != "com." || result["consistent"] != true {
+		t.Errorf("unexpected JSON output: %v", result)
+	}
+}
+
+func TestFormatter_OutputDNSTrace_Text(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("text", buf)
+
+	resp := &dnsinfo.TraceResponse{
+		Domain:     "www.example.com.",
+		RecordType: "A",
+		Hops: []dnsinfo.TraceHop{
+			{
+				Name:        "www.example.com.",
+				Zone:        ".",
+				Server:      "a.root-servers.net",
+				Address:     "198.41.0.4:53",
+				Referral:    "com.",
+				Nameservers: []string{"a.gtld-servers.net."},
+				Glue:        []dnsinfo.Record{{Name: "a.gtld-servers.net.", Type: "A", Value: "192.5.6.30"}},
+			},
+			{
+				Name:          "www.example.com.",
+				Zone:          "com.",
+				Server:        "a.gtld-servers.net.",
+				Address:       "192.5.6.30:53",
+				Authoritative: true,
+				Answer:        []dnsinfo.Record{{Name: "www.example.com.", Type: "A", Value: "93.184.216.34", TTL: 300}},
+			},
+		},
+		Records: []dnsinfo.Record{{Name: "www.example.com.", Type: "A", Value: "93.184.216.34", TTL: 300}},
+	}
+
+	err := f.OutputDNSTrace(resp)
+	if err != nil {
+		t.Fatalf("OutputDNSTrace failed: %v", err)
+	}
+
+	output := buf.String()
+
+	if !strings.Contains(output, ". via a.root-servers.net (198.41.0.4:53)") {
+		t.Error("expected output to contain the root hop")
+	}
+
+	if !strings.Contains(output, "└── referral to com. [a.gtld-servers.net.]") {
+		t.Error("expected output to contain the referral")
+	}
+
+	if !strings.Contains(output, "glue: a.gtld-servers.net. A 192.5.6.30") {
+		t.Error("expected output to contain glue")
+	}
+
+	if !strings.Contains(output, "      com. via a.gtld-servers.net. (192.5.6.30:53) 0s [authoritative]") {
+		t.Error("expected the TLD hop to be nested under the root hop")
+	}
+
+	if !strings.Contains(output, "answer: www.example.com. A 93.184.216.34") {
+		t.Error("expected output to contain the answer")
+	}
+}
+
+func TestFormatter_OutputDNSTrace_JSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("json", buf)
+
+	resp := &dnsinfo.TraceResponse{
+		Domain:     "www.example.com.",
+		RecordType: "A",
+		Hops: []dnsinfo.TraceHop{
+			{Name: "www.example.com.", Zone: ".", Server: "a.root-servers.net", Referral: "com."},
+		},
+	}
+
+	err := f.OutputDNSTrace(resp)
+	if err != nil {
+		t.Fatalf("OutputDNSTrace failed: %v", err)
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse JSON output: %v", err)
+	}
+
+	hops, ok := result["hops"].([]interface{})
+	if !ok || len(hops) != 1 {
+		t.Fatalf("expected 1 hop in JSON output, got %v", result["hops"])
+	}
+}
+
+func TestFormatter_OutputTLSScan_Text(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("text", buf)
+
+	result := &tlsinfo.TestResult{
+		Host: "example.com",
+		Port: "443",
+		SupportedVersions: map[string]bool{
+			"TLS 1.3": true,
+			"TLS 1.2": true,
+			"TLS 1.1": false,
+			"TLS 1.0": false,
+		},
+		CipherSuites: map[string][]string{
+			"TLS 1.3": {"TLS_AES_256_GCM_SHA384", "TLS_CHACHA20_POLY1305_SHA256"},
+			"TLS 1.2": {"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+		},
+		PreferredVersion: "TLS 1.3",
+		PreferredCipher:  "TLS_AES_256_GCM_SHA384",
+		Vulnerabilities:  []string{},
+	}
+
+	err := f.OutputTLSScan(result)
+	if err != nil {
+		t.Fatalf("OutputTLSScan failed: %v", err)
+	}
+
+	output := buf.String()
+
+	if !strings.Contains(output, "example.com") {
+		t.Error("expected output to contain host")
+	}
+
+	if !strings.Contains(output, "Supported TLS Versions") {
+		t.Error("expected output to contain 'Supported TLS Versions'")
+	}
+
+	if !strings.Contains(output, "TLS 1.3: Yes") {
+		t.Error("expected output to show TLS 1.3 as supported")
+	}
+
+	if !strings.Contains(output, "TLS 1.2: Yes") {
+		t.Error("expected output to show TLS 1.2 as supported")
+	}
+
+	if !strings.Contains(output, "TLS 1.0: No") {
+		t.Error("expected output to show TLS 1.0 as not supported")
+	}
+
+	if !strings.Contains(output, "Supported Cipher Suites") {
+		t.Error("expected output to contain 'Supported Cipher Suites'")
+	}
+
+	if !strings.Contains(output, "TLS_AES_256_GCM_SHA384") {
+		t.Error("expected output to contain cipher suite")
+	}
+
+	if !strings.Contains(output, "Preferred Version: TLS 1.3") {
+		t.Error("expected output to contain preferred version")
+	}
+
+	if !strings.Contains(output, "Preferred Cipher: TLS_AES_256_GCM_SHA384") {
+		t.Error("expected output to contain preferred cipher")
+	}
+}
+
+func TestFormatter_OutputTLSScan_JSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("json", buf)
+
+	result := &tlsinfo.TestResult{
+		Host: "example.com",
+		Port: "443",
+		SupportedVersions: map[string]bool{
+			"TLS 1.3": true,
+			"TLS 1.2": true,
+			"TLS 1.1": false,
+			"TLS 1.0": false,
+		},
+		PreferredVersion: "TLS 1.3",
+	}
+
+	err := f.OutputTLSScan(result)
+	if err != nil {
+		t.Fatalf("OutputTLSScan failed: %v", err)
+	}
+
+	var jsonResult map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &jsonResult); err != nil {
+		t.Fatalf("failed to parse JSON output: %v", err)
+	}
+
+	if jsonResult["host"] != "example.com" {
+		t.Error("expected host in JSON output")
+	}
+
+	if jsonResult["port"] != "443" {
+		t.Error("expected port in JSON output")
+	}
+
+	if jsonResult["preferredVersion"] != "TLS 1.3" {
+		t.Error("expected preferredVersion in JSON output")
+	}
+}
+
+func TestFormatter_OutputTLSScan_WithVulnerabilities(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("text", buf)
+
+	result := &tlsinfo.TestResult{
+		Host: "example.com",
+		Port: "443",
+		SupportedVersions: map[string]bool{
+			"TLS 1.3": true,
+			"TLS 1.2": true,
+			"TLS 1.1": true,
+			"TLS 1.0": true,
+		},
+		PreferredVersion: "TLS 1.3",
+		Vulnerabilities:  []string{"Server supports deprecated TLS 1.0", "Server supports deprecated TLS 1.1"},
+	}
+
+	err := f.OutputTLSScan(result)
+	if err != nil {
+		t.Fatalf("OutputTLSScan failed: %v", err)
+	}
+
+	output := buf.String()
+
+	if !strings.Contains(output, "Security Warnings") {
+		t.Error("expected output to contain 'Security Warnings'")
+	}
+
+	if !strings.Contains(output, "Server supports deprecated TLS 1.0") {
+		t.Error("expected output to contain TLS 1.0 warning")
+	}
+
+	if !strings.Contains(output, "Server supports deprecated TLS 1.1") {
+		t.Error("expected output to contain TLS 1.1 warning")
+	}
+}
+
+func sampleWHOISRecord() string {
+	return strings.TrimSpace(`
+Domain Name: EXAMPLE.COM
+Registrar: Example Registrar, Inc.
+Updated Date: 2024-01-15T12:00:00Z
+Creation Date: 1995-08-04T04:00:00Z
+Registry Expiry Date: 2025-08-03T04:00:00Z
+Name Server: NS1.EXAMPLE.COM
+Name Server: NS2.EXAMPLE.COM
+Domain Status: clientTransferProhibited
+`)
+}
+
+func TestFormatter_OutputMail_Text(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("text", buf)
+
+	resp := &mailinfo.Response{
+		Domain: "example.com",
+		Status: mailinfo.StatusFail,
+		SPF: &mailinfo.SPFResult{
+			Status:   mailinfo.StatusPass,
+			Domain:   "example.com",
+			Record:   "v=spf1 include:_spf.example.net -all",
+			Lookups:  3,
+			Includes: []mailinfo.SPFResult{{Status: mailinfo.StatusPass, Domain: "_spf.example.net"}},
+		},
+		DMARC: &mailinfo.DMARCResult{
+			Status:  mailinfo.StatusWarn,
+			Record:  "v=DMARC1; p=none; rua=mailto:dmarc@example.com",
+			Policy:  "none",
+			Percent: 100,
+			ADKIM:   "r",
+			ASPF:    "r",
+			RUA:     []string{"mailto:dmarc@example.com"},
+			Issues:  []string{"policy \"none\" only monitors, failing messages are still delivered"},
+		},
+		DKIM: &mailinfo.DKIMResult{
+			Status: mailinfo.StatusFail,
+			Selectors: []mailinfo.DKIMSelector{
+				{Selector: "selector1", Status: mailinfo.StatusPass, KeyType: "rsa", KeyBits: 2048},
+				{Selector: "old", Status: mailinfo.StatusFail, KeyType: "rsa", Issues: []string{"key has been revoked (empty p tag)"}},
+			},
+		},
+		MTASTS: &mailinfo.MTASTSResult{Status: mailinfo.StatusPass, Record: "v=STSv1; id=1", Mode: "enforce", MaxAge: 604800, MX: []string{"*.mail.example.com"}},
+		TLSRPT: &mailinfo.TLSRPTResult{Status: mailinfo.StatusWarn, Issues: []string{"no TLS-RPT record found, SMTP TLS failures are not reported"}},
+		BIMI:   &mailinfo.BIMIResult{Status: mailinfo.StatusWarn, Issues: []string{"no BIMI record found"}},
+	}
+
+	if err := f.OutputMail(resp); err != nil {
+		t.Fatalf("OutputMail failed: %v", err)
+	}
+
+	output := buf.String()
+
+	for _, expected := range []string{
+		"Status: fail",
+		"SPF [pass]",
+		"DNS Lookups: 3/10",
+		"Includes: _spf.example.net (pass)",
+		"DMARC [warn]",
+		"Policy: none (pct 100, adkim r, aspf r)",
+		"Aggregate Reports: mailto:dmarc@example.com",
+		"[pass]   selector1 (rsa, 2048 bits)",
+		"    - key has been revoked",
+		"Policy: enforce (max_age 604800, mx *.mail.example.com)",
+		"TLS-RPT [warn]",
+		"BIMI [warn]",
+		"  - no BIMI record found",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+}
+
+func TestFormatter_OutputMail_JSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("json", buf)
+
+	resp := &mailinfo.Response{
+		Domain: "example.com",
+		Status: mailinfo.StatusPass,
+		SPF:    &mailinfo.SPFResult{Status: mailinfo.StatusPass, Domain: "example.com", Lookups: 1},
+	}
+
+	if err := f.OutputMail(resp); err != nil {
+		t.Fatalf("OutputMail failed: %v", err)
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse JSON output: %v", err)
+	}
+
+	spf, ok := result["spf"].(map[string]interface{})
+	if !ok || spf["status"] != "pass" {
+		t.Errorf("expected spf status in JSON output, got %v", result["spf"])
+	}
+}
+
+func TestFormatter_OutputTakeover_Text(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("text", buf)
+
+	resp := &takeover.Response{
+		Checked:    3,
+		Vulnerable: 1,
+		Results: []takeover.Result{
+			{
+				Name:     "docs.example.com",
+				Status:   takeover.StatusVulnerable,
+				CNAMEs:   []string{"example.github.io."},
+				Service:  "GitHub Pages",
+				Evidence: "GitHub Pages answered with its unclaimed page: \"There isn't a GitHub Pages site here.\"",
+			},
+			{Name: "www.example.com", Status: takeover.StatusOK},
+			{Name: "old.example.com", Status: takeover.StatusError, Error: "i/o timeout"},
+		},
+	}
+
+	if err := f.OutputTakeover(resp); err != nil {
+		t.Fatalf("OutputTakeover failed: %v", err)
+	}
+
+	output := buf.String()
+
+	for _, expected := range []string{
+		"Vulnerable: 1",
+		"[vulnerable]  docs.example.com  -> example.github.io. (GitHub Pages)",
+		"unclaimed page",
+		"[ok]",
+		"error: i/o timeout",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain %q", expected)
+		}
+	}
+}
+
+func TestFormatter_OutputTakeover_JSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("json", buf)
+
+	resp := &takeover.Response{
+		Checked:    1,
+		Vulnerable: 1,
+		Results:    []takeover.Result{{Name: "app.example.com", Status: takeover.StatusVulnerable, Service: "Heroku"}},
+	}
+
+	if err := f.OutputTakeover(resp); err != nil {
+		t.Fatalf("OutputTakeover failed: %v", err)
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse JSON output: %v", err)
+	}
+
+	if result["vulnerable"] != float64(1) {
+		t.Errorf("unexpected JSON output: %v", result)
+	}
+}
+
+func TestFormatter_OutputDNS_Wildcard(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("text", buf)
+
+	resp := &dnsinfo.Response{
+		Domain:     "typo.example.com.",
+		RecordType: "A",
+		Records:    []dnsinfo.Record{{Type: "A", Value: "192.0.2.99", TTL: 300, Wildcard: true}},
+		Wildcard: &dnsinfo.Wildcard{
+			Zone:     "example.com.",
+			Detected: true,
+			Records:  []dnsinfo.Record{{Type: "A", Value: "192.0.2.99", TTL: 300}},
+		},
+	}
+
+	if err := f.OutputDNS(resp); err != nil {
+		t.Fatalf("OutputDNS failed: %v", err)
+	}
+
+	output := buf.String()
+
+	for _, expected := range []string{
+		"TTL: 300  (wildcard)",
+		"Wildcard: *.example.com. answers with A 192.0.2.99",
+		"WARNING: 1 of 1 records match the wildcard answer",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+}
+
+func newTestEnumResponse() *dnsinfo.EnumResponse {
+	return &dnsinfo.EnumResponse{
+		Zone:       "example.com.",
+		RecordType: "A",
+		Candidates: 4,
+		Found:      2,
+		Filtered:   1,
+		Failed:     1,
+		Wildcard: &dnsinfo.Wildcard{
+			Zone:     "example.com.",
+			Detected: true,
+			Records:  []dnsinfo.Record{{Type: "A", Value: "192.0.2.99"}},
+		},
+		Results: []dnsinfo.EnumResult{
+			{Name: "down.example.com.", Error: "i/o timeout"},
+			{Name: "mail.example.com.", Rcode: "NOERROR"},
+			{Name: "www.example.com.", Rcode: "NOERROR", Records: []dnsinfo.Record{{Type: "A", Value: "192.0.2.10"}, {Type: "A", Value: "192.0.2.11"}}},
+		},
+	}
+}
+
+func TestFormatter_OutputDNSEnum_Text(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("text", buf)
+
+	if err := f.OutputDNSEnum(newTestEnumResponse()); err != nil {
+		t.Fatalf("OutputDNSEnum failed: %v", err)
+	}
+
+	output := buf.String()
+
+	for _, expected := range []string{
+		"Candidates: 4, found: 2, wildcard matches: 1, failed: 1",
+		"Wildcard: *.example.com. answers with A 192.0.2.99",
+		"NAME",
+		"www.example.com.                          NOERROR   192.0.2.10, 192.0.2.11",
+		"down.example.com.                         ERROR     i/o timeout",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+}
+
+func TestFormatter_OutputDNSEnum_NDJSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("ndjson", buf)
+
+	if err := f.OutputDNSEnum(newTestEnumResponse()); err != nil {
+		t.Fatalf("OutputDNSEnum failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected one line per result, got %d:\n%s", len(lines), buf.String())
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[2]), &result); err != nil {
+		t.Fatalf("failed to parse NDJSON line: %v", err)
+	}
+	if result["name"] != "www.example.com." {
+		t.Errorf("unexpected NDJSON line: %v", result)
+	}
+}
+
+func TestFormatter_OutputDNSEnum_JSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("json", buf)
+
+	if err := f.OutputDNSEnum(newTestEnumResponse()); err != nil {
+		t.Fatalf("OutputDNSEnum failed: %v", err)
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse JSON output: %v", err)
+	}
+
+	if result["found"] != float64(2) || result["zone"] != "example.com." {
+		t.Errorf("unexpected JSON output: %v", result)
+	}
+}
+
+func TestFormatter_OutputDNSBench_Text(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("text", buf)
+
+	resp := &dnsinfo.BenchResponse{
+		Domain:      "example.com.",
+		RecordType:  "A",
+		Count:       100,
+		Concurrency: 10,
+		Results: []dnsinfo.BenchResult{
+			{
+				Nameserver: "192.0.2.53:53",
+				Cached:     dnsinfo.BenchStats{Queries: 100, Min: 1200 * time.Microsecond, Median: 2 * time.Millisecond, P95: 5 * time.Millisecond, P99: 9 * time.Millisecond, Max: 12 * time.Millisecond},
+				Uncached:   dnsinfo.BenchStats{Queries: 100, Timeouts: 3, Errors: 1, ErrorRate: 0.04, Median: 40 * time.Millisecond},
+			},
+		},
+	}
+
+	if err := f.OutputDNSBench(resp); err != nil {
+		t.Fatalf("OutputDNSBench failed: %v", err)
+	}
+
+	output := buf.String()
+
+	for _, expected := range []string{
+		"Queries: 100 cached and 100 uncached per resolver, concurrency 10",
+		"Cached:",
+		"Uncached:",
+		"192.0.2.53:53",
+		"1.2ms",
+		"0.0% (0 timeouts, 0 errors)",
+		"4.0% (3 timeouts, 1 errors)",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+}
+
+func TestFormatter_OutputDNSBench_JSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("json", buf)
+
+	resp := &dnsinfo.BenchResponse{
+		Domain:  "example.com.",
+		Count:   10,
+		Results: []dnsinfo.BenchResult{{Nameserver: "192.0.2.53:53", Cached: dnsinfo.BenchStats{Queries: 10, ErrorRate: 0.1}}},
+	}
+
+	if err := f.OutputDNSBench(resp); err != nil {
+		t.Fatalf("OutputDNSBench failed: %v", err)
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse JSON output: %v", err)
+	}
+
+	results := result["results"].([]interface{})
+	cached := results[0].(map[string]interface{})["cached"].(map[string]interface{})
+	if cached["errorRate"] != 0.1 {
+		t.Errorf("unexpected JSON output: %v", result)
+	}
+}
+
+func TestFormatter_OutputCAA_Text(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("text", buf)
+
+	resp := &caa.Response{
+		Host:          "example.com",
+		Port:          "443",
+		Issuer:        "DigiCert Inc (DigiCert Global G2 TLS RSA SHA256 2020 CA1)",
+		IssuerDomains: []string{"digicert.com"},
+		Status:        caa.StatusDenied,
+		Names: []caa.NameResult{
+			{
+				Name:       "example.com",
+				Owner:      "example.com",
+				Properties: []caa.Property{{Tag: "issue", Value: "letsencrypt.org", Issuer: "letsencrypt.org"}},
+				Authorized: []string{"letsencrypt.org"},
+				Status:     caa.StatusDenied,
+				Reason:     "the issuing CA is not authorized, issue at example.com authorizes letsencrypt.org",
+			},
+		},
+	}
+
+	if err := f.OutputCAA(resp); err != nil {
+		t.Fatalf("OutputCAA failed: %v", err)
+	}
+
+	output := buf.String()
+
+	for _, expected := range []string{
+		"Issuer Domains: digicert.com",
+		"Status: denied",
+		"[denied]      example.com",
+		"issue at example.com authorizes letsencrypt.org",
+		`CAA 0 issue "letsencrypt.org"`,
+		"WARNING: renewing the certificate",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+}
+
+func TestFormatter_OutputCAA_JSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("json", buf)
+
+	resp := &caa.Response{
+		Host:   "example.com",
+		Port:   "443",
+		Status: caa.StatusPermitted,
+		Names:  []caa.NameResult{{Name: "example.com", Status: caa.StatusPermitted}},
+	}
+
+	if err := f.OutputCAA(resp); err != nil {
+		t.Fatalf("OutputCAA failed: %v", err)
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse JSON output: %v", err)
+	}
+
+	if result["status"] != "permitted" {
+		t.Errorf("unexpected JSON output: %v", result)
+	}
+}
+
+func TestFormatter_OutputDANE_Text(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("text", buf)
+
+	resp := &dane.Response{
+		Host:     "mx.example.com",
+		Port:     "25",
+		TLSAName: "_25._tcp.mx.example.com.",
+		DNSSEC:   "secure",
+		Status:   dane.StatusNoMatch,
+		Chain:    []dane.ChainCert{{Subject: "CN=mx.example.com", Issuer: "CN=R11,O=Let's Encrypt,C=US", SHA256: "aa11", SPKISHA256: "bb22"}},
+		Records: []dane.RecordResult{
+			{Usage: 3, Selector: 1, MatchingType: 1, Data: "cc33", Description: "DANE-EE(3) SPKI(1) SHA2-256(1)", Certificate: -1, Reason: "no certificate in the chain matches (the end-entity certificate was compared)"},
+		},
+	}
+
+	if err := f.OutputDANE(resp); err != nil {
+		t.Fatalf("OutputDANE failed: %v", err)
+	}
+
+	output := buf.String()
+
+	for _, expected := range []string{
+		"TLSA Name: _25._tcp.mx.example.com.",
+		"DNSSEC: secure",
+		"Status: no-match",
+		"SPKI SHA-256: bb22",
+		"[no match]  3 1 1 cc33",
+		"DANE-EE(3) SPKI(1) SHA2-256(1): no certificate in the chain matches",
+		"WARNING: no TLSA record matches",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+}
+
+func TestFormatter_OutputDANE_JSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("json", buf)
+
+	resp := &dane.Response{
+		Host:    "mx.example.com",
+		Port:    "25",
+		Status:  dane.StatusMatched,
+		Records: []dane.RecordResult{{Usage: 3, Selector: 1, MatchingType: 1, Matched: true}},
+	}
+
+	if err := f.OutputDANE(resp); err != nil {
+		t.Fatalf("OutputDANE failed: %v", err)
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse JSON output: %v", err)
+	}
+
+	if result["status"] != "matched" {
+		t.Errorf("unexpected JSON output: %v", result)
+	}
+}
+
+func TestFormatter_OutputDNS_IDN(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("text", buf)
+
+	resp := &dnsinfo.Response{
+		Domain:     "xn--pypal-4ve.com.",
+		RecordType: "A",
+		IDN: &idn.Name{
+			ASCII:    "xn--pypal-4ve.com.",
+			Unicode:  "pаypal.com.",
+			Warnings: []string{`label "pаypal" mixes scripts: Cyrillic, Latin`},
+		},
+		Records: []dnsinfo.Record{},
+	}
+
+	if err := f.OutputDNS(resp); err != nil {
+		t.Fatalf("OutputDNS failed: %v", err)
+	}
+
+	output := buf.String()
+	for _, expected := range []string{
+		"Domain: xn--pypal-4ve.com.",
+		"Unicode: pаypal.com.",
+		"WARNING: label \"pаypal\" mixes scripts",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+}
+
+func TestFormatter_OutputMail_IDN(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("text", buf)
+
+	resp := &mailinfo.Response{
+		Domain: "xn--bcher-kva.de",
+		IDN:    &idn.Name{ASCII: "xn--bcher-kva.de", Unicode: "bücher.de"},
+		Status: mailinfo.StatusPass,
+	}
+
+	if err := f.OutputMail(resp); err != nil {
+		t.Fatalf("OutputMail failed: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "Unicode: bücher.de") {
+		t.Errorf("expected the U-label in the output, got:\n%s", buf.String())
+	}
+}
+
+func TestFormatter_OutputTakeover_IDN(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("text", buf)
+
+	resp := &takeover.Response{
+		Checked: 1,
+		Results: []takeover.Result{
+			{
+				Name:   "xn--pypal-4ve.example.com",
+				Status: takeover.StatusOK,
+				IDN: &idn.Name{
+					ASCII:    "xn--pypal-4ve.example.com",
+					Unicode:  "pаypal.example.com",
+					Warnings: []string{`label "pаypal" mixes scripts: Cyrillic, Latin`},
+				},
+			},
+		},
+	}
+
+	if err := f.OutputTakeover(resp); err != nil {
+		t.Fatalf("OutputTakeover failed: %v", err)
+	}
+
+	output := buf.String()
+	for _, expected := range []string{
+		"unicode: pаypal.example.com",
+		"WARNING: label \"pаypal\" mixes scripts",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+}
+
+func TestFormatter_OutputDNSTransfer_IDN(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("text", buf)
+
+	resp := &dnsinfo.TransferResponse{
+		Zone: "xn--bcher-kva.de.",
+		IDN:  &idn.Name{ASCII: "xn--bcher-kva.de.", Unicode: "bücher.de."},
+		Type: "AXFR",
+	}
+
+	if err := f.OutputDNSTransfer(resp); err != nil {
+		t.Fatalf("OutputDNSTransfer failed: %v", err)
+	}
+
+	// The header stays a zone file comment.
+	if !strings.Contains(buf.String(), "; Unicode: bücher.de.") {
+		t.Errorf("expected the U-label in the output, got:\n%s", buf.String())
+	}
+}
+
+func TestFormatter_OutputDNSSEC_IDN(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter("text", buf)
+
+	resp := &dnsinfo.DNSSECResponse{
+		Domain:     "xn--bcher-kva.de.",
+		IDN:        &idn.Name{ASCII: "xn--bcher-kva.de.", Unicode: "bücher.de."},
+		RecordType: "A",
+		Status:     dnsinfo.DNSSECSecure,
+	}
+
+	if err := f.OutputDNSSEC(resp); err != nil {
+		t.Fatalf("OutputDNSSEC failed: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "Unicode: bücher.de.") {
+		t.Errorf("expected the U-label in the output, got:\n%s", buf.String())
+	}
 }
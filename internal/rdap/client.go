@@ -13,6 +13,8 @@ import (
 
 	rdaplib "github.com/registrobr/rdap"
 	"github.com/registrobr/rdap/protocol"
+
+	"watchr/internal/idn"
 )
 
 type Client struct {
@@ -67,29 +69,43 @@ func (c *Client) queryURL(ctx context.Context, url string) (*Response, error) {
 	return &rdapResp, nil
 }
 
+// QueryDomain looks up the registration of domain. Internationalized names
+// are queried by their A-label.
 func (c *Client) QueryDomain(ctx context.Context, domain string) (*Response, error) {
-	domain = strings.ToLower(strings.TrimSpace(domain))
+	name, err := idn.Parse(strings.ToLower(strings.TrimSpace(domain)))
+	if err != nil {
+		return nil, err
+	}
+	domain = name.ASCII
 
+	var resp *Response
 	if c.baseURL != "" {
 		// Properly escape domain to prevent URL manipulation
 		escapedDomain := url.PathEscape(domain)
 		queryURL := fmt.Sprintf("%s/domain/%s", strings.TrimSuffix(c.baseURL, "/"), escapedDomain)
-		return c.queryURL(ctx, queryURL)
+		resp, err = c.queryURL(ctx, queryURL)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		slog.Debug("querying domain", "domain", domain)
+		domainObj, _, err := c.rdapClient.Domain(domain, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp = convertDomainToResponse(domainObj)
 	}
 
-	slog.Debug("querying domain", "domain", domain)
-	domainObj, _, err := c.rdapClient.Domain(domain, nil, nil)
-	if err != nil {
-		return nil, err
-	}
+	resp.IDN = name
 
-	return convertDomainToResponse(domainObj), nil
+	return resp, nil
 }
 
 func convertDomainToResponse(d *protocol.Domain) *Response {
 	resp := &Response{
-		Handle:  d.Handle,
-		LDHName: d.LDHName,
+		Handle:      d.Handle,
+		LDHName:     d.LDHName,
+		UnicodeName: d.UnicodeName,
 	}
 
 	for _, status := range d.Status {
@@ -86,3 +86,33 @@ func TestClient_QueryDomain_NotFound(t *testing.T) {
 		t.Fatal("expected error for not found domain")
 	}
 }
+
+func TestClient_QueryDomain_IDN(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/domain/xn--bcher-kva.de" {
+			http.NotFound(w, r)
+			return
+		}
+		if _, err := w.Write([]byte(`{"handle":"BUCHER","ldhName":"xn--bcher-kva.de","unicodeName":"bücher.de"}`)); err != nil {
+			t.Errorf("failed to write response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		baseURL:    server.URL,
+	}
+
+	resp, err := client.QueryDomain(context.Background(), "Bücher.de")
+	if err != nil {
+		t.Fatalf("QueryDomain failed: %v", err)
+	}
+
+	if resp.IDN == nil || resp.IDN.ASCII != "xn--bcher-kva.de" || resp.IDN.Unicode != "bücher.de" {
+		t.Errorf("expected both forms of the name, got %+v", resp.IDN)
+	}
+	if resp.UnicodeName != "bücher.de" {
+		t.Errorf("expected the unicodeName from the server, got %q", resp.UnicodeName)
+	}
+}
@@ -1,10 +1,16 @@
 package rdap
 
-import "time"
+import (
+	"time"
+
+	"watchr/internal/idn"
+)
 
 type Response struct {
 	Handle      string       `json:"handle"`
 	LDHName     string       `json:"ldhName"`
+	UnicodeName string       `json:"unicodeName,omitempty"`
+	IDN         *idn.Name    `json:"idn,omitempty"`
 	Status      []string     `json:"status"`
 	Entities    []Entity     `json:"entities"`
 	Events      []Event      `json:"events"`
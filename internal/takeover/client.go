@@ -0,0 +1,171 @@
+package takeover
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	dnsinfo "watchr/internal/dns"
+	httpinfo "watchr/internal/http"
+	"watchr/internal/idn"
+)
+
+// maxBodySize is how much of a page is searched for fingerprints.
+const maxBodySize = 256 * 1024
+
+type Client struct {
+	timeout      time.Duration
+	dnsClient    *dnsinfo.Client
+	fingerprints []Fingerprint
+	// siteURL returns the URL fetched to look for a service fingerprint.
+	siteURL func(name string) string
+}
+
+// NewClient returns a client matching names against fingerprints, or against
+// the built-in fingerprint set when fingerprints is nil.
+func NewClient(timeout time.Duration, dnsClient *dnsinfo.Client, fingerprints []Fingerprint) *Client {
+	if fingerprints == nil {
+		fingerprints = DefaultFingerprints()
+	}
+
+	return &Client{
+		timeout:      timeout,
+		dnsClient:    dnsClient,
+		fingerprints: fingerprints,
+		siteURL: func(name string) string {
+			return "http://" + name + "/"
+		},
+	}
+}
+
+// Check resolves the CNAME chain of every name, using up to concurrency
+// workers, and flags the names that can be taken over: names whose CNAME
+// target does not exist, and names pointing at a known service that answers
+// with its "not found" page for them.
+func (c *Client) Check(ctx context.Context, names []string, concurrency int) (*Response, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no names to check")
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	slog.Debug("checking for subdomain takeovers", "names", len(names), "concurrency", concurrency)
+	start := time.Now()
+
+	response := &Response{
+		Checked: len(names),
+		Results: make([]Result, len(names)),
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i, name := range names {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, name string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			response.Results[i] = c.checkName(ctx, name)
+		}(i, name)
+	}
+	wg.Wait()
+
+	for _, result := range response.Results {
+		switch result.Status {
+		case StatusVulnerable:
+			response.Vulnerable++
+		case StatusDangling:
+			response.Dangling++
+		}
+	}
+	response.QueryTime = time.Since(start)
+
+	return response, nil
+}
+
+func (c *Client) checkName(ctx context.Context, name string) Result {
+	name = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+	result := Result{Name: name}
+
+	parsed, err := idn.Parse(name)
+	if err != nil {
+		result.Status = StatusError
+		result.Error = err.Error()
+		return result
+	}
+	name = parsed.ASCII
+	result.Name = name
+	result.IDN = parsed
+
+	resp, err := c.dnsClient.Query(ctx, name, "A")
+	if err != nil {
+		result.Status = StatusError
+		result.Error = err.Error()
+		return result
+	}
+	result.Rcode = resp.Rcode
+
+	chain := resp.Chain
+	if chain == nil {
+		result.Status = StatusOK
+		return result
+	}
+
+	for _, hop := range chain.Hops {
+		result.CNAMEs = append(result.CNAMEs, hop.Target)
+	}
+	result.Rcode = chain.Rcode
+
+	fp := matchService(c.fingerprints, result.CNAMEs)
+	if fp != nil {
+		result.Service = fp.Service
+	}
+
+	dangling := chain.Status == dnsinfo.ChainDangling
+	switch {
+	case dangling && fp != nil && fp.NXDomain:
+		result.Status = StatusVulnerable
+		result.Evidence = fmt.Sprintf("CNAME target %s does not exist (NXDOMAIN) and can be claimed on %s", chain.Target, fp.Service)
+	case dangling:
+		result.Status = StatusDangling
+		result.Evidence = chain.Error
+	case fp != nil && len(fp.Fingerprint) > 0:
+		c.checkFingerprint(ctx, &result, fp)
+	default:
+		result.Status = StatusOK
+	}
+
+	return result
+}
+
+// checkFingerprint fetches the site served for the name and looks for the
+// service's "not found" page. Each check uses its own HTTP client, as the
+// client keeps the redirect chain of the request in flight.
+func (c *Client) checkFingerprint(ctx context.Context, result *Result, fp *Fingerprint) {
+	httpClient := httpinfo.NewClient(c.timeout, true, false, httpinfo.WithBody(maxBodySize))
+
+	resp, err := httpClient.Fetch(ctx, c.siteURL(result.Name))
+	if err != nil {
+		result.Status = StatusError
+		result.Error = fmt.Sprintf("failed to fetch the site: %v", err)
+		return
+	}
+	result.HTTPStatus = resp.StatusCode
+
+	result.Status = StatusOK
+	if fp.Status != 0 && resp.StatusCode != fp.Status {
+		return
+	}
+
+	for _, fingerprint := range fp.Fingerprint {
+		if strings.Contains(resp.Body, fingerprint) {
+			result.Status = StatusVulnerable
+			result.Evidence = fmt.Sprintf("%s answered with its unclaimed page: %q", fp.Service, fingerprint)
+			return
+		}
+	}
+}
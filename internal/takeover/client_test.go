@@ -0,0 +1,105 @@
+package takeover
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestClient_Check(t *testing.T) {
+	client := newTestClient(t,
+		map[string]string{
+			"docs.example.com": "<h1>404</h1><p>There isn't a GitHub Pages site here.</p>",
+			"blog.example.com": "<h1>Welcome to our blog</h1>",
+		},
+		"docs.example.com. 300 IN CNAME example.github.io.",
+		"example.github.io. 300 IN A 185.199.108.153",
+		"blog.example.com. 300 IN CNAME example-blog.github.io.",
+		"example-blog.github.io. 300 IN A 185.199.108.153",
+		"app.example.com. 300 IN CNAME example-app.herokuapp.com.",
+		"old.example.com. 300 IN CNAME gone.example-cdn.net.",
+		"www.example.com. 300 IN A 192.0.2.1",
+	)
+
+	resp, err := client.Check(context.Background(), []string{
+		"docs.example.com",
+		"blog.example.com",
+		"app.example.com.",
+		"old.example.com",
+		"www.example.com",
+	}, 2)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	if resp.Checked != 5 || resp.Vulnerable != 2 || resp.Dangling != 1 {
+		t.Errorf("expected 5 checked, 2 vulnerable and 1 dangling, got %d, %d and %d", resp.Checked, resp.Vulnerable, resp.Dangling)
+	}
+
+	results := make(map[string]Result)
+	for _, result := range resp.Results {
+		results[result.Name] = result
+	}
+
+	docs := results["docs.example.com"]
+	if docs.Status != StatusVulnerable || docs.Service != "GitHub Pages" {
+		t.Errorf("expected docs to be a vulnerable GitHub Pages site, got %+v", docs)
+	}
+	if !strings.Contains(docs.Evidence, "There isn't a GitHub Pages site here.") {
+		t.Errorf("expected the fingerprint as evidence, got %q", docs.Evidence)
+	}
+
+	blog := results["blog.example.com"]
+	if blog.Status != StatusOK || blog.Service != "GitHub Pages" || blog.HTTPStatus != 200 {
+		t.Errorf("expected blog to be a claimed GitHub Pages site, got %+v", blog)
+	}
+
+	app := results["app.example.com"]
+	if app.Status != StatusVulnerable || app.Service != "Heroku" || app.Rcode != "NXDOMAIN" {
+		t.Errorf("expected app to be a vulnerable Heroku app, got %+v", app)
+	}
+	if len(app.CNAMEs) != 1 || app.CNAMEs[0] != "example-app.herokuapp.com." {
+		t.Errorf("unexpected CNAME chain: %v", app.CNAMEs)
+	}
+
+	old := results["old.example.com"]
+	if old.Status != StatusDangling || old.Service != "" {
+		t.Errorf("expected old to be a dangling CNAME, got %+v", old)
+	}
+
+	www := results["www.example.com"]
+	if www.Status != StatusOK || len(www.CNAMEs) != 0 {
+		t.Errorf("expected www to be ok, got %+v", www)
+	}
+}
+
+func TestClient_Check_IDN(t *testing.T) {
+	client := newTestClient(t, nil,
+		"xn--bcher-kva.example.com. 300 IN A 192.0.2.1",
+	)
+
+	resp, err := client.Check(context.Background(), []string{"bücher.example.com", "-bücher.example.com"}, 1)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	result := resp.Results[0]
+	if result.Name != "xn--bcher-kva.example.com" || result.Status != StatusOK {
+		t.Errorf("expected the A-label to be checked, got %+v", result)
+	}
+	if result.IDN == nil || result.IDN.Unicode != "bücher.example.com" {
+		t.Errorf("expected the U-label in the result, got %+v", result.IDN)
+	}
+
+	if resp.Results[1].Status != StatusError {
+		t.Errorf("expected an invalid IDN to be an error, got %+v", resp.Results[1])
+	}
+}
+
+func TestClient_Check_NoNames(t *testing.T) {
+	client := newTestClient(t, nil)
+
+	if _, err := client.Check(context.Background(), nil, 1); err == nil {
+		t.Error("expected an error without names")
+	}
+}
@@ -0,0 +1,90 @@
+package takeover
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+//go:embed fingerprints.json
+var defaultFingerprints []byte
+
+// Fingerprint describes how a third-party service answers for a custom domain
+// nobody has claimed.
+type Fingerprint struct {
+	Service string `json:"service"`
+	// CNAME lists substrings of the CNAME targets pointing at the service.
+	CNAME []string `json:"cname"`
+	// Fingerprint lists substrings of the service's "not found" page.
+	Fingerprint []string `json:"fingerprint,omitempty"`
+	// Status, when set, is the HTTP status code the "not found" page is
+	// served with.
+	Status int `json:"status,omitempty"`
+	// NXDomain is set when a CNAME target that does not exist can be
+	// registered by anyone.
+	NXDomain bool `json:"nxdomain,omitempty"`
+}
+
+// DefaultFingerprints returns the built-in fingerprint set.
+func DefaultFingerprints() []Fingerprint {
+	fingerprints, err := ParseFingerprints(defaultFingerprints)
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in fingerprints: %v", err))
+	}
+	return fingerprints
+}
+
+// LoadFingerprints reads a fingerprint set from a JSON file using the same
+// format as the built-in one.
+func LoadFingerprints(path string) ([]Fingerprint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprints, err := ParseFingerprints(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return fingerprints, nil
+}
+
+// ParseFingerprints parses a JSON array of fingerprints.
+func ParseFingerprints(data []byte) ([]Fingerprint, error) {
+	var fingerprints []Fingerprint
+	if err := json.Unmarshal(data, &fingerprints); err != nil {
+		return nil, fmt.Errorf("failed to parse fingerprints: %w", err)
+	}
+
+	for i, fp := range fingerprints {
+		if fp.Service == "" {
+			return nil, fmt.Errorf("fingerprint %d has no service name", i)
+		}
+		if len(fp.CNAME) == 0 {
+			return nil, fmt.Errorf("fingerprint %q has no CNAME patterns", fp.Service)
+		}
+		if len(fp.Fingerprint) == 0 && !fp.NXDomain {
+			return nil, fmt.Errorf("fingerprint %q needs response fingerprints or nxdomain", fp.Service)
+		}
+	}
+
+	return fingerprints, nil
+}
+
+// matchService returns the fingerprint of the service one of the CNAME
+// targets points at.
+func matchService(fingerprints []Fingerprint, targets []string) *Fingerprint {
+	for _, target := range targets {
+		target = strings.ToLower(strings.TrimSuffix(target, "."))
+		for i, fp := range fingerprints {
+			for _, pattern := range fp.CNAME {
+				if strings.Contains(target, strings.ToLower(pattern)) {
+					return &fingerprints[i]
+				}
+			}
+		}
+	}
+	return nil
+}
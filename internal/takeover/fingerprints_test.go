@@ -0,0 +1,81 @@
+package takeover
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultFingerprints(t *testing.T) {
+	fingerprints := DefaultFingerprints()
+
+	services := make(map[string]bool)
+	for _, fp := range fingerprints {
+		services[fp.Service] = true
+	}
+
+	for _, service := range []string{"AWS S3", "GitHub Pages", "Heroku"} {
+		if !services[service] {
+			t.Errorf("expected a fingerprint for %s", service)
+		}
+	}
+}
+
+func TestLoadFingerprints(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fingerprints.json")
+	data := `[{"service": "Example Hosting", "cname": ["hosting.example"], "fingerprint": ["No site configured"], "status": 404}]`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("failed to write fingerprints: %v", err)
+	}
+
+	fingerprints, err := LoadFingerprints(path)
+	if err != nil {
+		t.Fatalf("LoadFingerprints failed: %v", err)
+	}
+
+	if len(fingerprints) != 1 || fingerprints[0].Service != "Example Hosting" || fingerprints[0].Status != 404 {
+		t.Errorf("unexpected fingerprints: %+v", fingerprints)
+	}
+
+	if _, err := LoadFingerprints(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestParseFingerprints_Invalid(t *testing.T) {
+	for _, data := range []string{
+		`{"service": "not an array"}`,
+		`[{"cname": ["example.net"], "nxdomain": true}]`,
+		`[{"service": "No patterns", "nxdomain": true}]`,
+		`[{"service": "No evidence", "cname": ["example.net"]}]`,
+	} {
+		if _, err := ParseFingerprints([]byte(data)); err == nil {
+			t.Errorf("expected an error for %s", data)
+		}
+	}
+}
+
+func TestMatchService(t *testing.T) {
+	fingerprints := DefaultFingerprints()
+
+	tests := []struct {
+		targets []string
+		service string
+	}{
+		{[]string{"assets.example.com.s3.amazonaws.com."}, "AWS S3"},
+		{[]string{"cdn.example.net.", "example.GitHub.io."}, "GitHub Pages"},
+		{[]string{"example.herokudns.com."}, "Heroku"},
+		{[]string{"www.example.org."}, ""},
+	}
+
+	for _, tt := range tests {
+		fp := matchService(fingerprints, tt.targets)
+		service := ""
+		if fp != nil {
+			service = fp.Service
+		}
+		if service != tt.service {
+			t.Errorf("matchService(%v) = %q, want %q", tt.targets, service, tt.service)
+		}
+	}
+}
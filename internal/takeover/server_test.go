@@ -0,0 +1,83 @@
+package takeover
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mdns "github.com/miekg/dns"
+
+	dnsinfo "watchr/internal/dns"
+)
+
+// newTestClient runs a local DNS server answering from the given records and
+// a web server serving pages[name] for every name, and returns a client using
+// them. Like an authoritative server, the DNS server answers CNAMEs without
+// following them and returns NXDOMAIN for names it has no records for.
+func newTestClient(t *testing.T, pages map[string]string, records ...string) *Client {
+	t.Helper()
+
+	rrs := make([]mdns.RR, 0, len(records))
+	for _, record := range records {
+		rr, err := mdns.NewRR(record)
+		if err != nil {
+			t.Fatalf("failed to parse %q: %v", record, err)
+		}
+		rrs = append(rrs, rr)
+	}
+
+	handler := func(w mdns.ResponseWriter, r *mdns.Msg) {
+		m := new(mdns.Msg)
+		m.SetReply(r)
+
+		q := r.Question[0]
+		exists := false
+		for _, rr := range rrs {
+			header := rr.Header()
+			if mdns.CanonicalName(header.Name) != mdns.CanonicalName(q.Name) {
+				continue
+			}
+			exists = true
+			if header.Rrtype == q.Qtype || header.Rrtype == mdns.TypeCNAME {
+				m.Answer = append(m.Answer, rr)
+			}
+		}
+		if !exists {
+			m.Rcode = mdns.RcodeNameError
+		}
+
+		_ = w.WriteMsg(m)
+	}
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen on UDP: %v", err)
+	}
+
+	server := &mdns.Server{PacketConn: pc, Handler: mdns.HandlerFunc(handler)}
+	go func() {
+		_ = server.ActivateAndServe()
+	}()
+	t.Cleanup(func() {
+		_ = server.Shutdown()
+	})
+
+	web := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.Query().Get("host")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(page))
+	}))
+	t.Cleanup(web.Close)
+
+	client := NewClient(5*time.Second, dnsinfo.NewClient(5*time.Second, pc.LocalAddr().String()), nil)
+	client.siteURL = func(name string) string {
+		return web.URL + "/?host=" + name
+	}
+
+	return client
+}
@@ -0,0 +1,40 @@
+package takeover
+
+import (
+	"time"
+
+	"watchr/internal/idn"
+)
+
+// Check statuses.
+const (
+	// StatusVulnerable marks a name pointing at an unclaimed resource of a
+	// known service.
+	StatusVulnerable = "vulnerable"
+	// StatusDangling marks a name whose CNAME target does not exist, on a
+	// service that is not in the fingerprint set.
+	StatusDangling = "dangling"
+	StatusOK       = "ok"
+	StatusError    = "error"
+)
+
+type Response struct {
+	QueryTime  time.Duration `json:"queryTime"`
+	Checked    int           `json:"checked"`
+	Vulnerable int           `json:"vulnerable"`
+	Dangling   int           `json:"dangling"`
+	Results    []Result      `json:"results"`
+}
+
+type Result struct {
+	Name   string    `json:"name"`
+	IDN    *idn.Name `json:"idn,omitempty"`
+	Status string    `json:"status"`
+	// CNAMEs lists the targets of the name's CNAME chain, in order.
+	CNAMEs     []string `json:"cnames,omitempty"`
+	Rcode      string   `json:"rcode,omitempty"`
+	Service    string   `json:"service,omitempty"`
+	HTTPStatus int      `json:"httpStatus,omitempty"`
+	Evidence   string   `json:"evidence,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
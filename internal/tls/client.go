@@ -12,20 +12,50 @@ import (
 	"log/slog"
 	"math/big"
 	"net"
+	"net/textproto"
+	"strings"
 	"time"
+
+	"watchr/internal/idn"
 )
 
 type Client struct {
-	timeout time.Duration
+	timeout  time.Duration
+	starttls string
+}
+
+type Option func(*Client)
+
+// WithSTARTTLS makes the client upgrade a plaintext connection with the given
+// protocol's STARTTLS command before the handshake. Only "smtp" is supported.
+func WithSTARTTLS(protocol string) Option {
+	return func(c *Client) {
+		c.starttls = strings.ToLower(protocol)
+	}
 }
 
-func NewClient(timeout time.Duration) *Client {
-	return &Client{
+func NewClient(timeout time.Duration, opts ...Option) *Client {
+	c := &Client{
 		timeout: timeout,
 	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
 }
 
+// Fetch connects to host and returns the certificate chain it serves.
+// Internationalized host names are connected to, and sent in the SNI, by
+// their A-label.
 func (c *Client) Fetch(ctx context.Context, host, port string) (*Response, error) {
+	name, err := idn.Parse(host)
+	if err != nil {
+		return nil, err
+	}
+	host = name.ASCII
+
 	address := net.JoinHostPort(host, port)
 
 	slog.Debug("connecting to TLS server", "address", address)
@@ -42,6 +72,12 @@ func (c *Client) Fetch(ctx context.Context, host, port string) (*Response, error
 		_ = conn.Close()
 	}()
 
+	if c.starttls != "" {
+		if err := c.startTLS(ctx, conn, host); err != nil {
+			return nil, err
+		}
+	}
+
 	tlsConfig := &tls.Config{
 		ServerName:         host,
 		InsecureSkipVerify: true,
@@ -60,8 +96,9 @@ func (c *Client) Fetch(ctx context.Context, host, port string) (*Response, error
 
 	response := &Response{
 		Host:         host,
+		IDN:          name,
 		Port:         port,
-		TLSVersion:   tlsVersionString(state.Version),
+		TLSVersion:   VersionString(state.Version),
 		CipherSuite:  tls.CipherSuiteName(state.CipherSuite),
 		Certificates: make([]Certificate, 0),
 	}
@@ -83,6 +120,49 @@ func (c *Client) Fetch(ctx context.Context, host, port string) (*Response, error
 	return response, nil
 }
 
+// startTLS asks the server to upgrade the connection to TLS.
+func (c *Client) startTLS(ctx context.Context, conn net.Conn, host string) error {
+	if c.starttls != "smtp" {
+		return fmt.Errorf("unsupported STARTTLS protocol %q", c.starttls)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	} else if c.timeout > 0 {
+		_ = conn.SetDeadline(time.Now().Add(c.timeout))
+	}
+	defer func() {
+		_ = conn.SetDeadline(time.Time{})
+	}()
+
+	slog.Debug("sending SMTP STARTTLS", "host", host)
+
+	text := textproto.NewConn(conn)
+	if _, _, err := text.ReadResponse(220); err != nil {
+		return fmt.Errorf("SMTP greeting: %w", err)
+	}
+
+	if err := text.PrintfLine("EHLO watchr.invalid"); err != nil {
+		return err
+	}
+	_, message, err := text.ReadResponse(250)
+	if err != nil {
+		return fmt.Errorf("SMTP EHLO: %w", err)
+	}
+	if !strings.Contains(strings.ToUpper(message), "STARTTLS") {
+		return fmt.Errorf("SMTP server does not offer STARTTLS")
+	}
+
+	if err := text.PrintfLine("STARTTLS"); err != nil {
+		return err
+	}
+	if _, _, err := text.ReadResponse(220); err != nil {
+		return fmt.Errorf("SMTP STARTTLS: %w", err)
+	}
+
+	return nil
+}
+
 func (c *Client) parseCertificate(cert *x509.Certificate) Certificate {
 	parsed := Certificate{
 		Subject:            parseSubject(cert.Subject),
@@ -94,6 +174,7 @@ func (c *Client) parseCertificate(cert *x509.Certificate) Certificate {
 		PublicKeyAlgorithm: cert.PublicKeyAlgorithm.String(),
 		DNSNames:           cert.DNSNames,
 		IsCA:               cert.IsCA,
+		Raw:                cert.Raw,
 	}
 
 	// Extract public key size for different key types
@@ -143,7 +224,8 @@ func serialNumberToString(sn *big.Int) string {
 	return fmt.Sprintf("%X", sn)
 }
 
-func tlsVersionString(version uint16) string {
+// VersionString returns the name of a TLS protocol version.
+func VersionString(version uint16) string {
 	switch version {
 	case tls.VersionTLS10:
 		return "TLS 1.0"
@@ -2,10 +2,15 @@ package tls
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"math/big"
 	"net"
+	"net/textproto"
 	"strings"
 	"testing"
 	"time"
@@ -186,3 +191,105 @@ func TestDialContext(t *testing.T) {
 		t.Error("expected peer certificates")
 	}
 }
+
+// startSMTPServer runs a local SMTP server offering STARTTLS with a
+// self-signed certificate for mx.example.com and returns its port.
+func startSMTPServer(t *testing.T, offerSTARTTLS bool) string {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "mx.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(90 * 24 * time.Hour),
+		DNSNames:     []string{"mx.example.com"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	config := &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = l.Close()
+	})
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			text := textproto.NewConn(conn)
+			_ = text.PrintfLine("220 mx.example.com ESMTP")
+			_, _ = text.ReadLine()
+			if offerSTARTTLS {
+				_ = text.PrintfLine("250-mx.example.com\r\n250-PIPELINING\r\n250 STARTTLS")
+				_, _ = text.ReadLine()
+				_ = text.PrintfLine("220 2.0.0 Ready to start TLS")
+				_ = tls.Server(conn, config).Handshake()
+			} else {
+				_ = text.PrintfLine("250 mx.example.com")
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	return port
+}
+
+func TestClient_Fetch_STARTTLS(t *testing.T) {
+	port := startSMTPServer(t, true)
+	client := NewClient(5*time.Second, WithSTARTTLS("smtp"))
+
+	resp, err := client.Fetch(context.Background(), "127.0.0.1", port)
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	if len(resp.Certificates) != 1 || resp.Certificates[0].Subject.CommonName != "mx.example.com" {
+		t.Fatalf("expected the certificate of mx.example.com, got %+v", resp.Certificates)
+	}
+	if len(resp.Certificates[0].Raw) == 0 {
+		t.Error("expected the DER encoding of the certificate")
+	}
+}
+
+func TestClient_Fetch_STARTTLSNotOffered(t *testing.T) {
+	port := startSMTPServer(t, false)
+	client := NewClient(5*time.Second, WithSTARTTLS("smtp"))
+
+	_, err := client.Fetch(context.Background(), "127.0.0.1", port)
+	if err == nil || !strings.Contains(err.Error(), "does not offer STARTTLS") {
+		t.Errorf("expected a missing STARTTLS error, got %v", err)
+	}
+}
+
+func TestClient_Fetch_STARTTLSUnsupported(t *testing.T) {
+	port := startSMTPServer(t, true)
+	client := NewClient(5*time.Second, WithSTARTTLS("ldap"))
+
+	_, err := client.Fetch(context.Background(), "127.0.0.1", port)
+	if err == nil || !strings.Contains(err.Error(), "unsupported STARTTLS protocol") {
+		t.Errorf("expected an unsupported protocol error, got %v", err)
+	}
+}
+
+func TestClient_Fetch_InvalidIDN(t *testing.T) {
+	client := NewClient(5 * time.Second)
+
+	_, err := client.Fetch(context.Background(), "bü\u200dcher.de", "443")
+	if err == nil || !strings.Contains(err.Error(), "invalid internationalized domain name") {
+		t.Errorf("expected the host to be rejected, got %v", err)
+	}
+}
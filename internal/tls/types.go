@@ -2,10 +2,13 @@ package tls
 
 import (
 	"time"
+
+	"watchr/internal/idn"
 )
 
 type Response struct {
 	Host           string        `json:"host"`
+	IDN            *idn.Name     `json:"idn,omitempty"`
 	Port           string        `json:"port"`
 	TLSVersion     string        `json:"tlsVersion"`
 	CipherSuite    string        `json:"cipherSuite"`
@@ -24,6 +27,8 @@ type Certificate struct {
 	PublicKeySize      int       `json:"publicKeySize"`
 	DNSNames           []string  `json:"dnsNames,omitempty"`
 	IsCA               bool      `json:"isCA"`
+	// Raw is the DER encoding of the certificate.
+	Raw []byte `json:"-"`
 }
 
 type Subject struct {
@@ -7,8 +7,16 @@ import (
 	"time"
 
 	whoislib "github.com/likexian/whois"
+
+	"watchr/internal/idn"
 )
 
+// Response is the raw WHOIS answer for a domain.
+type Response struct {
+	IDN *idn.Name `json:"idn,omitempty"`
+	Raw string    `json:"raw"`
+}
+
 type Client struct {
 	timeout time.Duration
 	client  *whoislib.Client
@@ -24,8 +32,14 @@ func NewClient(timeout time.Duration) *Client {
 	}
 }
 
-func (c *Client) Query(ctx context.Context, domain string) (string, error) {
-	domain = strings.ToLower(strings.TrimSpace(domain))
+// Query looks up domain in WHOIS. Internationalized names are queried by their
+// A-label.
+func (c *Client) Query(ctx context.Context, domain string) (*Response, error) {
+	name, err := idn.Parse(strings.ToLower(strings.TrimSpace(domain)))
+	if err != nil {
+		return nil, err
+	}
+	domain = name.ASCII
 
 	slog.Debug("querying WHOIS", "domain", domain)
 
@@ -43,8 +57,11 @@ func (c *Client) Query(ctx context.Context, domain string) (string, error) {
 
 	select {
 	case <-ctx.Done():
-		return "", ctx.Err()
+		return nil, ctx.Err()
 	case res := <-resultCh:
-		return res.data, res.err
+		if res.err != nil {
+			return nil, res.err
+		}
+		return &Response{IDN: name, Raw: res.data}, nil
 	}
 }
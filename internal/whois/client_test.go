@@ -29,11 +29,11 @@ func TestClient_Query(t *testing.T) {
 		t.Fatalf("Query failed: %v", err)
 	}
 
-	if result == "" {
+	if result.Raw == "" {
 		t.Error("expected non-empty result")
 	}
 
-	if !strings.Contains(strings.ToLower(result), "domain") {
+	if !strings.Contains(strings.ToLower(result.Raw), "domain") {
 		t.Error("expected result to contain 'domain'")
 	}
 }
@@ -43,7 +43,16 @@ func TestClient_Query_InvalidDomain(t *testing.T) {
 	ctx := context.Background()
 
 	result, err := client.Query(ctx, "invalid..domain")
-	if err == nil && result == "" {
+	if err == nil && result.Raw == "" {
 		t.Error("expected error or empty result for invalid domain")
 	}
 }
+
+func TestClient_Query_InvalidIDN(t *testing.T) {
+	client := NewClient(5 * time.Second)
+
+	_, err := client.Query(context.Background(), "-bücher.de")
+	if err == nil || !strings.Contains(err.Error(), "invalid internationalized domain name") {
+		t.Errorf("expected the name to be rejected before querying, got %v", err)
+	}
+}